@@ -39,6 +39,13 @@ const (
 	MimetypeDataWithValidator = "data/validator"
 	MimetypeTypedData         = "data/typed"
 	MimetypeTextPlain         = "text/plain"
+
+	// content types of the data signed by the consensus signers of tendermint
+	MimetypeTendermintMessage        = "application/x-tendermint-message"
+	MimetypeTendermintSeal           = "application/x-tendermint-seal"
+	MimetypeTendermintAggregatedSeal = "application/x-tendermint-aggregated-seal"
+	MimetypeTendermintBLSBeacon      = "application/x-tendermint-bls-beacon"
+	MimetypeTendermintHeader         = "application/x-tendermint-header"
 )
 
 // Wallet represents a software or hardware wallet that might contain one or more
@@ -15,10 +15,13 @@ import (
 	"github.com/clearmatics/autonity/core/vm"
 	"github.com/clearmatics/autonity/crypto"
 	"github.com/clearmatics/autonity/log"
+	lru "github.com/hashicorp/golang-lru"
 )
 
 var ErrAutonityContract = errors.New("could not call Autonity contract")
 var ErrWrongParameter = errors.New("wrong parameter")
+var ErrSlashUnsupported = errors.New("autonity contract doesn't support slashing")
+var ErrUnsupportedSignature = errors.New("unsupported autonity contract signature")
 var Deployer = common.Address{}
 var ContractAddress = crypto.CreateAddress(Deployer, 0)
 
@@ -33,7 +36,38 @@ type Blockchainer interface {
 	UpdateEnodeWhitelist(newWhitelist *types.Nodes)
 	ReadEnodeWhitelist() *types.Nodes
 
-	PutKeyValue(key []byte, value []byte) error
+	// WriteContractABI persists the ABI which took effect at the given height,
+	// dropping the ones stored for the same or later heights.
+	WriteContractABI(height uint64, abi string)
+	// ReadContractABIs returns the persisted ABIs sorted by height.
+	ReadContractABIs() ([]uint64, []string)
+}
+
+// Misbehaviour is a proven fault of a committee member to be punished by the
+// contract. The contract punishes a fault once per height, round and step,
+// whichever evidence proves it.
+type Misbehaviour struct {
+	Offender common.Address
+	Height   *big.Int
+	Round    *big.Int
+	Step     uint8
+	Evidence common.Hash
+}
+
+// Participation lists the members of the committee which decided on the
+// previous block who have, or haven't, signed it. Rewards are only given to
+// signers while absentees get penalised once they have been offline for too long.
+type Participation struct {
+	Signers   []common.Address
+	Absentees []common.Address
+}
+
+// abiVersion is an autonity contract ABI and the height of the block at which
+// it took effect.
+type abiVersion struct {
+	height uint64
+	abi    *abi.ABI
+	json   string
 }
 
 type Contract struct {
@@ -42,8 +76,16 @@ type Contract struct {
 	initialMinGasPrice uint64
 	contractABI        *abi.ABI
 	stringABI          string
-	bc                 Blockchainer
-	metrics            EconomicMetrics
+	// ABIs of the canonical chain sorted by height. The first one applies to
+	// every block older than the second one.
+	abiVersions []abiVersion
+	bc          Blockchainer
+	metrics     EconomicMetrics
+	// the packed results of the view functions, see viewCall.
+	views *lru.Cache
+	// the reward distributions of the finalized blocks by hash, they are
+	// only submitted to the metrics once the block is canonical.
+	rewards *lru.Cache
 
 	sync.RWMutex
 }
@@ -61,9 +103,23 @@ func NewAutonityContract(
 		initialMinGasPrice: minGasPrice,
 		bc:                 bc,
 		evmProvider:        evmProvider,
+		views:              newViewCache(),
+		rewards:            newRewardCache(),
 	}
-	err := contract.upgradeAbiCache(ABI)
-	return &contract, err
+
+	heights, abis := bc.ReadContractABIs()
+	if len(heights) == 0 {
+		// the history starts with the ABI of the genesis contract, or the
+		// latest one for databases written before it was kept.
+		heights, abis = []uint64{0}, []string{ABI}
+		bc.WriteContractABI(0, ABI)
+	}
+	for i := range heights {
+		if err := contract.upgradeAbiCache(abis[i], heights[i]); err != nil {
+			return nil, err
+		}
+	}
+	return &contract, nil
 }
 
 // measure metrics of user's meta data by regarding of network economic.
@@ -71,7 +127,7 @@ func (ac *Contract) MeasureMetricsOfNetworkEconomic(header *types.Header, stateD
 	// prepare abi and evm context
 	gas := uint64(0xFFFFFFFF)
 	evm := ac.evmProvider.EVM(header, Deployer, stateDB)
-	ABI := ac.contractABI
+	ABI := ac.ABIAt(header.Number.Uint64())
 
 	// pack the function which dump the data from contract.
 	input, err := ABI.Pack("dumpEconomicMetrics")
@@ -112,6 +168,59 @@ func (ac *Contract) MeasureMetricsOfNetworkEconomic(header *types.Header, stateD
 	return nil
 }
 
+// maxPendingRewards bounds the number of reward distributions kept until their
+// block is written, every proposal verified is finalized but few are committed.
+const maxPendingRewards = 128
+
+func newRewardCache() *lru.Cache {
+	rewards, _ := lru.New(maxPendingRewards)
+	return rewards
+}
+
+// MeasureRewardDistribution submits the reward distribution metrics of a block
+// written to the canonical chain, it must have been finalized beforehand.
+func (ac *Contract) MeasureRewardDistribution(block *types.Block) {
+	distribution, ok := ac.rewards.Get(block.Hash())
+	if !ok {
+		return
+	}
+	ac.rewards.Remove(block.Hash())
+	ac.metrics.SubmitRewardDistributionMetrics(distribution.(*RewardDistributionMetaData), block.NumberU64())
+}
+
+// rewardDistribution returns the rewards distributed by the contract when it
+// finalized the block, as reported by its Rewarded events.
+func (ac *Contract) rewardDistribution(header *types.Header, logs []*types.Log, amount *big.Int, participation Participation) *RewardDistributionMetaData {
+	distribution := &RewardDistributionMetaData{
+		Amount:       amount,
+		Participants: participation.Signers,
+		Absentees:    participation.Absentees,
+	}
+	contractABI := ac.parentABI(header)
+	event, ok := contractABI.Events["Rewarded"]
+	if !ok {
+		return distribution
+	}
+	for _, l := range logs {
+		if l.Address != ContractAddress || len(l.Topics) == 0 || l.Topics[0] != event.ID {
+			continue
+		}
+		var rewarded struct {
+			Address common.Address
+			Amount  *big.Int
+		}
+		if err := contractABI.UnpackIntoInterface(&rewarded, "Rewarded", l.Data); err != nil {
+			log.Warn("Cannot unpack the Rewarded event", "err", err)
+			continue
+		}
+		distribution.Holders = append(distribution.Holders, rewarded.Address)
+		distribution.Rewardfractions = append(distribution.Rewardfractions, rewarded.Amount)
+	}
+	// the contract skips the redistribution when none of the stake holders is active.
+	distribution.Result = len(distribution.Holders) > 0
+	return distribution
+}
+
 func (ac *Contract) GetCommittee(header *types.Header, statedb *state.StateDB) (types.Committee, error) {
 	// The Autonity Contract is not deployed yet at block #1, we return an error if this
 	// function is called at this height. In a past version we were returning the genesis committee field
@@ -177,7 +286,7 @@ func (ac *Contract) SetMinimumGasPrice(block *types.Block, db *state.StateDB, pr
 	return ac.callSetMinimumGasPrice(db, block.Header(), price)
 }
 
-func (ac *Contract) FinalizeAndGetCommittee(transactions types.Transactions, receipts types.Receipts, header *types.Header, statedb *state.StateDB) (types.Committee, *types.Receipt, error) {
+func (ac *Contract) FinalizeAndGetCommittee(transactions types.Transactions, receipts types.Receipts, header *types.Header, statedb *state.StateDB, misbehaviours []Misbehaviour, participation Participation) (types.Committee, *types.Receipt, error) {
 	if header.Number.Uint64() == 0 {
 		return nil, nil, nil
 	}
@@ -189,12 +298,16 @@ func (ac *Contract) FinalizeAndGetCommittee(transactions types.Transactions, rec
 	log.Debug("ApplyFinalize",
 		"balance", statedb.GetBalance(ContractAddress),
 		"block", header.Number.Uint64(),
-		"gas", blockGas.Uint64())
+		"gas", blockGas.Uint64(),
+		"misbehaviours", len(misbehaviours),
+		"signers", len(participation.Signers),
+		"absentees", len(participation.Absentees))
 
-	upgradeContract, committee, err := ac.callFinalize(statedb, header, blockGas)
+	upgradeContract, committee, err := ac.callFinalize(statedb, header, blockGas, misbehaviours, participation)
 	if err != nil {
 		return nil, nil, err
 	}
+	ac.purgeViews()
 
 	// Create a new receipt for the finalize call
 	receipt := types.NewReceipt(nil, false, 0)
@@ -206,6 +319,10 @@ func (ac *Contract) FinalizeAndGetCommittee(transactions types.Transactions, rec
 	receipt.BlockNumber = header.Number
 	receipt.TransactionIndex = uint(statedb.TxIndex())
 
+	// the same block is finalized when verified and when imported, the metrics
+	// are recorded once it is written to the canonical chain.
+	ac.rewards.Add(header.Hash(), ac.rewardDistribution(header, receipt.Logs, blockGas, participation))
+
 	log.Debug("ApplyFinalize", "upgradeContract", upgradeContract)
 
 	if upgradeContract {
@@ -240,27 +357,24 @@ func (ac *Contract) performContractUpgrade(statedb *state.StateDB, header *types
 	// Create account will delete previous the AC stateobject and carry over the balance
 	statedb.CreateAccount(ContractAddress)
 
-	if err := ac.updateAutonityContract(header, statedb, bytecode, stateBefore); err != nil {
-		statedb.RevertToSnapshot(snapshot)
-		return err
-	}
-
-	// save new abi in persistent, once node reset, it load from persistent level db.
-	if err := ac.bc.PutKeyValue([]byte(ABISPEC), []byte(newAbi)); err != nil {
+	if _, err := ac.updateAutonityContract(header, statedb, bytecode, stateBefore); err != nil {
 		statedb.RevertToSnapshot(snapshot)
 		return err
 	}
 
 	// upgrade ac.ContractStateStore too right after the contract upgrade successfully.
-	if err := ac.upgradeAbiCache(newAbi); err != nil {
+	if err := ac.upgradeAbiCache(newAbi, header.Number.Uint64()); err != nil {
 		statedb.RevertToSnapshot(snapshot)
 		return err
 	}
+
+	// save new abi in persistent, once node reset, it load from persistent level db.
+	ac.bc.WriteContractABI(header.Number.Uint64(), newAbi)
 	log.Info("Autonity Contract upgrade success")
 	return nil
 }
 
-func (ac *Contract) upgradeAbiCache(newAbi string) error {
+func (ac *Contract) upgradeAbiCache(newAbi string, height uint64) error {
 	ac.Lock()
 	defer ac.Unlock()
 	newABI, err := abi.JSON(strings.NewReader(newAbi))
@@ -270,9 +384,47 @@ func (ac *Contract) upgradeAbiCache(newAbi string) error {
 
 	ac.contractABI = &newABI
 	ac.stringABI = newAbi
+	ac.purgeViews()
+
+	// the same block can be finalized more than once, e.g. when verifying a
+	// proposal and then when importing it, versions from later blocks are
+	// dropped as they are not part of the chain being processed anymore.
+	i := len(ac.abiVersions)
+	for i > 0 && ac.abiVersions[i-1].height >= height {
+		i--
+	}
+	ac.abiVersions = append(ac.abiVersions[:i], abiVersion{height: height, abi: &newABI, json: newAbi})
 	return nil
 }
 
+// Rewind drops the ABIs which took effect after the given height, it is called
+// when the chain head is set back.
+func (ac *Contract) Rewind(height uint64) {
+	ac.Lock()
+	defer ac.Unlock()
+	i := len(ac.abiVersions)
+	for i > 1 && ac.abiVersions[i-1].height > height {
+		i--
+	}
+	ac.abiVersions = ac.abiVersions[:i]
+	ac.contractABI = ac.abiVersions[i-1].abi
+	ac.stringABI = ac.abiVersions[i-1].json
+	ac.purgeViews()
+}
+
+// ABIAt returns the autonity contract ABI which was in effect after the block
+// of the given height had been finalized.
+func (ac *Contract) ABIAt(height uint64) *abi.ABI {
+	ac.RLock()
+	defer ac.RUnlock()
+	for i := len(ac.abiVersions) - 1; i > 0; i-- {
+		if ac.abiVersions[i].height <= height {
+			return ac.abiVersions[i].abi
+		}
+	}
+	return ac.abiVersions[0].abi
+}
+
 // StringABI returns the current autonity contract ABI in string format
 func (ac *Contract) StringABI() string {
 	return ac.stringABI
@@ -282,3 +434,15 @@ func (ac *Contract) StringABI() string {
 func (ac *Contract) ABI() *abi.ABI {
 	return ac.contractABI
 }
+
+// ABIs returns the autonity contract ABIs of the canonical chain, the oldest
+// first.
+func (ac *Contract) ABIs() []*abi.ABI {
+	ac.RLock()
+	defer ac.RUnlock()
+	abis := make([]*abi.ABI, len(ac.abiVersions))
+	for i, v := range ac.abiVersions {
+		abis[i] = v.abi
+	}
+	return abis
+}
@@ -0,0 +1,230 @@
+package autonity
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/metrics"
+)
+
+const (
+	testABIV1 = `[{"inputs":[],"name":"getVersion","outputs":[{"internalType":"string","name":"","type":"string"}],"stateMutability":"view","type":"function"}]`
+	testABIV2 = `[{"inputs":[],"name":"getVersion","outputs":[{"internalType":"string","name":"","type":"string"}],"stateMutability":"view","type":"function"},{"inputs":[],"name":"getCommittee","outputs":[],"stateMutability":"view","type":"function"}]`
+)
+
+func TestContract_ABIAt(t *testing.T) {
+	ac := &Contract{}
+	if err := ac.upgradeAbiCache(testABIV1, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.upgradeAbiCache(testABIV2, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("ABI before the upgrade", func(t *testing.T) {
+		for _, height := range []uint64{0, 9} {
+			if _, ok := ac.ABIAt(height).Methods["getCommittee"]; ok {
+				t.Fatalf("unexpected ABI at height %d", height)
+			}
+		}
+	})
+
+	t.Run("ABI after the upgrade", func(t *testing.T) {
+		for _, height := range []uint64{10, 100} {
+			if _, ok := ac.ABIAt(height).Methods["getCommittee"]; !ok {
+				t.Fatalf("unexpected ABI at height %d", height)
+			}
+		}
+	})
+
+	t.Run("upgrade block finalized again", func(t *testing.T) {
+		if err := ac.upgradeAbiCache(testABIV1, 5); err != nil {
+			t.Fatal(err)
+		}
+		if len(ac.abiVersions) != 2 {
+			t.Fatalf("expected 2 versions, got %d", len(ac.abiVersions))
+		}
+		if _, ok := ac.ABIAt(10).Methods["getCommittee"]; ok {
+			t.Fatal("version of a dropped block should have been removed")
+		}
+	})
+}
+
+// testBlockchainer keeps the contract ABIs in memory.
+type testBlockchainer struct {
+	heights []uint64
+	abis    []string
+}
+
+func (bc *testBlockchainer) UpdateEnodeWhitelist(*types.Nodes) {}
+func (bc *testBlockchainer) ReadEnodeWhitelist() *types.Nodes  { return nil }
+
+func (bc *testBlockchainer) WriteContractABI(height uint64, abi string) {
+	i := len(bc.heights)
+	for i > 0 && bc.heights[i-1] >= height {
+		i--
+	}
+	bc.heights = append(bc.heights[:i], height)
+	bc.abis = append(bc.abis[:i], abi)
+}
+
+func (bc *testBlockchainer) ReadContractABIs() ([]uint64, []string) {
+	return bc.heights, bc.abis
+}
+
+func TestNewAutonityContract_ABIHistory(t *testing.T) {
+	t.Run("new chain", func(t *testing.T) {
+		bc := &testBlockchainer{}
+		ac, err := NewAutonityContract(bc, common.Address{}, 0, testABIV1, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(bc.heights) != 1 || bc.heights[0] != 0 || bc.abis[0] != testABIV1 {
+			t.Fatalf("expected the genesis ABI to be persisted, got %v", bc.heights)
+		}
+		if ac.StringABI() != testABIV1 {
+			t.Fatalf("Expected %v, got %v", testABIV1, ac.StringABI())
+		}
+	})
+
+	t.Run("persisted history", func(t *testing.T) {
+		bc := &testBlockchainer{heights: []uint64{0, 10}, abis: []string{testABIV1, testABIV2}}
+		ac, err := NewAutonityContract(bc, common.Address{}, 0, testABIV1, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ac.StringABI() != testABIV2 {
+			t.Fatalf("Expected %v, got %v", testABIV2, ac.StringABI())
+		}
+		if _, ok := ac.ABIAt(9).Methods["getCommittee"]; ok {
+			t.Fatal("unexpected ABI at height 9")
+		}
+		if _, ok := ac.ABIAt(10).Methods["getCommittee"]; !ok {
+			t.Fatal("unexpected ABI at height 10")
+		}
+	})
+}
+
+func TestContract_Rewind(t *testing.T) {
+	ac := &Contract{}
+	if err := ac.upgradeAbiCache(testABIV1, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.upgradeAbiCache(testABIV2, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	ac.Rewind(10)
+	if ac.StringABI() != testABIV2 {
+		t.Fatalf("Expected %v, got %v", testABIV2, ac.StringABI())
+	}
+
+	ac.Rewind(9)
+	if ac.StringABI() != testABIV1 {
+		t.Fatalf("Expected %v, got %v", testABIV1, ac.StringABI())
+	}
+	if _, ok := ac.ABIAt(10).Methods["getCommittee"]; ok {
+		t.Fatal("rewound version should have been removed")
+	}
+
+	// the genesis ABI is always kept.
+	ac.Rewind(0)
+	if len(ac.abiVersions) != 1 {
+		t.Fatalf("expected 1 version, got %d", len(ac.abiVersions))
+	}
+}
+
+func TestContract_SlashUnsupported(t *testing.T) {
+	provider := &countingEVMProvider{}
+	ac, err := NewAutonityContract(&testBlockchainer{}, common.Address{}, 0, testABIV1, provider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := &types.Header{Number: big.NewInt(1)}
+	misbehaviours := []Misbehaviour{{Offender: common.Address{1}, Height: big.NewInt(1), Round: new(big.Int)}}
+
+	// contracts without slash can't punish the offenders, the block can't be finalized.
+	if err := ac.callSlash(newViewState(t, returnCode), header, misbehaviours); err != ErrSlashUnsupported {
+		t.Fatalf("Expected %v, got %v", ErrSlashUnsupported, err)
+	}
+	if provider.calls != 0 {
+		t.Fatalf("Expected 0 calls, got %d", provider.calls)
+	}
+}
+
+func TestContract_FinalizeUnsupported(t *testing.T) {
+	const finalizeABI = `[{"inputs":[{"internalType":"uint256","name":"amount","type":"uint256"},{"internalType":"address","name":"_signer","type":"address"}],"name":"finalize","outputs":[],"stateMutability":"nonpayable","type":"function"}]`
+	provider := &countingEVMProvider{}
+	ac, err := NewAutonityContract(&testBlockchainer{}, common.Address{}, 0, finalizeABI, provider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := &types.Header{Number: big.NewInt(1)}
+
+	_, _, err = ac.callFinalize(newViewState(t, returnCode), header, new(big.Int), nil, Participation{})
+	if !errors.Is(err, ErrUnsupportedSignature) {
+		t.Fatalf("Expected %v, got %v", ErrUnsupportedSignature, err)
+	}
+	if provider.calls != 0 {
+		t.Fatalf("Expected 0 calls, got %d", provider.calls)
+	}
+}
+
+func TestContract_RewardDistribution(t *testing.T) {
+	const rewardedABI = `[{"anonymous":false,"inputs":[{"indexed":false,"internalType":"address","name":"_address","type":"address"},{"indexed":false,"internalType":"uint256","name":"_amount","type":"uint256"}],"name":"Rewarded","type":"event"}]`
+	ac, err := NewAutonityContract(&testBlockchainer{}, common.Address{}, 0, rewardedABI, &countingEVMProvider{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := ac.ABIAt(0).Events["Rewarded"]
+	rewardedLog := func(address common.Address, amount int64) *types.Log {
+		data, err := event.Inputs.Pack(address, big.NewInt(amount))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &types.Log{Address: ContractAddress, Topics: []common.Hash{event.ID}, Data: data}
+	}
+	header := &types.Header{Number: big.NewInt(5)}
+	holder := common.Address{1}
+	logs := []*types.Log{
+		rewardedLog(holder, 3),
+		// events which weren't emitted by the contract are ignored.
+		{Address: common.Address{2}, Topics: []common.Hash{event.ID}, Data: rewardedLog(holder, 4).Data},
+	}
+
+	t.Run("rewards are read from the contract events", func(t *testing.T) {
+		distribution := ac.rewardDistribution(header, logs, big.NewInt(3), Participation{})
+		if !distribution.Result {
+			t.Fatal("Expected the rewards to be distributed")
+		}
+		if len(distribution.Holders) != 1 || distribution.Holders[0] != holder || distribution.Rewardfractions[0].Int64() != 3 {
+			t.Fatalf("Unexpected distribution %v %v", distribution.Holders, distribution.Rewardfractions)
+		}
+	})
+
+	t.Run("nothing distributed", func(t *testing.T) {
+		if distribution := ac.rewardDistribution(header, nil, big.NewInt(3), Participation{}); distribution.Result {
+			t.Fatal("Expected no reward distribution")
+		}
+	})
+
+	t.Run("metrics are recorded once the block is written", func(t *testing.T) {
+		block := types.NewBlockWithHeader(header)
+		metricID := ac.metrics.generateRewardDistributionMetricsID(holder, Stakeholder, block.NumberU64())
+
+		ac.rewards.Add(block.Hash(), ac.rewardDistribution(header, logs, big.NewInt(3), Participation{}))
+		if metrics.Get(metricID) != nil {
+			t.Fatal("Metrics recorded before the block is written")
+		}
+		ac.MeasureRewardDistribution(block)
+		if metrics.Get(metricID) == nil {
+			t.Fatal("Expected the reward distribution metrics")
+		}
+		if ac.rewards.Contains(block.Hash()) {
+			t.Fatal("Expected the reward distribution to be dropped")
+		}
+	})
+}
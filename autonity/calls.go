@@ -1,10 +1,12 @@
 package autonity
 
 import (
+	"fmt"
 	"math"
 	"math/big"
 	"reflect"
 	"sort"
+	"strings"
 
 	"github.com/clearmatics/autonity/accounts/abi"
 	"github.com/clearmatics/autonity/common"
@@ -23,6 +25,7 @@ import (
 type ContractState struct {
 	Users           []common.Address `abi:"users"`
 	Enodes          []string         `abi:"enodes"`
+	Consensus       []common.Address `abi:"consensus"`
 	Types           []*big.Int       `abi:"types"`
 	Stakes          []*big.Int       `abi:"stakes"`
 	CommissionRates []*big.Int       `abi:"commisionrates"`
@@ -34,6 +37,27 @@ type ContractState struct {
 
 type raw []byte
 
+// The signatures of the protocol calls made by each autonity contract version,
+// the latest first.
+const (
+	constructorSig              = "address[],string[],address[],uint256[],uint256[],address,uint256,uint256,string,address[],address[]"
+	consensusKeysConstructorSig = "address[],string[],address[],uint256[],uint256[],address,uint256,uint256,string"
+	legacyConstructorSig        = "address[],string[],uint256[],uint256[],address,uint256,uint256,string"
+
+	finalizeSig       = "finalize(uint256,address[],address[])"
+	legacyFinalizeSig = "finalize(uint256)"
+)
+
+// argumentTypes returns the comma separated types of the arguments, as they
+// appear in a method signature.
+func argumentTypes(args abi.Arguments) string {
+	types := make([]string, len(args))
+	for i, arg := range args {
+		types[i] = arg.Type.String()
+	}
+	return strings.Join(types, ",")
+}
+
 func DeployContract(abi *abi.ABI, autonityConfig *params.AutonityContractGenesis, evm *vm.EVM) error {
 	// Convert the contract bytecode from hex into bytes
 	contractBytecode := common.Hex2Bytes(autonityConfig.Bytecode)
@@ -41,6 +65,7 @@ func DeployContract(abi *abi.ABI, autonityConfig *params.AutonityContractGenesis
 	ln := len(autonityConfig.GetValidatorUsers())
 	validators := make(common.Addresses, 0, ln)
 	enodes := make([]string, 0, ln)
+	consensusAddresses := make(common.Addresses, 0, ln)
 	accTypes := make([]*big.Int, 0, ln)
 	participantStake := make([]*big.Int, 0, ln)
 
@@ -50,19 +75,32 @@ func DeployContract(abi *abi.ABI, autonityConfig *params.AutonityContractGenesis
 	for _, v := range autonityConfig.Users {
 		validators = append(validators, *v.Address)
 		enodes = append(enodes, v.Enode)
+		consensusAddresses = append(consensusAddresses, v.GetConsensusAddress())
 		accTypes = append(accTypes, big.NewInt(int64(v.Type.GetID())))
 		participantStake = append(participantStake, big.NewInt(int64(v.Stake)))
 	}
 
-	constructorParams, err := abi.Pack("",
-		validators,
-		enodes,
-		accTypes,
-		participantStake,
-		autonityConfig.Operator,
-		new(big.Int).SetUint64(autonityConfig.MinGasPrice),
-		defaultCommitteeSize,
-		defaultVersion)
+	var args []interface{}
+	switch sig := argumentTypes(abi.Constructor.Inputs); sig {
+	case constructorSig:
+		// the owners of the consensus addresses are carried over by the upgrades only.
+		args = []interface{}{validators, enodes, consensusAddresses, accTypes, participantStake,
+			autonityConfig.Operator, new(big.Int).SetUint64(autonityConfig.MinGasPrice), defaultCommitteeSize,
+			defaultVersion, common.Addresses{}, common.Addresses{}}
+	case consensusKeysConstructorSig:
+		args = []interface{}{validators, enodes, consensusAddresses, accTypes, participantStake,
+			autonityConfig.Operator, new(big.Int).SetUint64(autonityConfig.MinGasPrice), defaultCommitteeSize,
+			defaultVersion}
+	case legacyConstructorSig:
+		// contracts predating the consensus keys sign with the enode keys.
+		args = []interface{}{validators, enodes, accTypes, participantStake,
+			autonityConfig.Operator, new(big.Int).SetUint64(autonityConfig.MinGasPrice), defaultCommitteeSize,
+			defaultVersion}
+	default:
+		return fmt.Errorf("%w: constructor(%s)", ErrUnsupportedSignature, sig)
+	}
+
+	constructorParams, err := abi.Pack("", args...)
 	if err != nil {
 		log.Error("contractABI.Pack returns err", "err", err)
 		return err
@@ -83,26 +121,56 @@ func DeployContract(abi *abi.ABI, autonityConfig *params.AutonityContractGenesis
 	return nil
 }
 
-func (ac *Contract) updateAutonityContract(header *types.Header, statedb *state.StateDB, bytecode string, state []byte) error {
+// updateAutonityContract deploys the new contract bytecode at the autonity
+// contract address, its constructor migrates the given state. It returns the
+// gas used by the deployment.
+func (ac *Contract) updateAutonityContract(header *types.Header, statedb *state.StateDB, bytecode string, state []byte) (uint64, error) {
 	evm := ac.evmProvider.EVM(header, Deployer, statedb)
 	contractBytecode := common.Hex2Bytes(bytecode)
 	data := append(contractBytecode, state...)
 	gas := uint64(0xFFFFFFFF)
 	value := new(big.Int).SetUint64(0x00)
-	_, _, _, vmerr := evm.CreateWithAddress(vm.AccountRef(Deployer), data, gas, value, ContractAddress)
+	_, _, leftOverGas, vmerr := evm.CreateWithAddress(vm.AccountRef(Deployer), data, gas, value, ContractAddress)
 	if vmerr != nil {
 		log.Error("updateAutonityContract evm.Create", "err", vmerr)
-		return vmerr
+		return gas - leftOverGas, vmerr
 	}
-	return nil
+	return gas - leftOverGas, nil
 }
 
-// AutonityContractCall calls the specified function of the autonity contract
-// with the given args, and returns the output unpacked into the result
-// interface.
+// AutonityContractCall calls the specified view function of the autonity
+// contract with the given args, and returns the output unpacked into the
+// result interface. The state is expected to be the one of the header's block,
+// its arguments and output are encoded with the ABI in effect at that height.
 func (ac *Contract) AutonityContractCall(statedb *state.StateDB, header *types.Header, function string, result interface{}, args ...interface{}) error {
+	contractABI := ac.ABIAt(header.Number.Uint64())
+	packedArgs, err := contractABI.Pack(function, args...)
+	if err != nil {
+		return err
+	}
+	ret, err := ac.viewCall(statedb, header, function, packedArgs)
+	if err != nil {
+		return err
+	}
+	return unpackResult(contractABI, function, ret, result)
+}
+
+// finalizeCall is used for the calls made while the header's block is being
+// finalized, the state is still the one of its parent block.
+func (ac *Contract) finalizeCall(statedb *state.StateDB, header *types.Header, function string, result interface{}, args ...interface{}) error {
+	return ac.contractCall(ac.parentABI(header), statedb, header, function, result, args...)
+}
 
-	packedArgs, err := ac.contractABI.Pack(function, args...)
+// parentABI returns the ABI in effect before the header's block is finalized.
+func (ac *Contract) parentABI(header *types.Header) *abi.ABI {
+	if header.Number.Uint64() == 0 {
+		return ac.ABIAt(0)
+	}
+	return ac.ABIAt(header.Number.Uint64() - 1)
+}
+
+func (ac *Contract) contractCall(contractABI *abi.ABI, statedb *state.StateDB, header *types.Header, function string, result interface{}, args ...interface{}) error {
+	packedArgs, err := contractABI.Pack(function, args...)
 	if err != nil {
 		return err
 	}
@@ -111,14 +179,18 @@ func (ac *Contract) AutonityContractCall(statedb *state.StateDB, header *types.H
 	if err != nil {
 		return err
 	}
+	return unpackResult(contractABI, function, ret, result)
+}
+
+func unpackResult(contractABI *abi.ABI, function string, ret []byte, result interface{}) error {
 	// if result's type is "raw" then bypass unpacking
 	if reflect.TypeOf(result) == reflect.TypeOf(&raw{}) {
 		rawPtr := result.(*raw)
-		*rawPtr = raw(ret)
+		*rawPtr = raw(common.CopyBytes(ret))
 		return nil
 	}
 
-	if err := ac.contractABI.UnpackIntoInterface(result, function, ret); err != nil {
+	if err := contractABI.UnpackIntoInterface(result, function, ret); err != nil {
 		log.Error("Could not unpack returned value", "function", function)
 		return err
 	}
@@ -168,25 +240,64 @@ func (ac *Contract) callGetProposer(state *state.StateDB, header *types.Header,
 	return proposer
 }
 
-func (ac *Contract) callFinalize(state *state.StateDB, header *types.Header, blockGas *big.Int) (bool, types.Committee, error) {
+func (ac *Contract) callFinalize(state *state.StateDB, header *types.Header, blockGas *big.Int, misbehaviours []Misbehaviour, participation Participation) (bool, types.Committee, error) {
 
 	var updateReady bool
 	var committee types.Committee
 
-	err := ac.AutonityContractCall(state, header, "finalize", &[]interface{}{&updateReady, &committee}, blockGas)
+	// offenders are punished first so that they are left out of the next committee.
+	if len(misbehaviours) > 0 {
+		if err := ac.callSlash(state, header, misbehaviours); err != nil {
+			return false, nil, err
+		}
+	}
+
+	var args []interface{}
+	switch sig := ac.parentABI(header).Methods["finalize"].Sig; sig {
+	case finalizeSig:
+		args = []interface{}{blockGas, addressesOrEmpty(participation.Signers), addressesOrEmpty(participation.Absentees)}
+	case legacyFinalizeSig:
+		// contracts deployed before participation was tracked only take the block fees.
+		args = []interface{}{blockGas}
+	default:
+		return false, nil, fmt.Errorf("%w: %s", ErrUnsupportedSignature, sig)
+	}
+
+	err := ac.finalizeCall(state, header, "finalize", &[]interface{}{&updateReady, &committee}, args...)
 	if err != nil {
 		return false, nil, err
 	}
 	sort.Sort(committee)
-	// submit the final reward distribution metrics.
-	//ac.metrics.SubmitRewardDistributionMetrics(&v, header.Number.Uint64())
 	return updateReady, committee, nil
 }
 
+func (ac *Contract) callSlash(state *state.StateDB, header *types.Header, misbehaviours []Misbehaviour) error {
+	// a block carrying evidence can't be finalized by a contract unable to punish the offenders.
+	if _, ok := ac.parentABI(header).Methods["slash"]; !ok {
+		return ErrSlashUnsupported
+	}
+	offenders := make([]common.Address, len(misbehaviours))
+	heights := make([]*big.Int, len(misbehaviours))
+	rounds := make([]*big.Int, len(misbehaviours))
+	steps := make([]uint8, len(misbehaviours))
+	evidence := make([][32]byte, len(misbehaviours))
+	for i, m := range misbehaviours {
+		offenders[i] = m.Offender
+		heights[i] = m.Height
+		rounds[i] = m.Round
+		steps[i] = m.Step
+		evidence[i] = m.Evidence
+	}
+
+	// slash doesn't return anything, the output is not unpacked.
+	var ret raw
+	return ac.finalizeCall(state, header, "slash", &ret, offenders, heights, rounds, steps, evidence)
+}
+
 func (ac *Contract) callRetrieveState(statedb *state.StateDB, header *types.Header) ([]byte, error) {
 	var state raw
 
-	err := ac.AutonityContractCall(statedb, header, "getState", &state)
+	err := ac.finalizeCall(statedb, header, "getState", &state)
 	if err != nil {
 		return nil, err
 	}
@@ -197,7 +308,7 @@ func (ac *Contract) callRetrieveState(statedb *state.StateDB, header *types.Head
 func (ac *Contract) callRetrieveContract(state *state.StateDB, header *types.Header) (string, string, error) {
 	var bytecode string
 	var abi string
-	err := ac.AutonityContractCall(state, header, "getNewContract", &[]interface{}{&bytecode, &abi})
+	err := ac.finalizeCall(state, header, "getNewContract", &[]interface{}{&bytecode, &abi})
 	if err != nil {
 		return "", "", err
 	}
@@ -209,7 +320,7 @@ func (ac *Contract) callSetMinimumGasPrice(state *state.StateDB, header *types.H
 	gas := uint64(0xFFFFFFFF)
 	evm := ac.evmProvider.EVM(header, Deployer, state)
 
-	input, err := ac.contractABI.Pack("setMinimumGasPrice")
+	input, err := ac.ABIAt(header.Number.Uint64()).Pack("setMinimumGasPrice")
 	if err != nil {
 		return err
 	}
@@ -221,3 +332,10 @@ func (ac *Contract) callSetMinimumGasPrice(state *state.StateDB, header *types.H
 	}
 	return nil
 }
+
+func addressesOrEmpty(addresses []common.Address) []common.Address {
+	if addresses == nil {
+		return []common.Address{}
+	}
+	return addresses
+}
@@ -48,6 +48,9 @@ const (
 	// gauge tracks the reward/transactionfee of a specific block.
 	BlockRewardBlockMetricID = "contract/block/%v/reward"
 
+	// gauge tracks whether a committee member has signed the previous block, 1 if it did and 0 otherwise.
+	BlockParticipationMetricIDTemplate = "contract/block/%v/user/%s/participation"
+
 	RoleUnknown     = "unknown"
 	RoleValidator   = "validator"
 	RoleStakeHolder = "stakeholder"
@@ -77,6 +80,8 @@ type RewardDistributionMetaData struct {
 	Holders         []common.Address `abi:"stakeholders"`
 	Rewardfractions []*big.Int       `abi:"rewardfractions"`
 	Amount          *big.Int         `abi:"amount"`
+	Participants    []common.Address `abi:"participants"`
+	Absentees       []common.Address `abi:"absentees"`
 }
 
 type EconomicMetrics struct {
@@ -139,6 +144,14 @@ func (em *EconomicMetrics) SubmitRewardDistributionMetrics(v *RewardDistribution
 		em.recordMetric(rewardDistributionMetricID, v.Rewardfractions[i], true)
 	}
 
+	// submit the participation of the committee members to registry.
+	for _, participant := range v.Participants {
+		em.recordMetric(em.generateParticipationMetricsID(participant, height), common.Big1, false)
+	}
+	for _, absentee := range v.Absentees {
+		em.recordMetric(em.generateParticipationMetricsID(absentee, height), common.Big0, false)
+	}
+
 	// submit block reward metric to registry.
 	blockRewardMetricID := em.generateBlockRewardMetricsID(height)
 	em.recordMetric(blockRewardMetricID, v.Amount, true)
@@ -157,6 +170,10 @@ func (em *EconomicMetrics) generateRewardDistributionMetricsID(address common.Ad
 	return blockMetricsID
 }
 
+func (em *EconomicMetrics) generateParticipationMetricsID(address common.Address, blockNumber uint64) string {
+	return fmt.Sprintf(BlockParticipationMetricIDTemplate, blockNumber, address.String())
+}
+
 func (em *EconomicMetrics) resolveUserTypeName(role uint8) string {
 	ret := RoleUnknown
 	switch role {
@@ -243,6 +260,7 @@ func (em *EconomicMetrics) removeMetricsOutOfWindow(blockNumber uint64) {
 		for _, user := range em.users {
 			blcRwdDistributionID := em.generateRewardDistributionMetricsID(user, Stakeholder, height)
 			metrics.DefaultRegistry.Unregister(blcRwdDistributionID)
+			metrics.DefaultRegistry.Unregister(em.generateParticipationMetricsID(user, height))
 		}
 		blcRwdID := em.generateBlockRewardMetricsID(height)
 		metrics.DefaultRegistry.Unregister(blcRwdID)
@@ -269,6 +269,27 @@ func TestEconomicMetrics_measureRewardDistributionMetrics(t *testing.T) {
 			t.Fatal("case failed.")
 		}
 	})
+
+	t.Run("measure reward distribution metrics, participation of committee members.", func(t *testing.T) {
+		em := &EconomicMetrics{}
+		signer := common.BytesToAddress(common.Hex2Bytes(testAddress1))
+		absentee := common.BytesToAddress(common.Hex2Bytes(testAddress2))
+		height := uint64(7)
+
+		em.SubmitRewardDistributionMetrics(&RewardDistributionMetaData{
+			Result:       true,
+			Amount:       common.Big0,
+			Participants: []common.Address{signer},
+			Absentees:    []common.Address{absentee},
+		}, height)
+
+		if metrics.Get(em.generateParticipationMetricsID(signer, height)) == nil {
+			t.Fatal("case failed.")
+		}
+		if metrics.Get(em.generateParticipationMetricsID(absentee, height)) == nil {
+			t.Fatal("case failed.")
+		}
+	})
 }
 
 func TestEconomicMetrics_recordMetric(t *testing.T) {
@@ -16,11 +16,16 @@ contract Autonity is IERC20 {
         UserType userType;
         uint256 stake;
         string enode;
+        address consensusAddress;
     }
 
+    /* `addr` is the address of the key signing the consensus messages, the rewards
+    of the member are paid to its `treasury`. */
     struct CommitteeMember {
-        address payable addr;
+        address addr;
         uint256 votingPower;
+        bytes consensusKey;
+        address payable treasury;
     }
 
     struct EconomicMetrics {
@@ -42,6 +47,34 @@ contract Autonity is IERC20 {
 
     mapping (address => mapping (address => uint256)) private allowances;
 
+    /* Part of the stake burnt when a validator is slashed, in basis points. */
+    uint256 public constant SLASHING_RATE_PRECISION = 10000;
+    uint256 public slashingRate = 1000;
+    /* Faults already punished, keyed by offender, height, round and step, a fault is only punished once. */
+    mapping (bytes32 => bool) private slashedFaults;
+
+    /* Validators absent from `inactivityThreshold` blocks in a row lose `inactivityPenaltyRate`
+    of their stake, in basis points. */
+    uint256 public inactivityThreshold = 100;
+    uint256 public inactivityPenaltyRate = 10;
+    mapping (address => uint256) private missedBlocks;
+    mapping (address => uint256) private lastMissedBlock;
+
+    /* BLS public key followed by its proof of possession, committed seals are aggregated
+    once every committee member has registered one. */
+    uint256 public constant CONSENSUS_KEY_LENGTH = 288;
+    mapping (address => bytes) private consensusKeys;
+
+    /* Users owning each consensus address, including the addresses rotated out so that the
+    participation of the previous committee is still accounted for. */
+    mapping (address => address) private consensusOwners;
+    /* Keys of consensusOwners, migrated during a contract upgrade. */
+    address[] private consensusAddresses;
+    /* Consensus keys rotations requested by the users, applied at the next committee computation. */
+    address[] private pendingRotations;
+    mapping (address => address) private pendingConsensusAddresses;
+    mapping (address => bytes) private pendingConsensusKeys;
+
     /* State data that will be recomputed during a contract upgrade. */
     address[] private validators;
     address[] private stakeholders;
@@ -71,6 +104,11 @@ contract Autonity is IERC20 {
     event MintedStake(address _address, uint256 _amount);
     event BurnedStake(address _address, uint256 _amount);
     event Rewarded(address _address, uint256 _amount);
+    event Slashed(address _address, uint256 _amount, bytes32 _evidence);
+    event InactivityPenalty(address _address, uint256 _amount);
+    event ConsensusKeyRegistered(address _address);
+    event ConsensusKeyRotationRequested(address _address, address _consensusAddress);
+    event ConsensusKeyRotated(address _address, address _consensusAddress);
 
     /**
      * @dev Emitted when the Minimum Gas Price was updated and set to `gasPrice`.
@@ -85,14 +123,18 @@ contract Autonity is IERC20 {
 
     constructor (address[] memory _participantAddress,
         string[] memory _participantEnode,
+        address[] memory _participantConsensus,
         uint256[] memory _participantType,
         uint256[] memory _participantStake,
         address _operatorAccount,
         uint256 _minGasPrice,
         uint256 _committeeSize,
-        string memory _contractVersion) {
+        string memory _contractVersion,
+        address[] memory _consensusAddresses,
+        address[] memory _consensusOwners) {
 
         require(_participantAddress.length == _participantEnode.length
+        && _participantAddress.length == _participantConsensus.length
         && _participantAddress.length == _participantType.length
         && _participantAddress.length == _participantStake.length,
             "Incorrect constructor params");
@@ -101,8 +143,9 @@ contract Autonity is IERC20 {
             require(_participantAddress[i] != address(0), "Addresses must be defined");
             UserType _userType = UserType(_participantType[i]);
             address payable addr = address(uint160(_participantAddress[i]));
-            _createUser(addr, _participantEnode[i], _userType, _participantStake[i]);
+            _createUser(addr, _participantEnode[i], _participantConsensus[i], _userType, _participantStake[i]);
         }
+        _restoreConsensusOwners(_consensusAddresses, _consensusOwners);
         operatorAccount = _operatorAccount;
         minGasPrice = _minGasPrice;
         contractVersion = _contractVersion;
@@ -141,13 +184,51 @@ contract Autonity is IERC20 {
 
     /**
     * @notice Create a user in the Autonity Contract with the specified role. Restricted to the operator account.
+    * The user signs the consensus messages with the key of its account until it rotates its consensus key.
     */
     function addUser(address payable _address, uint256 _stake, string memory _enode, UserType _role) public onlyOperator(msg.sender) {
         require(!(_role == UserType.Participant && _stake > 0), "participant can't have stake");
-        _createUser(_address, _enode, _role, _stake);
+        _createUser(_address, _enode, address(0), _role, _stake);
         emit UserAdded(_address, _role, _stake);
     }
 
+    /**
+    * @notice Register the BLS consensus key of the caller's validator node alongside its enode.
+    * The proof of possession is checked by the protocol, a committee member with an invalid key
+    * prevents the aggregation of committed seals.
+    */
+    function registerConsensusKey(bytes memory _key) public {
+        require(users[msg.sender].addr != address(0), "user must exists");
+        require(_key.length == CONSENSUS_KEY_LENGTH, "invalid consensus key length");
+        consensusKeys[msg.sender] = _key;
+        emit ConsensusKeyRegistered(msg.sender);
+    }
+
+    /**
+    * @notice Replace the consensus key of the caller's validator node, `_consensusAddress` being the
+    * address of the new key and `_key` its BLS key, if any. The caller's account stays the treasury
+    * receiving the rewards. The new key takes effect at the next committee computation, the node is
+    * expected to hold both keys until then.
+    */
+    function rotateConsensusKey(address _consensusAddress, bytes memory _key) public {
+        require(users[msg.sender].addr != address(0), "user must exists");
+        require(_consensusAddress != address(0), "consensus address must be defined");
+        require(consensusOwners[_consensusAddress] == address(0), "consensus address already in use");
+        require(_key.length == 0 || _key.length == CONSENSUS_KEY_LENGTH, "invalid consensus key length");
+
+        address _pending = pendingConsensusAddresses[msg.sender];
+        if (_pending == address(0)) {
+            pendingRotations.push(msg.sender);
+        } else {
+            // the previous request is superseded, its address is released.
+            delete consensusOwners[_pending];
+        }
+        _setConsensusOwner(_consensusAddress, msg.sender);
+        pendingConsensusAddresses[msg.sender] = _consensusAddress;
+        pendingConsensusKeys[msg.sender] = _key;
+        emit ConsensusKeyRotationRequested(msg.sender, _consensusAddress);
+    }
+
     /**
     * @notice Change the user account type. Restricted to the operator account.
     */
@@ -163,6 +244,7 @@ contract Autonity is IERC20 {
     */
     function removeUser(address account) public onlyOperator(msg.sender) {
         _removeUser(account);
+        delete consensusKeys[account];
     }
 
     /**
@@ -183,6 +265,26 @@ contract Autonity is IERC20 {
         committeeSize = size;
     }
 
+    /*
+    * @notice Set the part of the stake burnt when a validator is slashed, in basis points.
+    * Restricted to the Operator account.
+    */
+    function setSlashingRate(uint256 rate) public onlyOperator(msg.sender) {
+        require(rate <= SLASHING_RATE_PRECISION, "slashing rate exceeds 100%");
+        slashingRate = rate;
+    }
+
+    /*
+    * @notice Set the number of blocks in a row a validator can miss before being penalised and the part
+    * of its stake burnt, in basis points. Restricted to the Operator account.
+    */
+    function setInactivityPenalty(uint256 threshold, uint256 rate) public onlyOperator(msg.sender) {
+        require(threshold > 0, "inactivity threshold must be positive");
+        require(rate <= SLASHING_RATE_PRECISION, "inactivity penalty rate exceeds 100%");
+        inactivityThreshold = threshold;
+        inactivityPenaltyRate = rate;
+    }
+
     /*
     * @notice Mint new stake token (NEW) and add it to the recipient balance. Restricted to the Operator account.
     * @dev emit a MintStake event.
@@ -277,39 +379,75 @@ contract Autonity is IERC20 {
     * protocol only.
     *
     * @param amount The amount of transaction fees collected for this block.
+    * @param _signers The committee members who have signed the previous block.
+    * @param _absentees The committee members who haven't signed the previous block.
     * @return upgrade Set to true if an autonity contract upgrade is available.
     * @return committee The next block consensus committee.
     */
-    function finalize(uint256 amount) external onlyProtocol(msg.sender)
-        returns(bool , CommitteeMember[] memory) {
+    function finalize(uint256 amount, address[] memory _signers, address[] memory _absentees)
+        external onlyProtocol(msg.sender) returns(bool , CommitteeMember[] memory) {
 
+        _recordParticipation(_signers, _absentees);
         _performRedistribution(amount);
         bool _updateAvailable = bytes(bytecode).length != 0;
         computeCommittee();
         return (_updateAvailable, committee);
     }
 
+    /** @dev slash punishes the committee members for which the block holds an
+    * evidence of misbehaviour. It is called by the protocol before finalize so that
+    * the offenders are left out of the next committee. It must be restricted to the protocol only.
+    *
+    * @param _offenders The committee members who have misbehaved.
+    * @param _heights The height of each misbehaviour.
+    * @param _rounds The round of each misbehaviour.
+    * @param _steps The step of each misbehaviour.
+    * @param _evidence The hash of the evidence of each misbehaviour.
+    */
+    function slash(address[] memory _offenders, uint256[] memory _heights, uint256[] memory _rounds,
+        uint8[] memory _steps, bytes32[] memory _evidence) external onlyProtocol(msg.sender) {
+        require(_offenders.length == _heights.length && _offenders.length == _rounds.length &&
+            _offenders.length == _steps.length && _offenders.length == _evidence.length, "Incorrect slash params");
+        for (uint256 i = 0; i < _offenders.length; i++) {
+            // different evidence can prove the same fault.
+            bytes32 _fault = keccak256(abi.encodePacked(_offenders[i], _heights[i], _rounds[i], _steps[i]));
+            if (slashedFaults[_fault]) {
+                continue;
+            }
+            slashedFaults[_fault] = true;
+            _slash(consensusOwners[_offenders[i]], _evidence[i]);
+        }
+    }
+
     /**
     * @dev Dump the current internal state key elements. Called by the protocol during a contract upgrade.
     * The returned data will be passed directly to the constructor of the new contract at deployment.
+    * The consensus key rotations are applied by finalize beforehand, the state can't be dumped while
+    * some are pending.
     */
     function getState() external view returns(
         address[] memory _addr,
         string[] memory _enode,
+        address[] memory _consensus,
         uint256[] memory _userType,
         uint256[] memory _stake,
         address _operatorAccount,
         uint256 _minGasPrice,
         uint256 _committeeSize,
-        string memory _contractVersion) {
+        string memory _contractVersion,
+        address[] memory _consensusAddresses,
+        address[] memory _consensusOwners) {
 
+        require(pendingRotations.length == 0, "consensus key rotations pending");
         // Exceptionally using named returns here, make things clearer.
         _addr = new address[](usersList.length);
         _userType  = new uint256[](usersList.length);
         _stake = new uint256[](usersList.length);
         _enode = new string[](usersList.length);
+        _consensus = new address[](usersList.length);
         for(uint256 i=0; i<usersList.length; i++ ) {
             _addr[i] = users[usersList[i]].addr;
+            _consensus[i] = users[usersList[i]].consensusAddress;
             _userType[i] = uint256(users[usersList[i]].userType);
             _stake[i] = users[usersList[i]].stake;
             _enode[i] = users[usersList[i]].enode;
@@ -318,6 +456,7 @@ contract Autonity is IERC20 {
         _minGasPrice = minGasPrice;
         _committeeSize = committeeSize;
         _contractVersion = contractVersion;
+        (_consensusAddresses, _consensusOwners) = _dumpConsensusOwners();
     }
 
     /*
@@ -386,6 +525,20 @@ contract Autonity is IERC20 {
         return users[_account];
     }
 
+    /**
+    * @return Returns the consensus key registered by `_account`, empty if there is none.
+    */
+    function getConsensusKey(address _account) external view returns(bytes memory) {
+        return consensusKeys[_account];
+    }
+
+    /**
+    * @return Returns the user owning the consensus address `_consensusAddress`, zero if there is none.
+    */
+    function getConsensusOwner(address _consensusAddress) external view returns(address) {
+        return consensusOwners[_consensusAddress];
+    }
+
     /**
     * @return Returns the maximum size of the consensus committee.
     */
@@ -462,6 +615,7 @@ contract Autonity is IERC20 {
     function computeCommittee() public onlyProtocol(msg.sender) {
         // Left public for testing purposes.
         require(validators.length > 0, "There must be validators");
+        _applyRotations();
         uint _len = validators.length;
         uint256 _committeeLength = committeeSize;
         if (_committeeLength >= _len) {_committeeLength = _len;}
@@ -491,7 +645,8 @@ contract Autonity is IERC20 {
         // Update committee in persistent storage
         delete committee;
         for (uint256 _k =0 ; _k < _committeeLength; _k++) {
-            CommitteeMember memory _member = CommitteeMember(_committeeList[_k].addr, _committeeList[_k].stake);
+            CommitteeMember memory _member = CommitteeMember(_committeeList[_k].consensusAddress,
+                _committeeList[_k].stake, consensusKeys[_committeeList[_k].addr], _committeeList[_k].addr);
             committee.push(_member);
         }
 
@@ -546,22 +701,122 @@ contract Autonity is IERC20 {
 
     /**
     * @notice Perform Auton reward distribution. The transaction fees
-    * are simply re-distributed to all stake-holders, including validators,
-    * pro-rata the amount of stake held.
+    * are re-distributed to all stake-holders, including validators,
+    * pro-rata the amount of stake held. Validators who haven't signed
+    * the previous block are left out.
     * @dev Emit a {BlockReward} event for every account that collected rewards.
     */
     function _performRedistribution(uint256 _amount) internal  {
         require(address(this).balance >= _amount, "not enough funds to perform redistribution");
         require(stakeholders.length > 0, "there must be stake holders");
 
+        uint256 _activeStake = 0;
+        for (uint256 i = 0; i < stakeholders.length; i++) {
+            if (lastMissedBlock[stakeholders[i]] != block.number) {
+                _activeStake = _activeStake.add(users[stakeholders[i]].stake);
+            }
+        }
+        if (_activeStake == 0) {
+            return;
+        }
+
         for (uint256 i = 0; i < stakeholders.length; i++) {
             User storage _user = users[stakeholders[i]];
-            uint256 _reward = _user.stake.mul(_amount).div(stakeSupply);
+            if (lastMissedBlock[_user.addr] == block.number) {
+                continue;
+            }
+            uint256 _reward = _user.stake.mul(_amount).div(_activeStake);
             _user.addr.transfer(_reward);
             emit Rewarded(_user.addr, _reward);
         }
     }
 
+    /**
+    * @notice Keep track of the blocks missed in a row by each committee member, absentees
+    * reaching `inactivityThreshold` are penalised.
+    */
+    function _recordParticipation(address[] memory _signers, address[] memory _absentees) internal {
+        for (uint256 i = 0; i < _signers.length; i++) {
+            missedBlocks[consensusOwners[_signers[i]]] = 0;
+        }
+        for (uint256 i = 0; i < _absentees.length; i++) {
+            address _owner = consensusOwners[_absentees[i]];
+            if (_owner == address(0)) {
+                continue;
+            }
+            lastMissedBlock[_owner] = block.number;
+            missedBlocks[_owner] = missedBlocks[_owner].add(1);
+            if (missedBlocks[_owner] >= inactivityThreshold) {
+                missedBlocks[_owner] = 0;
+                _penalizeInactivity(_owner);
+            }
+        }
+    }
+
+    /**
+    * @notice Switch the users who have requested it to their new consensus key.
+    * @dev Emit a {ConsensusKeyRotated} event for every rotation applied.
+    */
+    function _applyRotations() internal {
+        for (uint256 i = 0; i < pendingRotations.length; i++) {
+            address _user = pendingRotations[i];
+            address _consensusAddress = pendingConsensusAddresses[_user];
+            if (users[_user].addr != address(0)) {
+                users[_user].consensusAddress = _consensusAddress;
+                consensusKeys[_user] = pendingConsensusKeys[_user];
+                emit ConsensusKeyRotated(_user, _consensusAddress);
+            }
+            delete pendingConsensusAddresses[_user];
+            delete pendingConsensusKeys[_user];
+        }
+        delete pendingRotations;
+    }
+
+    /**
+    * @notice Burn `inactivityPenaltyRate` of the stake of a validator who has been offline for too long.
+    * @dev Emit a {InactivityPenalty} event.
+    */
+    function _penalizeInactivity(address _address) internal {
+        User storage u = users[_address];
+        if (u.addr == address(0)) {
+            return;
+        }
+
+        uint256 _amount = u.stake.mul(inactivityPenaltyRate).div(SLASHING_RATE_PRECISION);
+        if (_amount == 0) {
+            return;
+        }
+        u.stake = u.stake.sub(_amount);
+        stakeSupply = stakeSupply.sub(_amount);
+        emit InactivityPenalty(u.addr, _amount);
+
+        if (u.stake == 0 && u.userType == UserType.Validator && validators.length > 1) {
+            _changeUserType(u.addr, UserType.Stakeholder);
+        }
+    }
+
+    /**
+    * @notice Burn `slashingRate` of the offender stake and jail it by downgrading
+    * it to a stakeholder, unless it is the last validator of the network.
+    * @dev Emit a {Slashed} event.
+    */
+    function _slash(address _address, bytes32 _evidence) internal {
+        User storage u = users[_address];
+        if (u.addr == address(0)) {
+            // the user has been removed since the misbehaviour.
+            return;
+        }
+
+        uint256 _amount = u.stake.mul(slashingRate).div(SLASHING_RATE_PRECISION);
+        u.stake = u.stake.sub(_amount);
+        stakeSupply = stakeSupply.sub(_amount);
+        emit Slashed(u.addr, _amount, _evidence);
+
+        if (u.userType == UserType.Validator && validators.length > 1) {
+            _changeUserType(u.addr, UserType.Stakeholder);
+        }
+    }
+
     function _transfer(address sender, address recipient, uint256 amount) internal canUseStake(sender) canUseStake(recipient) {
         users[sender].stake = users[sender].stake.sub(amount, "Transfer amount exceeds balance");
         users[recipient].stake = users[recipient].stake.add(amount);
@@ -616,7 +871,7 @@ contract Autonity is IERC20 {
             require(u.stake == 0);
         }
         _removeUser(u.addr);
-        _createUser(u.addr, u.enode, newUserType, u.stake);
+        _createUser(u.addr, u.enode, u.consensusAddress, newUserType, u.stake);
 
         emit ChangedUserType(u.addr , u.userType , newUserType);
     }
@@ -651,11 +906,60 @@ contract Autonity is IERC20 {
     }
 
 
-    function _createUser(address payable _address, string memory _enode, UserType _userType, uint256 _stake) internal {
+    function _setConsensusOwner(address _consensusAddress, address _owner) internal {
+        if (consensusOwners[_consensusAddress] == address(0)) {
+            consensusAddresses.push(_consensusAddress);
+        }
+        consensusOwners[_consensusAddress] = _owner;
+    }
+
+    /**
+    * @dev The addresses rotated out are kept, the misbehaviours of the previous committees can
+    * still be punished after an upgrade.
+    */
+    function _restoreConsensusOwners(address[] memory _consensusAddresses, address[] memory _consensusOwners) internal {
+        require(_consensusAddresses.length == _consensusOwners.length, "Incorrect consensus owners");
+        for (uint256 i = 0; i < _consensusAddresses.length; i++) {
+            if (consensusOwners[_consensusAddresses[i]] == address(0)) {
+                _setConsensusOwner(_consensusAddresses[i], _consensusOwners[i]);
+            }
+        }
+    }
+
+    function _dumpConsensusOwners() internal view returns (address[] memory, address[] memory) {
+        // the addresses released by superseded rotation requests are left out.
+        uint256 _count = 0;
+        for (uint256 i = 0; i < consensusAddresses.length; i++) {
+            if (consensusOwners[consensusAddresses[i]] != address(0)) {
+                _count++;
+            }
+        }
+        address[] memory _addresses = new address[](_count);
+        address[] memory _owners = new address[](_count);
+        uint256 _j = 0;
+        for (uint256 i = 0; i < consensusAddresses.length; i++) {
+            address _owner = consensusOwners[consensusAddresses[i]];
+            if (_owner != address(0)) {
+                _addresses[_j] = consensusAddresses[i];
+                _owners[_j] = _owner;
+                _j++;
+            }
+        }
+        return (_addresses, _owners);
+    }
+
+    /**
+    * @dev The consensus address defaults to the address of the user.
+    */
+    function _createUser(address payable _address, string memory _enode, address _consensusAddress,
+        UserType _userType, uint256 _stake) internal {
         require(_address != address(0), "Addresses must be defined");
         require(Precompiled.enodeCheck(_enode)[0] != 0, "enode error");
+        if (_consensusAddress == address(0)) {
+            _consensusAddress = _address;
+        }
 
-        User memory u = User(_address, _userType, _stake, _enode);
+        User memory u = User(_address, _userType, _stake, _enode, _consensusAddress);
 
         // avoid duplicated user in usersList.
         require(users[u.addr].addr == address(0), "already registered address");
@@ -663,6 +967,7 @@ contract Autonity is IERC20 {
         usersList.push(u.addr);
 
         users[u.addr] = u;
+        _setConsensusOwner(u.consensusAddress, u.addr);
 
         if (u.userType == UserType.Stakeholder){
             stakeholders.push(u.addr);
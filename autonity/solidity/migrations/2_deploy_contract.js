@@ -14,6 +14,13 @@ module.exports = function(deployer, network, accounts) {
         "enode://438a5c2cd8fdc2ecbc508bf7362e41c0f0c3754ba1d3267127a3756324caf45e6546b02140e2144b205aeb372c96c5df9641485f721dc7c5b27eb9e35f5d887b@172.25.0.14:30303",
         "enode://3ce6c053cb563bfd94f4e0e248510a07ccee1bc836c9784da1816dba4b10564e7be1ba42e0bd8d73c8f6274f8e9878dc13814adb381c823264265c06048b4b59@172.25.0.15:30303"
     ],
+    [
+            "0x0000000000000000000000000000000000000000",
+            "0x0000000000000000000000000000000000000000",
+            "0x0000000000000000000000000000000000000000",
+            "0x0000000000000000000000000000000000000000",
+            "0x0000000000000000000000000000000000000000"
+    ],
     [
         2,
         2,
@@ -32,5 +39,7 @@ module.exports = function(deployer, network, accounts) {
     0,
     1000,
     "v1.0.0",
+    [],
+    [],
     { from:accounts[8]} );
 };
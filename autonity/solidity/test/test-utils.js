@@ -2,7 +2,9 @@ const Autonity = artifacts.require("Autonity.sol");
 
 
 const deployContract = async (accounts, enodes, userTypes, stakes, sysOperator, minGasPrice, committeeSize, version, msgSender) => {
-    return Autonity.new(accounts, enodes, userTypes, stakes, sysOperator, minGasPrice, committeeSize, version, msgSender);
+    // the consensus addresses default to the accounts.
+    let consensus = accounts.map(() => "0x0000000000000000000000000000000000000000");
+    return Autonity.new(accounts, enodes, consensus, userTypes, stakes, sysOperator, minGasPrice, committeeSize, version, [], [], msgSender);
 };
 
 
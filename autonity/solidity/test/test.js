@@ -1,6 +1,7 @@
 'use strict';
 const assert = require('assert');
 const utils = require('./test-utils');
+const Autonity = artifacts.require("Autonity.sol");
 //todo: move gas analysis to separate js file
 
 contract('Autonity', function (accounts) {
@@ -97,7 +98,7 @@ contract('Autonity', function (accounts) {
 
         it('test redistribution fails with empty balance', async function () {
             try {
-                await token.finalize(10000, {from: deployer});
+                await token.finalize(10000, [], [], {from: deployer});
                 assert.fail('Expected throw not received', r);
             } catch (e) {
 
@@ -115,7 +116,7 @@ contract('Autonity', function (accounts) {
                 assert.fail("incorrect balance")
             }
             try {
-                await token.finalize(10000, {from: accounts[0]});
+                await token.finalize(10000, [], [], {from: accounts[0]});
                 assert.fail('Expected throw not received', r);
             } catch (e) {
 
@@ -138,7 +139,7 @@ contract('Autonity', function (accounts) {
             let totalStake= stakes.reduce((a,b) => a + b);
             let stakeholdersPart = stakes.map(element => element * performAmount / totalStake);
 
-            await token.finalize(performAmount, {from: operator});
+            await token.finalize(performAmount, [], [], {from: operator});
 
             let balancesAfter = [];
             for (let i = 0; i < st.length; i++) {
@@ -700,6 +701,234 @@ contract('Autonity', function (accounts) {
         });
     });
 
+    describe('Slashing', function() {
+
+        beforeEach(async function(){
+            token = await utils.deployContract(validatorsList, whiteList,
+                userTypes, stakes, operator, minGasPrice, committeeSize, version,  { from:accounts[8]} );
+        });
+
+        it('test slash burns stake and removes the offender from the committee', async function () {
+            let evidence = web3.utils.keccak256("evidence");
+            let stakeSupply = (await token.dumpEconomicMetrics({from: operator})).stakesupply;
+
+            await token.slash([accounts[1]], [5], [0], [1], [evidence], {from: deployer});
+
+            let balance = await token.balanceOf(accounts[1], {from: operator});
+            assert.deepEqual(Number(balance), 90);
+            let newStakeSupply = (await token.dumpEconomicMetrics({from: operator})).stakesupply;
+            assert.deepEqual(Number(stakeSupply) - Number(newStakeSupply), 10);
+
+            let userType = (await token.getUser(accounts[1], {from: operator})).userType;
+            assert(userType == roleStakeHolder, "wrong user type");
+
+            await token.computeCommittee({from: deployer});
+            let committee = await token.getCommittee();
+            assert.deepEqual(committee.length, validatorsList.length - 1);
+            committee.forEach(function (member) {
+                assert.notEqual(member.addr, accounts[1]);
+            });
+        });
+
+        it('test same fault is only acted upon once', async function () {
+            let evidence = web3.utils.keccak256("evidence");
+            let otherEvidence = web3.utils.keccak256("other evidence");
+            await token.slash([accounts[2]], [5], [0], [1], [evidence], {from: deployer});
+            await token.slash([accounts[2]], [5], [0], [1], [evidence], {from: deployer});
+            await token.slash([accounts[2]], [5], [0], [1], [otherEvidence], {from: deployer});
+
+            let balance = await token.balanceOf(accounts[2], {from: operator});
+            assert.deepEqual(Number(balance), 81);
+
+            await token.slash([accounts[2]], [5], [1], [1], [evidence], {from: deployer});
+            balance = await token.balanceOf(accounts[2], {from: operator});
+            assert.deepEqual(Number(balance), 73);
+        });
+
+        it('test slash is restricted to the protocol', async function () {
+            let evidence = web3.utils.keccak256("evidence");
+            try {
+                let r = await token.slash([accounts[1]], [5], [0], [1], [evidence], {from: operator});
+                assert.fail('Expected throw not received', r);
+            } catch (e) {
+                let balance = await token.balanceOf(accounts[1], {from: operator});
+                assert.deepEqual(Number(balance), 100);
+            }
+        });
+
+        it('test set slashing rate by operator account', async function () {
+            await token.setSlashingRate(5000, {from: operator});
+            assert.deepEqual(Number(await token.slashingRate()), 5000);
+
+            try {
+                let r = await token.setSlashingRate(10001, {from: operator});
+                assert.fail('Expected throw not received', r);
+            } catch (e) {
+                assert.deepEqual(Number(await token.slashingRate()), 5000);
+            }
+
+            try {
+                let r = await token.setSlashingRate(0, {from: accounts[1]});
+                assert.fail('Expected throw not received', r);
+            } catch (e) {
+                assert.deepEqual(Number(await token.slashingRate()), 5000);
+            }
+        });
+    });
+
+    describe('Consensus keys', function() {
+
+        beforeEach(async function(){
+            token = await utils.deployContract(validatorsList, whiteList,
+                userTypes, stakes, operator, minGasPrice, committeeSize, version,  { from:accounts[8]} );
+        });
+
+        it('test validators sign with their account by default', async function () {
+            await token.computeCommittee({from: deployer});
+            let committee = await token.getCommittee();
+            committee.forEach(function (member) {
+                assert.deepEqual(member.addr, member.treasury);
+            });
+            assert.deepEqual(await token.getConsensusOwner(accounts[1]), accounts[1]);
+        });
+
+        it('test rotated key takes effect at the next committee', async function () {
+            let consensusAddress = accounts[9];
+            await token.rotateConsensusKey(consensusAddress, "0x", {from: accounts[1]});
+            assert.deepEqual(await token.getConsensusOwner(consensusAddress), accounts[1]);
+
+            let committee = await token.getCommittee();
+            assert(committee.every((member) => member.addr != consensusAddress), "key rotated before the committee computation");
+
+            await token.computeCommittee({from: deployer});
+            committee = await token.getCommittee();
+            let member = committee.find((member) => member.treasury == accounts[1]);
+            assert.deepEqual(member.addr, consensusAddress);
+            // the previous key still maps to the validator
+            assert.deepEqual(await token.getConsensusOwner(accounts[1]), accounts[1]);
+        });
+
+        it('test consensus address in use is refused', async function () {
+            try {
+                let r = await token.rotateConsensusKey(accounts[2], "0x", {from: accounts[1]});
+                assert.fail('Expected throw not received', r);
+            } catch (e) {
+                assert.deepEqual(await token.getConsensusOwner(accounts[2]), accounts[2]);
+            }
+
+            try {
+                let r = await token.rotateConsensusKey(accounts[9], "0x", {from: accounts[7]});
+                assert.fail('Expected throw not received', r);
+            } catch (e) {
+                assert.deepEqual(await token.getConsensusOwner(accounts[9]), "0x0000000000000000000000000000000000000000");
+            }
+        });
+
+        it('test slashing resolves the consensus address to the validator', async function () {
+            await token.rotateConsensusKey(accounts[9], "0x", {from: accounts[1]});
+            await token.computeCommittee({from: deployer});
+
+            let evidence = web3.utils.keccak256("evidence");
+            await token.slash([accounts[9]], [5], [0], [1], [evidence], {from: deployer});
+            let balance = await token.balanceOf(accounts[1], {from: operator});
+            assert.deepEqual(Number(balance), 90);
+        });
+
+        it('test consensus owners are migrated by an upgrade', async function () {
+            await token.rotateConsensusKey(accounts[9], "0x", {from: accounts[1]});
+            await token.computeCommittee({from: deployer});
+
+            let state = await token.getState({from: operator});
+            let upgraded = await Autonity.new(state._addr, state._enode, state._consensus, state._userType,
+                state._stake, state._operatorAccount, state._minGasPrice, state._committeeSize,
+                state._contractVersion, state._consensusAddresses, state._consensusOwners, {from: deployer});
+            assert.deepEqual(await upgraded.getConsensusOwner(accounts[9]), accounts[1]);
+            // the previous committee can still be held accountable
+            assert.deepEqual(await upgraded.getConsensusOwner(accounts[1]), accounts[1]);
+        });
+
+        it('test state is not dumped with pending rotations', async function () {
+            await token.rotateConsensusKey(accounts[9], "0x", {from: accounts[1]});
+            try {
+                let r = await token.getState({from: operator});
+                assert.fail('Expected throw not received', r);
+            } catch (e) {
+                assert(e.message.includes("consensus key rotations pending"), e.message);
+            }
+            await token.computeCommittee({from: deployer});
+            await token.getState({from: operator});
+        });
+    });
+
+    describe('Liveness', function() {
+
+        beforeEach(async function(){
+            token = await utils.deployContract(validatorsList, whiteList,
+                userTypes, stakes, operator, minGasPrice, committeeSize, version, { from:accounts[0]} );
+        });
+
+        it('test absent validator is left out of the redistribution', async function () {
+            let st = await token.getStakeholders({from: operator});
+            for (let i = 0; i < st.length; i++) {
+                await web3.eth.sendTransaction({from: st[i], to: token.address, value: 10000});
+            }
+
+            let balances = [];
+            for (let i = 0; i < st.length; i++) {
+                balances[i] = await web3.eth.getBalance(st[i]);
+            }
+
+            let performAmount = 10000;
+            let activeStake = stakes.slice(1).reduce((a,b) => a + b);
+            await token.finalize(performAmount, validatorsList.slice(1), [validatorsList[0]], {from: operator});
+
+            for (let i = 0; i < st.length; i++) {
+                let expected = i == 0 ? 0 : Math.floor(stakes[i] * performAmount / activeStake);
+                let check = web3.utils.toBN(await web3.eth.getBalance(st[i]))
+                    .sub(web3.utils.toBN(balances[i]))
+                    .eq(web3.utils.toBN(expected));
+                assert(check, "not equal");
+            }
+        });
+
+        it('test validator offline for too long is penalised', async function () {
+            await token.setInactivityPenalty(2, 1000, {from: operator});
+
+            await token.finalize(0, validatorsList.slice(1), [validatorsList[0]], {from: operator});
+            assert.deepEqual(Number(await token.balanceOf(validatorsList[0], {from: operator})), 100);
+
+            await token.finalize(0, validatorsList.slice(1), [validatorsList[0]], {from: operator});
+            assert.deepEqual(Number(await token.balanceOf(validatorsList[0], {from: operator})), 90);
+            let stakeSupply = (await token.dumpEconomicMetrics({from: operator})).stakesupply;
+            assert.deepEqual(Number(stakeSupply), stakes.reduce((a,b) => a + b) - 10);
+        });
+
+        it('test signing a block resets the missed blocks', async function () {
+            await token.setInactivityPenalty(2, 1000, {from: operator});
+
+            await token.finalize(0, validatorsList.slice(1), [validatorsList[0]], {from: operator});
+            await token.finalize(0, validatorsList, [], {from: operator});
+            await token.finalize(0, validatorsList.slice(1), [validatorsList[0]], {from: operator});
+            assert.deepEqual(Number(await token.balanceOf(validatorsList[0], {from: operator})), 100);
+        });
+
+        it('test set inactivity penalty by operator account', async function () {
+            try {
+                let r = await token.setInactivityPenalty(0, 1000, {from: operator});
+                assert.fail('Expected throw not received', r);
+            } catch (e) {
+                assert.deepEqual(Number(await token.inactivityThreshold()), 100);
+            }
+
+            try {
+                let r = await token.setInactivityPenalty(10, 1000, {from: accounts[1]});
+                assert.fail('Expected throw not received', r);
+            } catch (e) {
+                assert.deepEqual(Number(await token.inactivityPenaltyRate()), 10);
+            }
+        });
+    });
+
     describe('Proposer selection, Normal case.', function() {
 
         beforeEach(async function(){
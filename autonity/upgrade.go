@@ -0,0 +1,142 @@
+package autonity
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/clearmatics/autonity/accounts/abi"
+	"github.com/clearmatics/autonity/core/state"
+	"github.com/clearmatics/autonity/core/types"
+)
+
+// ErrNoPendingUpgrade is returned when no new contract has been set by the operator.
+var ErrNoPendingUpgrade = errors.New("no pending autonity contract upgrade")
+
+// RequiredFunctions are the autonity contract functions the protocol calls,
+// a new contract which doesn't expose all of them would halt the chain.
+var RequiredFunctions = []string{
+	"finalize",
+	"getCommittee",
+	"getProposer",
+	"getWhitelist",
+	"getMinimumGasPrice",
+	"dumpEconomicMetrics",
+}
+
+// StateChange is a value returned by getState which differs between the
+// current contract and the upgraded one, it is null if missing on either side.
+type StateChange struct {
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+// UpgradeReport is the outcome of a dry-run of the pending contract upgrade.
+type UpgradeReport struct {
+	// Success is true if the state has been migrated to the new contract.
+	Success bool `json:"success"`
+	// Error is the reason why the migration or the decoding of the new state failed.
+	Error   string `json:"error,omitempty"`
+	GasUsed uint64 `json:"gasUsed"`
+	// StateDiff maps the getState outputs which have changed to their values.
+	StateDiff map[string]StateChange `json:"stateDiff"`
+	// MissingFunctions are the required functions absent from the new ABI.
+	MissingFunctions []string `json:"missingFunctions"`
+}
+
+// DryRunUpgrade runs the upgrade to the contract set by the operator against a
+// copy of the state of the header's block, the given state is not modified.
+func (ac *Contract) DryRunUpgrade(header *types.Header, statedb *state.StateDB) (*UpgradeReport, error) {
+	statedb = statedb.Copy()
+	currentABI := ac.ABIAt(header.Number.Uint64())
+
+	var bytecode, newJSONABI string
+	if err := ac.AutonityContractCall(statedb, header, "getNewContract", &[]interface{}{&bytecode, &newJSONABI}); err != nil {
+		return nil, err
+	}
+	if len(bytecode) == 0 {
+		return nil, ErrNoPendingUpgrade
+	}
+
+	var stateBefore raw
+	if err := ac.AutonityContractCall(statedb, header, "getState", &stateBefore); err != nil {
+		return nil, err
+	}
+
+	report := &UpgradeReport{MissingFunctions: []string{}}
+	newABI, err := abi.JSON(strings.NewReader(newJSONABI))
+	if err != nil {
+		report.Error = "invalid ABI: " + err.Error()
+		report.MissingFunctions = RequiredFunctions
+		return report, nil
+	}
+	for _, function := range RequiredFunctions {
+		if _, ok := newABI.Methods[function]; !ok {
+			report.MissingFunctions = append(report.MissingFunctions, function)
+		}
+	}
+
+	statedb.CreateAccount(ContractAddress)
+	report.GasUsed, err = ac.updateAutonityContract(header, statedb, bytecode, stateBefore)
+	if err != nil {
+		report.Error = err.Error()
+		return report, nil
+	}
+	report.Success = true
+
+	before, err := unpackState(currentABI, stateBefore)
+	if err != nil {
+		report.Error = "cannot decode the current state: " + err.Error()
+		return report, nil
+	}
+	var stateAfter raw
+	if err := ac.contractCall(&newABI, statedb, header, "getState", &stateAfter); err != nil {
+		report.Error = "cannot retrieve the new state: " + err.Error()
+		return report, nil
+	}
+	after, err := unpackState(&newABI, stateAfter)
+	if err != nil {
+		report.Error = "cannot decode the new state: " + err.Error()
+		return report, nil
+	}
+	report.StateDiff = diffState(before, after)
+	return report, nil
+}
+
+// unpackState decodes the output of getState into its json encoded values
+// indexed by name.
+func unpackState(contractABI *abi.ABI, packed []byte) (map[string]json.RawMessage, error) {
+	method, ok := contractABI.Methods["getState"]
+	if !ok {
+		return nil, errors.New("getState is missing from the ABI")
+	}
+	values, err := method.Outputs.Unpack(packed)
+	if err != nil {
+		return nil, err
+	}
+	state := make(map[string]json.RawMessage, len(values))
+	for i, value := range values {
+		enc, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		state[method.Outputs[i].Name] = enc
+	}
+	return state, nil
+}
+
+func diffState(before, after map[string]json.RawMessage) map[string]StateChange {
+	diff := make(map[string]StateChange)
+	for name, value := range before {
+		if !bytes.Equal(value, after[name]) {
+			diff[name] = StateChange{Before: value, After: after[name]}
+		}
+	}
+	for name, value := range after {
+		if _, ok := before[name]; !ok {
+			diff[name] = StateChange{After: value}
+		}
+	}
+	return diff
+}
@@ -0,0 +1,56 @@
+package autonity
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/clearmatics/autonity/accounts/abi"
+	"github.com/clearmatics/autonity/common"
+)
+
+const (
+	testStateABIV1 = `[{"inputs":[],"name":"getState","outputs":[{"name":"_addr","type":"address[]"},{"name":"_minGasPrice","type":"uint256"}],"stateMutability":"view","type":"function"}]`
+	testStateABIV2 = `[{"inputs":[],"name":"getState","outputs":[{"name":"_addr","type":"address[]"},{"name":"_minGasPrice","type":"uint256"},{"name":"_version","type":"string"}],"stateMutability":"view","type":"function"}]`
+)
+
+func TestUpgradeStateDiff(t *testing.T) {
+	pack := func(jsonABI string, values ...interface{}) ([]byte, *abi.ABI) {
+		contractABI, err := abi.JSON(strings.NewReader(jsonABI))
+		if err != nil {
+			t.Fatal(err)
+		}
+		packed, err := contractABI.Methods["getState"].Outputs.Pack(values...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return packed, &contractABI
+	}
+	users := []common.Address{common.HexToAddress("0x1")}
+
+	packedBefore, abiV1 := pack(testStateABIV1, users, big.NewInt(5))
+	before, err := unpackState(abiV1, packedBefore)
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	packedAfter, abiV2 := pack(testStateABIV2, users, big.NewInt(10), "v1.0.0")
+	after, err := unpackState(abiV2, packedAfter)
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+
+	diff := diffState(before, after)
+	if len(diff) != 2 {
+		t.Fatalf("Expected 2 changes, got %v", diff)
+	}
+	if change := diff["_minGasPrice"]; string(change.Before) != "5" || string(change.After) != "10" {
+		t.Fatalf("Expected 5 -> 10, got %s -> %s", change.Before, change.After)
+	}
+	if change := diff["_version"]; change.Before != nil || string(change.After) != `"v1.0.0"` {
+		t.Fatalf("Expected new value v1.0.0, got %s -> %s", change.Before, change.After)
+	}
+
+	if _, err := unpackState(&abi.ABI{}, packedBefore); err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+}
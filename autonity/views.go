@@ -0,0 +1,83 @@
+package autonity
+
+import (
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/core/state"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/crypto"
+	"github.com/clearmatics/autonity/metrics"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// maxCachedViews bounds the number of view results cached, the same calls are
+// made over and over at a block, e.g. getMinimumGasPrice for every transaction
+// entering the pool.
+const maxCachedViews = 256
+
+var (
+	viewHitMeter      = metrics.NewRegisteredMeter("autonity/views/hit", nil)
+	viewMissMeter     = metrics.NewRegisteredMeter("autonity/views/miss", nil)
+	viewHitRatioGauge = metrics.NewRegisteredGaugeFloat64("autonity/views/hitratio", nil)
+)
+
+// viewKey returns the key of a view call, the state root and the block
+// determine its result. It returns false if the state has been modified since
+// its root was computed, such calls aren't cached.
+func viewKey(statedb *state.StateDB, header *types.Header, packedArgs []byte) (common.Hash, bool) {
+	root, ok := statedb.CleanRoot()
+	if !ok {
+		return common.Hash{}, false
+	}
+	return crypto.Keccak256Hash(root.Bytes(), header.Hash().Bytes(), packedArgs), true
+}
+
+func newViewCache() *lru.Cache {
+	views, _ := lru.New(maxCachedViews)
+	return views
+}
+
+// viewCall calls a view function of the contract with packedArgs, the packed
+// result is cached until the next block is finalized or the contract is
+// upgraded.
+func (ac *Contract) viewCall(statedb *state.StateDB, header *types.Header, function string, packedArgs []byte) ([]byte, error) {
+	if ac.views == nil {
+		return ac.CallContractFunc(statedb, header, function, packedArgs)
+	}
+	key, ok := viewKey(statedb, header, packedArgs)
+	if ok {
+		if ret, hit := ac.views.Get(key); hit {
+			markView(viewHitMeter)
+			return ret.([]byte), nil
+		}
+	}
+	markView(viewMissMeter)
+	ret, err := ac.CallContractFunc(statedb, header, function, packedArgs)
+	if err != nil {
+		return nil, err
+	}
+	// the results of the calls which modified the state aren't cached, the
+	// modifications would be skipped on a hit. Neither are the results of the
+	// calls which failed to read the state, e.g. a missing trie node on a light
+	// client, the next call might succeed.
+	if statedb.Error() != nil {
+		return ret, nil
+	}
+	if after, clean := viewKey(statedb, header, packedArgs); ok && clean && after == key {
+		ac.views.Add(key, ret)
+	}
+	return ret, nil
+}
+
+func markView(meter metrics.Meter) {
+	meter.Mark(1)
+	if total := viewHitMeter.Count() + viewMissMeter.Count(); total > 0 {
+		viewHitRatioGauge.Update(float64(viewHitMeter.Count()) / float64(total))
+	}
+}
+
+// purgeViews drops the cached view results.
+func (ac *Contract) purgeViews() {
+	if ac.views != nil {
+		ac.views.Purge()
+	}
+}
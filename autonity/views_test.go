@@ -0,0 +1,183 @@
+package autonity
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/core/rawdb"
+	"github.com/clearmatics/autonity/core/state"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/core/vm"
+	"github.com/clearmatics/autonity/crypto"
+	"github.com/clearmatics/autonity/params"
+)
+
+const testViewABI = `[{"inputs":[],"name":"getMinimumGasPrice","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"}]`
+
+var (
+	// mstore(0, 7), return(0, 32)
+	returnCode = []byte{0x60, 0x07, 0x60, 0x00, 0x52, 0x60, 0x20, 0x60, 0x00, 0xf3}
+	// sstore(0, 1), mstore(0, 7), return(0, 32)
+	storeCode = append([]byte{0x60, 0x01, 0x60, 0x00, 0x55}, returnCode...)
+)
+
+// countingEVMProvider counts the evms provided, one per contract call.
+type countingEVMProvider struct {
+	calls int
+}
+
+func (p *countingEVMProvider) EVM(header *types.Header, origin common.Address, statedb *state.StateDB) *vm.EVM {
+	p.calls++
+	ctx := vm.Context{
+		CanTransfer: func(vm.StateDB, common.Address, *big.Int) bool { return true },
+		Transfer:    func(vm.StateDB, common.Address, common.Address, *big.Int) {},
+		Origin:      origin,
+		BlockNumber: header.Number,
+		Time:        new(big.Int),
+		Difficulty:  new(big.Int),
+		GasPrice:    new(big.Int),
+	}
+	return vm.NewEVM(ctx, statedb, params.TestChainConfig, vm.Config{})
+}
+
+func newViewState(t *testing.T, code []byte) *state.StateDB {
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	statedb.SetCode(ContractAddress, code)
+	statedb.IntermediateRoot(true)
+	return statedb
+}
+
+func TestContract_ViewCache(t *testing.T) {
+	newContract := func(t *testing.T) (*Contract, *countingEVMProvider) {
+		provider := &countingEVMProvider{}
+		ac, err := NewAutonityContract(&testBlockchainer{}, common.Address{}, 0, testViewABI, provider)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ac, provider
+	}
+	header := &types.Header{Number: big.NewInt(1)}
+
+	t.Run("views are called once per block", func(t *testing.T) {
+		ac, provider := newContract(t)
+		statedb := newViewState(t, returnCode)
+		for i := 0; i < 3; i++ {
+			price, err := ac.callGetMinimumGasPrice(statedb, header)
+			if err != nil {
+				t.Fatalf("Expected <nil>, got %v", err)
+			}
+			if price != 7 {
+				t.Fatalf("Expected 7, got %d", price)
+			}
+		}
+		if provider.calls != 1 {
+			t.Fatalf("Expected 1 call, got %d", provider.calls)
+		}
+
+		if _, err := ac.callGetMinimumGasPrice(statedb, &types.Header{Number: big.NewInt(2)}); err != nil {
+			t.Fatalf("Expected <nil>, got %v", err)
+		}
+		if provider.calls != 2 {
+			t.Fatalf("Expected another call for a new block, got %d calls", provider.calls)
+		}
+	})
+
+	t.Run("views of a modified state are not cached", func(t *testing.T) {
+		ac, provider := newContract(t)
+		statedb := newViewState(t, returnCode)
+		statedb.AddBalance(common.Address{1}, big.NewInt(1))
+		for i := 0; i < 2; i++ {
+			if _, err := ac.callGetMinimumGasPrice(statedb, header); err != nil {
+				t.Fatalf("Expected <nil>, got %v", err)
+			}
+		}
+		if provider.calls != 2 {
+			t.Fatalf("Expected 2 calls, got %d", provider.calls)
+		}
+	})
+
+	t.Run("calls modifying the state are not cached", func(t *testing.T) {
+		ac, provider := newContract(t)
+		for i := 0; i < 2; i++ {
+			if _, err := ac.callGetMinimumGasPrice(newViewState(t, storeCode), header); err != nil {
+				t.Fatalf("Expected <nil>, got %v", err)
+			}
+		}
+		if provider.calls != 2 {
+			t.Fatalf("Expected 2 calls, got %d", provider.calls)
+		}
+	})
+
+	t.Run("views failing to read the state are not cached", func(t *testing.T) {
+		ac, provider := newContract(t)
+		db := rawdb.NewMemoryDatabase()
+		statedb, err := state.New(common.Hash{}, state.NewDatabase(db), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		statedb.SetCode(ContractAddress, returnCode)
+		statedb.AddBalance(common.Address{1}, big.NewInt(1))
+		root, err := statedb.Commit(true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := statedb.Database().TrieDB().Commit(root, false, nil); err != nil {
+			t.Fatal(err)
+		}
+		proof, err := statedb.GetProof(ContractAddress)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// drop the contract account from the database as a light client
+		// failing to retrieve it would.
+		leaf := proof[len(proof)-1]
+		rawdb.DeleteTrieNode(db, crypto.Keccak256Hash(leaf))
+		missing, err := state.New(root, state.NewDatabase(db), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ac.callGetMinimumGasPrice(missing, header); err == nil {
+			t.Fatalf("Expected an error, got <nil>")
+		}
+		if missing.Error() == nil {
+			t.Fatalf("Expected a state error, got <nil>")
+		}
+
+		rawdb.WriteTrieNode(db, crypto.Keccak256Hash(leaf), leaf)
+		complete, err := state.New(root, state.NewDatabase(db), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		price, err := ac.callGetMinimumGasPrice(complete, header)
+		if err != nil {
+			t.Fatalf("Expected <nil>, got %v", err)
+		}
+		if price != 7 {
+			t.Fatalf("Expected 7, got %d", price)
+		}
+		if provider.calls != 2 {
+			t.Fatalf("Expected 2 calls, got %d", provider.calls)
+		}
+	})
+
+	t.Run("views are purged on upgrade", func(t *testing.T) {
+		ac, provider := newContract(t)
+		statedb := newViewState(t, returnCode)
+		if _, err := ac.callGetMinimumGasPrice(statedb, header); err != nil {
+			t.Fatalf("Expected <nil>, got %v", err)
+		}
+		if err := ac.upgradeAbiCache(testViewABI, 1); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ac.callGetMinimumGasPrice(statedb, header); err != nil {
+			t.Fatalf("Expected <nil>, got %v", err)
+		}
+		if provider.calls != 2 {
+			t.Fatalf("Expected 2 calls, got %d", provider.calls)
+		}
+	})
+}
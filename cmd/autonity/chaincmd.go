@@ -184,6 +184,24 @@ Use "ethereum dump 0" to dump the genesis block.`,
 		},
 		Category: "BLOCKCHAIN COMMANDS",
 	}
+	dryRunUpgradeCommand = cli.Command{
+		Action:    utils.MigrateFlags(dryRunUpgrade),
+		Name:      "dryrun-upgrade",
+		Usage:     "Dry-run the pending Autonity contract upgrade against the latest state",
+		ArgsUsage: " ",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.AncientFlag,
+			utils.CacheFlag,
+			utils.SyncModeFlag,
+		},
+		Category: "BLOCKCHAIN COMMANDS",
+		Description: `
+The bytecode and ABI set by the operator are used to migrate the Autonity
+contract state on a copy of the latest state, the database is left untouched.
+The report lists the gas used, the changes of the contract state and the
+functions required by the protocol which are missing from the new ABI.`,
+	}
 )
 
 // initGenesis will initialise the given JSON format genesis file and writes it as
@@ -220,6 +238,13 @@ func initGenesis(ctx *cli.Context) error {
 		return fmt.Errorf("autonity contract section is invalid. error:%v", err.Error())
 	}
 
+	// timeouts left unset in the genesis fall back to the defaults.
+	timeouts := new(config.Config)
+	timeouts.ApplyChainConfig(genesis.Config.Tendermint)
+	if err := timeouts.ValidateTimeouts(); err != nil {
+		return fmt.Errorf("tendermint section is invalid. error:%v", err)
+	}
+
 	setupDefaults(genesis)
 
 	// Open an initialise both full and light databases
@@ -592,6 +617,34 @@ func inspect(ctx *cli.Context) error {
 	return rawdb.InspectDatabase(chainDb)
 }
 
+func dryRunUpgrade(ctx *cli.Context) error {
+	node, _ := makeConfigNode(ctx)
+	defer node.Close()
+
+	chain, chainDb := utils.MakeChain(ctx, node, true)
+	defer chainDb.Close()
+
+	contract := chain.GetAutonityContract()
+	if contract == nil {
+		utils.Fatalf("The chain doesn't run the Autonity contract")
+	}
+	header := chain.CurrentHeader()
+	statedb, err := chain.StateAt(header.Root)
+	if err != nil {
+		utils.Fatalf("Could not load the latest state: %v", err)
+	}
+	report, err := contract.DryRunUpgrade(header, statedb)
+	if err != nil {
+		utils.Fatalf("Dry-run failed: %v", err)
+	}
+	out, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		utils.Fatalf("Could not encode the report: %v", err)
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
 // hashish returns true for strings that look like hashes.
 func hashish(x string) bool {
 	_, err := strconv.Atoi(x)
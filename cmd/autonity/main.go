@@ -132,6 +132,20 @@ var (
 		utils.LegacyMinerExtraDataFlag,
 		utils.MinerRecommitIntervalFlag,
 		utils.MinerNoVerfiyFlag,
+		utils.TendermintProposeTimeoutFlag,
+		utils.TendermintProposeTimeoutDeltaFlag,
+		utils.TendermintPrevoteTimeoutFlag,
+		utils.TendermintPrevoteTimeoutDeltaFlag,
+		utils.TendermintPrecommitTimeoutFlag,
+		utils.TendermintPrecommitTimeoutDeltaFlag,
+		utils.TendermintExponentialTimeoutsFlag,
+		utils.TendermintAdaptiveTimeoutsFlag,
+		utils.TendermintMinAdaptiveTimeoutFlag,
+		utils.TendermintMaxAdaptiveTimeoutFlag,
+		utils.TendermintExternalSignerFlag,
+		utils.TendermintConsensusKeysFlag,
+		utils.TendermintRelayFlag,
+		utils.TendermintCompactProposalsFlag,
 		utils.NATFlag,
 		utils.NoDiscoverFlag,
 		utils.DiscoveryV5Flag,
@@ -216,6 +230,7 @@ func init() {
 		removedbCommand,
 		dumpCommand,
 		inspectCommand,
+		dryRunUpgradeCommand,
 		// See accountcmd.go:
 		accountCommand,
 		walletCommand,
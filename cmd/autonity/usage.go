@@ -180,6 +180,25 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.MinerNoVerfiyFlag,
 		},
 	},
+	{
+		Name: "TENDERMINT",
+		Flags: []cli.Flag{
+			utils.TendermintProposeTimeoutFlag,
+			utils.TendermintProposeTimeoutDeltaFlag,
+			utils.TendermintPrevoteTimeoutFlag,
+			utils.TendermintPrevoteTimeoutDeltaFlag,
+			utils.TendermintPrecommitTimeoutFlag,
+			utils.TendermintPrecommitTimeoutDeltaFlag,
+			utils.TendermintExponentialTimeoutsFlag,
+			utils.TendermintAdaptiveTimeoutsFlag,
+			utils.TendermintMinAdaptiveTimeoutFlag,
+			utils.TendermintMaxAdaptiveTimeoutFlag,
+			utils.TendermintExternalSignerFlag,
+			utils.TendermintConsensusKeysFlag,
+			utils.TendermintRelayFlag,
+			utils.TendermintCompactProposalsFlag,
+		},
+	},
 	{
 		Name: "GAS PRICE ORACLE",
 		Flags: []cli.Flag{
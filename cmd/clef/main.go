@@ -129,6 +129,14 @@ var (
 		Name:  "rules",
 		Usage: "Path to the rule file to auto-authorize requests with",
 	}
+	consensusKeyFlag = cli.StringFlag{
+		Name:  "consensus.key",
+		Usage: "File holding the node key used to sign the consensus messages of an autonity validator, served over IPC only",
+	}
+	consensusMarkFlag = cli.StringFlag{
+		Name:  "consensus.mark",
+		Usage: "File holding the high-water mark of the consensus messages signed (default = <configdir>/consensus-mark.json)",
+	}
 	stdiouiFlag = cli.BoolFlag{
 		Name: "stdio-ui",
 		Usage: "Use STDIN/STDOUT as a channel for an external UI. " +
@@ -278,6 +286,8 @@ func init() {
 		customDBFlag,
 		auditLogFlag,
 		ruleFlag,
+		consensusKeyFlag,
+		consensusMarkFlag,
 		stdiouiFlag,
 		testFlag,
 		advancedMode,
@@ -663,12 +673,39 @@ func signer(c *cli.Context) error {
 			Service:   api,
 			Version:   "1.0"},
 	}
+	whitelist := []string{"account"}
+	ipcAPI := rpcAPI
+	if keyFile := c.GlobalString(consensusKeyFlag.Name); keyFile != "" {
+		key, err := crypto.LoadECDSA(keyFile)
+		if err != nil {
+			utils.Fatalf("Could not load the consensus key: %v", err)
+		}
+		markFile := c.GlobalString(consensusMarkFlag.Name)
+		if markFile == "" {
+			markFile = filepath.Join(configDir, "consensus-mark.json")
+		}
+		consensusAPI, err := core.NewConsensusSignerAPI(key, markFile)
+		if err != nil {
+			utils.Fatalf("Could not start the consensus signer: %v", err)
+		}
+		// the consensus data is signed without approval, only the local node
+		// is allowed to ask for it.
+		if c.GlobalBool(utils.IPCDisabledFlag.Name) {
+			utils.Fatalf("The consensus signer is only served over IPC, it can't be disabled")
+		}
+		log.Info("Consensus signer configured", "address", consensusAPI.Address(), "mark", markFile)
+		ipcAPI = append(ipcAPI, rpc.API{
+			Namespace: "consensus",
+			Public:    true,
+			Service:   consensusAPI,
+			Version:   "1.0"})
+	}
 	if c.GlobalBool(utils.HTTPEnabledFlag.Name) {
 		vhosts := utils.SplitAndTrim(c.GlobalString(utils.HTTPVirtualHostsFlag.Name))
 		cors := utils.SplitAndTrim(c.GlobalString(utils.HTTPCORSDomainFlag.Name))
 
 		srv := rpc.NewServer()
-		err := node.RegisterApisFromWhitelist(rpcAPI, []string{"account"}, srv, false)
+		err := node.RegisterApisFromWhitelist(rpcAPI, whitelist, srv, false)
 		if err != nil {
 			utils.Fatalf("Could not register API: %w", err)
 		}
@@ -701,7 +738,7 @@ func signer(c *cli.Context) error {
 	if !c.GlobalBool(utils.IPCDisabledFlag.Name) {
 		givenPath := c.GlobalString(utils.IPCPathFlag.Name)
 		ipcapiURL = ipcEndpoint(filepath.Join(givenPath, "clef.ipc"), configDir)
-		listener, _, err := rpc.StartIPCEndpoint(ipcapiURL, rpcAPI)
+		listener, _, err := rpc.StartIPCEndpoint(ipcapiURL, ipcAPI)
 		if err != nil {
 			utils.Fatalf("Could not start IPC api: %v", err)
 		}
@@ -39,6 +39,7 @@ import (
 	"github.com/clearmatics/autonity/common/fdlimit"
 	"github.com/clearmatics/autonity/consensus"
 	"github.com/clearmatics/autonity/consensus/ethash"
+	tendermintConfig "github.com/clearmatics/autonity/consensus/tendermint/config"
 	"github.com/clearmatics/autonity/core"
 	"github.com/clearmatics/autonity/core/vm"
 	"github.com/clearmatics/autonity/crypto"
@@ -421,6 +422,65 @@ var (
 		Name:  "miner.noverify",
 		Usage: "Disable remote sealing verification",
 	}
+	// Tendermint settings
+	TendermintProposeTimeoutFlag = cli.DurationFlag{
+		Name:  "tendermint.timeout.propose",
+		Usage: "Timeout of the propose step at round 0 (default = genesis value or 2s)",
+	}
+	TendermintProposeTimeoutDeltaFlag = cli.DurationFlag{
+		Name:  "tendermint.timeout.propose.delta",
+		Usage: "Increase of the propose timeout at each round (default = genesis value or 500ms)",
+	}
+	TendermintPrevoteTimeoutFlag = cli.DurationFlag{
+		Name:  "tendermint.timeout.prevote",
+		Usage: "Timeout of the prevote step at round 0 (default = genesis value or 1s)",
+	}
+	TendermintPrevoteTimeoutDeltaFlag = cli.DurationFlag{
+		Name:  "tendermint.timeout.prevote.delta",
+		Usage: "Increase of the prevote timeout at each round (default = genesis value or 500ms)",
+	}
+	TendermintPrecommitTimeoutFlag = cli.DurationFlag{
+		Name:  "tendermint.timeout.precommit",
+		Usage: "Timeout of the precommit step at round 0 (default = genesis value or 1s)",
+	}
+	TendermintPrecommitTimeoutDeltaFlag = cli.DurationFlag{
+		Name:  "tendermint.timeout.precommit.delta",
+		Usage: "Increase of the precommit timeout at each round (default = genesis value or 500ms)",
+	}
+	TendermintExponentialTimeoutsFlag = cli.BoolFlag{
+		Name:  "tendermint.timeout.exponential",
+		Usage: "Double the timeout increases at each round instead of adding them linearly",
+	}
+	TendermintAdaptiveTimeoutsFlag = cli.BoolFlag{
+		Name:  "tendermint.timeout.adaptive",
+		Usage: "Adjust the timeouts at round 0 to the step durations observed at the previous height",
+	}
+	TendermintMinAdaptiveTimeoutFlag = cli.DurationFlag{
+		Name:  "tendermint.timeout.adaptive.min",
+		Usage: "Lower bound of the adaptive timeouts (default = genesis value or 200ms)",
+	}
+	TendermintMaxAdaptiveTimeoutFlag = cli.DurationFlag{
+		Name:  "tendermint.timeout.adaptive.max",
+		Usage: "Upper bound of the adaptive timeouts (default = genesis value or 10s)",
+	}
+	TendermintExternalSignerFlag = cli.StringFlag{
+		Name:  "tendermint.signer",
+		Usage: "Path to the ipc file of the external signer holding the consensus keys",
+		Value: "",
+	}
+	TendermintConsensusKeysFlag = cli.StringFlag{
+		Name:  "tendermint.consensuskeys",
+		Usage: "Comma separated files of the consensus keys, list the new key alongside the current one to rotate it (default = node key)",
+		Value: "",
+	}
+	TendermintRelayFlag = cli.BoolFlag{
+		Name:  "tendermint.relay",
+		Usage: "Relay the consensus messages to every peer, so that validators not directly connected (e.g. behind sentry nodes) reach each other",
+	}
+	TendermintCompactProposalsFlag = cli.BoolFlag{
+		Name:  "tendermint.compactproposals",
+		Usage: "Propose the blocks with the short hashes of their transactions, the validators rebuild them from their transaction pool",
+	}
 	// Account settings
 	UnlockedAccountFlag = cli.StringFlag{
 		Name:  "unlock",
@@ -1243,6 +1303,52 @@ func setEthash(ctx *cli.Context, cfg *eth.Config) {
 	}
 }
 
+func setTendermint(ctx *cli.Context, cfg *tendermintConfig.Config) {
+	for _, t := range []struct {
+		flag  cli.DurationFlag
+		value **uint64
+	}{
+		{TendermintProposeTimeoutFlag, &cfg.ProposeTimeout},
+		{TendermintProposeTimeoutDeltaFlag, &cfg.ProposeTimeoutDelta},
+		{TendermintPrevoteTimeoutFlag, &cfg.PrevoteTimeout},
+		{TendermintPrevoteTimeoutDeltaFlag, &cfg.PrevoteTimeoutDelta},
+		{TendermintPrecommitTimeoutFlag, &cfg.PrecommitTimeout},
+		{TendermintPrecommitTimeoutDeltaFlag, &cfg.PrecommitTimeoutDelta},
+		{TendermintMinAdaptiveTimeoutFlag, &cfg.MinAdaptiveTimeout},
+		{TendermintMaxAdaptiveTimeoutFlag, &cfg.MaxAdaptiveTimeout},
+	} {
+		if !ctx.GlobalIsSet(t.flag.Name) {
+			continue
+		}
+		d := ctx.GlobalDuration(t.flag.Name)
+		if d < time.Millisecond || d > tendermintConfig.MaxTimeout*time.Millisecond {
+			Fatalf("--%s must be between 1ms and %v", t.flag.Name, tendermintConfig.MaxTimeout*time.Millisecond)
+		}
+		ms := uint64(d.Milliseconds())
+		*t.value = &ms
+	}
+	if ctx.GlobalIsSet(TendermintExponentialTimeoutsFlag.Name) {
+		exponential := ctx.GlobalBool(TendermintExponentialTimeoutsFlag.Name)
+		cfg.ExponentialTimeouts = &exponential
+	}
+	if ctx.GlobalIsSet(TendermintAdaptiveTimeoutsFlag.Name) {
+		adaptive := ctx.GlobalBool(TendermintAdaptiveTimeoutsFlag.Name)
+		cfg.AdaptiveTimeouts = &adaptive
+	}
+	if ctx.GlobalIsSet(TendermintExternalSignerFlag.Name) {
+		cfg.ExternalSigner = ctx.GlobalString(TendermintExternalSignerFlag.Name)
+	}
+	if ctx.GlobalIsSet(TendermintConsensusKeysFlag.Name) {
+		cfg.ConsensusKeyFiles = SplitAndTrim(ctx.GlobalString(TendermintConsensusKeysFlag.Name))
+	}
+	if ctx.GlobalIsSet(TendermintRelayFlag.Name) {
+		cfg.Relay = ctx.GlobalBool(TendermintRelayFlag.Name)
+	}
+	if ctx.GlobalIsSet(TendermintCompactProposalsFlag.Name) {
+		cfg.CompactProposals = ctx.GlobalBool(TendermintCompactProposalsFlag.Name)
+	}
+}
+
 func setMiner(ctx *cli.Context, cfg *miner.Config) {
 	if ctx.GlobalIsSet(MinerNotifyFlag.Name) {
 		cfg.Notify = strings.Split(ctx.GlobalString(MinerNotifyFlag.Name), ",")
@@ -1371,6 +1477,7 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 	setTxPool(ctx, &cfg.TxPool)
 	setEthash(ctx, cfg)
 	setMiner(ctx, &cfg.Miner)
+	setTendermint(ctx, &cfg.Tendermint)
 	setWhitelist(ctx, cfg)
 	setLes(ctx, cfg)
 
@@ -159,8 +159,8 @@ type BFT interface {
 	Start(ctx context.Context) error
 }
 
+// Syncer is implemented by the engines keeping a per peer cache of the
+// consensus messages, the peers ask for the messages they miss on their own.
 type Syncer interface {
-	SyncPeer(address common.Address)
-
 	ResetPeerCache(address common.Address)
 }
@@ -28,6 +28,10 @@ type Broadcaster interface {
 	Enqueue(id string, block *types.Block)
 	// FindPeers retrives connected peers by addresses
 	FindPeers(map[common.Address]struct{}) map[common.Address]Peer
+	// Peers retrieves all the connected peers by addresses
+	Peers() map[common.Address]Peer
+	// DisconnectPeer drops the connection to the peer with the given address
+	DisconnectPeer(common.Address)
 }
 
 // Peer defines the interface to communicate with peer
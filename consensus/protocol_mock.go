@@ -60,6 +60,32 @@ func (mr *MockBroadcasterMockRecorder) FindPeers(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPeers", reflect.TypeOf((*MockBroadcaster)(nil).FindPeers), arg0)
 }
 
+// Peers mocks base method
+func (m *MockBroadcaster) Peers() map[common.Address]Peer {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Peers")
+	ret0, _ := ret[0].(map[common.Address]Peer)
+	return ret0
+}
+
+// Peers indicates an expected call of Peers
+func (mr *MockBroadcasterMockRecorder) Peers() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Peers", reflect.TypeOf((*MockBroadcaster)(nil).Peers))
+}
+
+// DisconnectPeer mocks base method
+func (m *MockBroadcaster) DisconnectPeer(arg0 common.Address) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DisconnectPeer", arg0)
+}
+
+// DisconnectPeer indicates an expected call of DisconnectPeer
+func (mr *MockBroadcasterMockRecorder) DisconnectPeer(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisconnectPeer", reflect.TypeOf((*MockBroadcaster)(nil).DisconnectPeer), arg0)
+}
+
 // MockPeer is a mock of Peer interface
 type MockPeer struct {
 	ctrl     *gomock.Controller
@@ -19,6 +19,7 @@ package backend
 import (
 	"github.com/clearmatics/autonity/autonity"
 	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/common/hexutil"
 	"github.com/clearmatics/autonity/consensus"
 	"github.com/clearmatics/autonity/consensus/tendermint/core"
 	"github.com/clearmatics/autonity/core/types"
@@ -77,3 +78,34 @@ func (api *API) GetWhitelist() []string {
 func (api *API) GetCoreState() core.TendermintState {
 	return api.tendermint.CoreState()
 }
+
+// Get the evidence of misbehaving committee members recorded by this node
+func (api *API) GetEvidence() []*core.Evidence {
+	return core.ReadAllEvidence(api.tendermint.db)
+}
+
+// Get the BLS consensus key of this node, to be registered in the autonity contract
+func (api *API) GetConsensusKey() hexutil.Bytes {
+	return api.tendermint.ConsensusKey()
+}
+
+// Get the BLS consensus keys of this node by address, a new key is registered in
+// the autonity contract along with its address to be rotated in
+func (api *API) GetConsensusKeys() map[common.Address]hexutil.Bytes {
+	keys := make(map[common.Address]hexutil.Bytes)
+	for address, key := range api.tendermint.ConsensusKeys() {
+		keys[address] = key
+	}
+	return keys
+}
+
+// AdminAPI is the private RPC API of the node operator, registered in the
+// admin namespace.
+type AdminAPI struct {
+	tendermint *Backend
+}
+
+// PeerScores returns the scores of the peers which sent consensus messages.
+func (api *AdminAPI) PeerScores() map[common.Address]PeerScore {
+	return api.tendermint.PeerScores()
+}
@@ -23,6 +23,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/clearmatics/autonity/accounts"
 	"github.com/clearmatics/autonity/common"
 	"github.com/clearmatics/autonity/consensus"
 	"github.com/clearmatics/autonity/consensus/tendermint/bft"
@@ -32,7 +33,6 @@ import (
 	"github.com/clearmatics/autonity/core"
 	"github.com/clearmatics/autonity/core/types"
 	"github.com/clearmatics/autonity/core/vm"
-	"github.com/clearmatics/autonity/crypto"
 	"github.com/clearmatics/autonity/ethdb"
 	"github.com/clearmatics/autonity/event"
 	"github.com/clearmatics/autonity/log"
@@ -46,6 +46,9 @@ const (
 	fetcherID = "tendermint"
 	// ring buffer to be able to handle at maximum 10 rounds, 20 committee and 3 messages types
 	ringCapacity = 10 * 20 * 3
+	// partFanout is the number of committee members each part of a proposed
+	// block is sent to by the proposer, the members gossip it further.
+	partFanout = 2
 )
 
 var (
@@ -58,15 +61,21 @@ var (
 
 // New creates an Ethereum Backend for BFT core engine.
 func New(config *tendermintConfig.Config, privateKey *ecdsa.PrivateKey, db ethdb.Database, chainConfig *params.ChainConfig, vmConfig *vm.Config) *Backend {
-	if chainConfig.Tendermint.BlockPeriod != 0 {
-		config.BlockPeriod = chainConfig.Tendermint.BlockPeriod
-	}
+	config.ApplyChainConfig(chainConfig.Tendermint)
 
 	recents, _ := lru.NewARC(inmemorySnapshots)
 	recentMessages, _ := lru.NewARC(inmemoryPeers)
 	knownMessages, _ := lru.NewARC(inmemoryMessages)
+	proposalTxs, _ := lru.NewARC(inmemoryProposalTxs)
 
-	pub := crypto.PubkeyToAddress(privateKey.PublicKey).String()
+	signers, err := newConsensusSigners(config, privateKey)
+	if err != nil {
+		// signing with another key than the one configured could lead to
+		// double signing, there is no fallback.
+		log.Crit("Failed to create the consensus signer", "err", err)
+	}
+
+	pub := signers[0].Address().String()
 	logger := log.New("addr", pub)
 
 	logger.Warn("new backend with public key")
@@ -75,28 +84,37 @@ func New(config *tendermintConfig.Config, privateKey *ecdsa.PrivateKey, db ethdb
 		config:         config,
 		eventMux:       event.NewTypeMuxSilent(logger),
 		privateKey:     privateKey,
-		address:        crypto.PubkeyToAddress(privateKey.PublicKey),
+		signers:        signers,
 		logger:         logger,
 		db:             db,
 		recents:        recents,
 		coreStarted:    false,
 		recentMessages: recentMessages,
 		knownMessages:  knownMessages,
+		proposalTxs:    proposalTxs,
+		scores:         newPeerScorer(),
 		vmConfig:       vmConfig,
+		relay:          config.Relay,
 	}
 
 	backend.pendingMessages.SetCapacity(ringCapacity)
-	backend.core = tendermintCore.New(backend, config)
+	backend.core = tendermintCore.New(backend, config, db)
 	return backend
 }
 
+// SetLightMode makes the engine verify the headers the way light clients do,
+// it is set before the engine verifies any header.
+func (sb *Backend) SetLightMode() {
+	sb.light = true
+}
+
 // ----------------------------------------------------------------------------
 
 type Backend struct {
 	config       *tendermintConfig.Config
 	eventMux     *event.TypeMuxSilent
 	privateKey   *ecdsa.PrivateKey
-	address      common.Address
+	signers      []ConsensusSigner
 	logger       log.Logger
 	db           ethdb.Database
 	blockchain   *core.BlockChain
@@ -123,9 +141,22 @@ type Backend struct {
 	//TODO: ARCChace is patented by IBM, so probably need to stop using it
 	recentMessages *lru.ARCCache // the cache of peer's messages
 	knownMessages  *lru.ARCCache // the cache of self messages
+	scores         *peerScorer   // the scores of the peers, see HandleMsg
+
+	// the transactions of the blocks of the compact proposals, see
+	// LookupTransactions.
+	txPool        *core.TxPool
+	proposalTxs   *lru.ARCCache
+	proposalTxsMu sync.Mutex
 
 	contractsMu sync.RWMutex
 	vmConfig    *vm.Config
+
+	// light is set for the engines of light clients, see verifyHeader.
+	light bool
+	// relay is set for the engines forwarding the consensus messages to
+	// every peer, see Gossip.
+	relay bool
 }
 
 func (sb *Backend) BlockChain() *core.BlockChain {
@@ -134,7 +165,10 @@ func (sb *Backend) BlockChain() *core.BlockChain {
 
 // Address implements tendermint.Backend.Address
 func (sb *Backend) Address() common.Address {
-	return sb.address
+	if len(sb.signers) == 0 {
+		return common.Address{}
+	}
+	return sb.consensusSigner().Address()
 }
 
 // Broadcast implements tendermint.Backend.Broadcast
@@ -154,69 +188,144 @@ func (sb *Backend) postEvent(event interface{}) {
 }
 
 func (sb *Backend) AskSync(header *types.Header) {
-	sb.logger.Info("Broadcasting consensus synchronization request")
-
-	targets := make(map[common.Address]struct{})
-	for _, val := range header.Committee {
-		if val.Address != sb.Address() {
-			targets[val.Address] = struct{}{}
-		}
-	}
+	sb.logger.Debug("Broadcasting consensus synchronization request")
 
+	targets := sb.committeeTargets(header.Committee)
 	if sb.broadcaster != nil && len(targets) > 0 {
+		request := tendermintCore.NewSyncRequest(header, sb.core.GetCurrentHeightMessages())
 		ps := sb.broadcaster.FindPeers(targets)
+		powers := make(map[common.Address]uint64, len(header.Committee))
+		for _, member := range header.Committee {
+			powers[nodeAddress(member)] += member.VotingPower.Uint64()
+		}
 		var count uint64
 		for addr, p := range ps {
 			//ask to a quorum nodes to sync, 1 must then be honest and updated
 			if count >= bft.Quorum(header.TotalVotingPower()) {
 				break
 			}
-			sb.logger.Info("Asking sync to", "addr", addr)
-			go p.Send(tendermintSyncMsg, []byte{}) //nolint
+			sb.logger.Debug("Asking sync to", "addr", addr)
+			go p.Send(tendermintSyncRequestMsg, request) //nolint
+			count += powers[addr]
+		}
+	}
+}
 
-			member := header.CommitteeMember(addr)
-			if member == nil {
-				sb.logger.Error("could not retrieve member from address")
-				continue
-			}
-			count += member.VotingPower.Uint64()
+// committeeTargets returns the addresses of the peers the messages to the
+// committee are sent to, we are left out.
+func (sb *Backend) committeeTargets(committee types.Committee) map[common.Address]struct{} {
+	targets := make(map[common.Address]struct{}, len(committee))
+	for _, member := range committee {
+		if member.Address != sb.Address() {
+			targets[nodeAddress(member)] = struct{}{}
 		}
 	}
+	return targets
 }
 
-// Broadcast implements tendermint.Backend.Gossip
+// nodeAddress returns the address of the enode key of the member's node, the
+// peers are known by it. The consensus address of a member differs from it
+// once its key has been rotated, its treasury is then the owner of the enode.
+// The members of older committees have no treasury and sign with the enode key.
+func nodeAddress(member types.CommitteeMember) common.Address {
+	if member.Treasury != (common.Address{}) {
+		return member.Treasury
+	}
+	return member.Address
+}
+
+// Gossip implements tendermint.Backend.Gossip, in relay mode the message is
+// forwarded to the peers outside of the committee as well while it has hops
+// left.
 func (sb *Backend) Gossip(ctx context.Context, committee types.Committee, payload []byte) {
 	hash := types.RLPHash(payload)
-	sb.knownMessages.Add(hash, true)
-
-	targets := make(map[common.Address]struct{})
-	for _, val := range committee {
-		if val.Address != sb.Address() {
-			targets[val.Address] = struct{}{}
+	// the known messages hold the hops left to the messages relayed to us,
+	// our own messages and the ones received directly can be relayed the
+	// furthest.
+	hops := uint64(maxRelayHops)
+	if v, ok := sb.knownMessages.Peek(hash); ok {
+		if h, ok := v.(uint64); ok {
+			hops = h
 		}
 	}
+	sb.knownMessages.Add(hash, hops)
 
-	if sb.broadcaster != nil && len(targets) > 0 {
+	targets := sb.committeeTargets(committee)
+
+	if sb.broadcaster == nil {
+		return
+	}
+	if len(targets) > 0 {
 		ps := sb.broadcaster.FindPeers(targets)
 		for addr, p := range ps {
-			ms, ok := sb.recentMessages.Get(addr)
-			var m *lru.ARCCache
-			if ok {
-				m, _ = ms.(*lru.ARCCache)
-				if _, k := m.Get(hash); k {
-					// This peer had this event, skip it
-					continue
-				}
-			} else {
-				m, _ = lru.NewARC(inmemoryMessages)
+			if sb.markSent(addr, hash) {
+				go p.Send(tendermintMsg, payload) //nolint
 			}
+		}
+	}
+	if !sb.relay || hops == 0 {
+		return
+	}
+	relayed := relayMsg{Payload: payload, TTL: hops - 1}
+	for addr, p := range sb.broadcaster.Peers() {
+		if _, ok := targets[addr]; ok {
+			continue
+		}
+		if sb.markSent(addr, hash) {
+			go p.Send(tendermintRelayMsg, relayed) //nolint
+		}
+	}
+}
+
+// GossipParts implements tendermint.Backend.GossipParts, each part is sent to
+// partFanout members in turn so that the upload of the proposer is spread
+// across the committee. In relay mode the committee may not be reachable
+// directly, the parts are then gossiped as the other messages.
+func (sb *Backend) GossipParts(ctx context.Context, committee types.Committee, payloads [][]byte) {
+	if sb.broadcaster == nil || sb.relay {
+		for _, payload := range payloads {
+			sb.Gossip(ctx, committee, payload)
+		}
+		return
+	}
 
-			m.Add(hash, true)
-			sb.recentMessages.Add(addr, m)
+	targets := sb.committeeTargets(committee)
+	ps := sb.broadcaster.FindPeers(targets)
+	addrs := make([]common.Address, 0, len(ps))
+	for addr := range ps {
+		addrs = append(addrs, addr)
+	}
+	for i, payload := range payloads {
+		hash := types.RLPHash(payload)
+		sb.knownMessages.Add(hash, uint64(maxRelayHops))
+		for j := 0; j < partFanout && j < len(addrs); j++ {
+			addr := addrs[(i*partFanout+j)%len(addrs)]
+			if sb.markSent(addr, hash) {
+				go ps[addr].Send(tendermintMsg, payload) //nolint
+			}
+		}
+	}
+}
 
-			go p.Send(tendermintMsg, payload) //nolint
+// markSent marks the message as sent to the peer, it returns false if the
+// peer already had it.
+func (sb *Backend) markSent(addr common.Address, hash common.Hash) bool {
+	ms, ok := sb.recentMessages.Get(addr)
+	var m *lru.ARCCache
+	if ok {
+		m, _ = ms.(*lru.ARCCache)
+		if _, k := m.Get(hash); k {
+			// This peer had this event, skip it
+			return false
 		}
+	} else {
+		m, _ = lru.NewARC(inmemoryMessages)
 	}
+
+	// false marks a message sent to the peer, see HandleMsg
+	m.Add(hash, false)
+	sb.recentMessages.Add(addr, m)
+	return true
 }
 
 // KnownMsgHash dumps the known messages in case of gossiping.
@@ -235,7 +344,19 @@ func (sb *Backend) Commit(proposal *types.Block, round int64, seals [][]byte) er
 	if err := types.WriteCommittedSeals(h, seals); err != nil {
 		return err
 	}
+	return sb.commit(proposal, h, round)
+}
 
+// CommitAggregated implements tendermint.Backend.CommitAggregated
+func (sb *Backend) CommitAggregated(proposal *types.Block, round int64, seal types.AggregatedSeal) error {
+	h := proposal.Header()
+	if err := types.WriteAggregatedSeal(h, seal); err != nil {
+		return err
+	}
+	return sb.commit(proposal, h, round)
+}
+
+func (sb *Backend) commit(proposal *types.Block, h *types.Header, round int64) error {
 	if err := types.WriteRound(h, round); err != nil {
 		return err
 	}
@@ -332,33 +453,25 @@ func (sb *Backend) VerifyProposal(proposal types.Block) (time.Duration, error) {
 		}
 
 		//Perform the actual comparison
-		if len(header.Committee) != len(committeeSet) {
+		if !header.Committee.Equal(committeeSet) {
 			sb.logger.Error("wrong committee set",
+				"currentVerifier", sb.Address().String(),
 				"proposalNumber", proposalNumber,
-				"extraLen", len(header.Committee),
-				"currentLen", len(committeeSet),
-				"committee", header.Committee,
-				"current", committeeSet,
+				"headerCommittee", header.Committee,
+				"computedCommittee", committeeSet,
 			)
 			return 0, consensus.ErrInconsistentCommitteeSet
 		}
+		// At this stage committee field is consistent with the validator list returned by Soma-contract
 
-		for i := range committeeSet {
-			if header.Committee[i].Address != committeeSet[i].Address ||
-				header.Committee[i].VotingPower.Cmp(committeeSet[i].VotingPower) != 0 {
-				sb.logger.Error("wrong committee member in the set",
-					"index", i,
-					"currentVerifier", sb.address.String(),
-					"proposalNumber", proposalNumber,
-					"headerCommittee", header.Committee[i],
-					"computedCommittee", committeeSet[i],
-					"fullHeader", header.Committee,
-					"fullComputed", committeeSet,
-				)
-				return 0, consensus.ErrInconsistentCommitteeSet
+		// The block is written without being executed again once committed
+		var logs []*types.Log
+		for _, r := range receipts {
+			if r != nil {
+				logs = append(logs, r.Logs...)
 			}
 		}
-		// At this stage committee field is consistent with the validator list returned by Soma-contract
+		sb.blockchain.AddProcessedBlock(block, &core.ProcessedBlock{State: state, Receipts: receipts, Logs: logs, UsedGas: *usedGas})
 
 		return 0, nil
 	} else if err == consensus.ErrFutureBlock {
@@ -369,8 +482,47 @@ func (sb *Backend) VerifyProposal(proposal types.Block) (time.Duration, error) {
 
 // Sign implements tendermint.Backend.Sign
 func (sb *Backend) Sign(data []byte) ([]byte, error) {
-	hashData := crypto.Keccak256(data)
-	return crypto.Sign(hashData, sb.privateKey)
+	return sb.consensusSigner().SignData(accounts.MimetypeTendermintMessage, data)
+}
+
+// SignCommittedSeal implements tendermint.Backend.SignCommittedSeal
+func (sb *Backend) SignCommittedSeal(seal []byte, aggregated bool) ([]byte, error) {
+	if aggregated {
+		return sb.consensusSigner().SignData(accounts.MimetypeTendermintAggregatedSeal, seal)
+	}
+	return sb.consensusSigner().SignData(accounts.MimetypeTendermintSeal, seal)
+}
+
+// ConsensusKey returns the BLS public key of the node followed by its proof of
+// possession, which has to be registered in the autonity contract.
+func (sb *Backend) ConsensusKey() []byte {
+	return sb.consensusSigner().ConsensusKey()
+}
+
+// ConsensusKeys returns the BLS public key and its proof of possession of every
+// consensus key of the node, indexed by the address of the key.
+func (sb *Backend) ConsensusKeys() map[common.Address][]byte {
+	keys := make(map[common.Address][]byte, len(sb.signers))
+	for _, signer := range sb.signers {
+		keys[signer.Address()] = signer.ConsensusKey()
+	}
+	return keys
+}
+
+// consensusSigner returns the signer of the node which is a member of the
+// committee of the next block, the first one if none is. The keys rotated in
+// take over at the height from which the committee holds their address.
+func (sb *Backend) consensusSigner() ConsensusSigner {
+	if len(sb.signers) == 1 || sb.currentBlock == nil {
+		return sb.signers[0]
+	}
+	head := sb.currentBlock().Header()
+	for _, signer := range sb.signers {
+		if head.CommitteeMember(signer.Address()) != nil {
+			return signer
+		}
+	}
+	return sb.signers[0]
 }
 
 // CheckSignature implements tendermint.Backend.CheckSignature
@@ -437,8 +589,8 @@ func (sb *Backend) WhiteList() []string {
 	return enodes.StrList
 }
 
-// Synchronize new connected peer with current height state
-func (sb *Backend) SyncPeer(address common.Address) {
+// SyncPeer implements tendermint.Backend.SyncPeer
+func (sb *Backend) SyncPeer(address common.Address, request *tendermintCore.SyncRequest) {
 	if sb.broadcaster == nil {
 		return
 	}
@@ -451,6 +603,9 @@ func (sb *Backend) SyncPeer(address common.Address) {
 		return
 	}
 	messages := sb.core.GetCurrentHeightMessages()
+	if request != nil {
+		messages = request.Missing(sb.currentBlock().Header(), messages)
+	}
 	for _, msg := range messages {
 		//We do not save sync messages in the arc cache as recipient could not have been able to process some previous sent.
 		go p.Send(tendermintMsg, msg.Payload()) //nolint
@@ -51,14 +51,16 @@ func TestAskSync(t *testing.T) {
 	defer ctrl.Finish()
 	// We are testing for a Quorum Q of peers to be asked for sync.
 	header := newTestHeader(7) // N=7, F=2, Q=5
+	header.Number = big.NewInt(1)
 	validators := header.Committee
 	addresses := make([]common.Address, 0, len(validators))
 	peers := make(map[common.Address]consensus.Peer)
 	counter := uint64(0)
+	request := &tendermintCore.SyncRequest{Height: big.NewInt(2)}
 	for _, val := range validators {
 		addresses = append(addresses, val.Address)
 		mockedPeer := consensus.NewMockPeer(ctrl)
-		mockedPeer.EXPECT().Send(uint64(tendermintSyncMsg), gomock.Eq([]byte{})).Do(func(_, _ interface{}) {
+		mockedPeer.EXPECT().Send(uint64(tendermintSyncRequestMsg), gomock.Eq(request)).Do(func(_, _ interface{}) {
 			atomic.AddUint64(&counter, 1)
 		}).MaxTimes(1)
 		peers[val.Address] = mockedPeer
@@ -75,9 +77,12 @@ func TestAskSync(t *testing.T) {
 
 	broadcaster := consensus.NewMockBroadcaster(ctrl)
 	broadcaster.EXPECT().FindPeers(m).Return(peers)
+	tendermintC := tendermintCore.NewMockTendermint(ctrl)
+	tendermintC.EXPECT().GetCurrentHeightMessages().Return(nil)
 	b := &Backend{
 		knownMessages: knownMessages,
 		logger:        log.New("backend", "test", "id", 0),
+		core:          tendermintC,
 	}
 	b.SetBroadcaster(broadcaster)
 	b.AskSync(header)
@@ -153,6 +158,242 @@ func TestGossip(t *testing.T) {
 	}
 }
 
+func TestGossipRotatedKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// the second member has rotated its consensus key, its peer is still
+	// known by the address of its enode key.
+	committee := types.Committee{
+		{Address: common.Address{1}, Treasury: common.Address{1}, VotingPower: common.Big1},
+		{Address: common.Address{2}, Treasury: common.Address{3}, VotingPower: common.Big1},
+	}
+	payload, err := rlp.EncodeToBytes([]byte("data"))
+	if err != nil {
+		t.Fatalf("Expected <nil>, got %v", err)
+	}
+	counter := uint64(0)
+	peers := make(map[common.Address]consensus.Peer)
+	for _, addr := range []common.Address{{1}, {3}} {
+		mockedPeer := consensus.NewMockPeer(ctrl)
+		mockedPeer.EXPECT().Send(uint64(tendermintMsg), payload).Do(func(_, _ interface{}) {
+			atomic.AddUint64(&counter, 1)
+		}).Times(1)
+		peers[addr] = mockedPeer
+	}
+
+	broadcaster := consensus.NewMockBroadcaster(ctrl)
+	broadcaster.EXPECT().FindPeers(map[common.Address]struct{}{{1}: {}, {3}: {}}).Return(peers)
+
+	knownMessages, err := lru.NewARC(inmemoryMessages)
+	if err != nil {
+		t.Fatalf("Expected <nil>, got %v", err)
+	}
+	recentMessages, err := lru.NewARC(inmemoryMessages)
+	if err != nil {
+		t.Fatalf("Expected <nil>, got %v", err)
+	}
+	b := &Backend{
+		knownMessages:  knownMessages,
+		recentMessages: recentMessages,
+	}
+	b.SetBroadcaster(broadcaster)
+
+	b.Gossip(context.Background(), committee, payload)
+	<-time.NewTimer(2 * time.Second).C
+	if atomic.LoadUint64(&counter) != 2 {
+		t.Fatalf("gossip message transmission failure")
+	}
+}
+
+func TestGossipParts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	header := newTestHeader(5)
+	validators := header.Committee
+	payloads := make([][]byte, 5)
+	for i := range payloads {
+		payload, err := rlp.EncodeToBytes([]byte(fmt.Sprintf("part %d", i)))
+		if err != nil {
+			t.Fatalf("Expected <nil>, got %v", err)
+		}
+		payloads[i] = payload
+	}
+
+	// each part is sent to partFanout peers, all the peers get some parts
+	sent := make([]uint64, len(payloads))
+	peers := make(map[common.Address]consensus.Peer)
+	m := make(map[common.Address]struct{})
+	for _, val := range validators {
+		mockedPeer := consensus.NewMockPeer(ctrl)
+		mockedPeer.EXPECT().Send(uint64(tendermintMsg), gomock.Any()).Do(func(msgCode, data interface{}) {
+			for i, payload := range payloads {
+				if reflect.DeepEqual(data, payload) {
+					atomic.AddUint64(&sent[i], 1)
+				}
+			}
+		}).MinTimes(1)
+		peers[val.Address] = mockedPeer
+		m[val.Address] = struct{}{}
+	}
+
+	broadcaster := consensus.NewMockBroadcaster(ctrl)
+	broadcaster.EXPECT().FindPeers(m).Return(peers)
+
+	knownMessages, err := lru.NewARC(inmemoryMessages)
+	if err != nil {
+		t.Fatalf("Expected <nil>, got %v", err)
+	}
+	recentMessages, err := lru.NewARC(inmemoryMessages)
+	if err != nil {
+		t.Fatalf("Expected <nil>, got %v", err)
+	}
+	b := &Backend{
+		knownMessages:  knownMessages,
+		recentMessages: recentMessages,
+	}
+	b.SetBroadcaster(broadcaster)
+
+	b.GossipParts(context.Background(), validators, payloads)
+	<-time.NewTimer(2 * time.Second).C
+	for i := range payloads {
+		if n := atomic.LoadUint64(&sent[i]); n != partFanout {
+			t.Fatalf("Expected part %d to be sent to %d peers, got %d", i, partFanout, n)
+		}
+		if _, ok := knownMessages.Get(types.RLPHash(payloads[i])); !ok {
+			t.Fatalf("Expected part %d to be known", i)
+		}
+	}
+}
+
+func TestRelay(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	validator := crypto.PubkeyToAddress(key.PublicKey)
+	header := &types.Header{
+		Number:    big.NewInt(4),
+		Committee: types.Committee{{Address: validator, VotingPower: big.NewInt(1)}},
+	}
+	vote := func(height int64, key *ecdsa.PrivateKey) []byte {
+		encodedVote, err := tendermintCore.Encode(&tendermintCore.Vote{Height: big.NewInt(height), ProposedBlockHash: common.HexToHash("0x1")})
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg := &tendermintCore.Message{Code: tendermintCore.MsgPrevote, Msg: encodedVote, Address: validator}
+		data, err := msg.PayloadNoSig()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg.Signature, err = crypto.Sign(crypto.Keccak256(data), key); err != nil {
+			t.Fatal(err)
+		}
+		return msg.Payload()
+	}
+	newBackend := func() *Backend {
+		recentMessages, _ := lru.NewARC(inmemoryPeers)
+		knownMessages, _ := lru.NewARC(inmemoryMessages)
+		return &Backend{
+			logger:         log.New("backend", "test", "id", 0),
+			recentMessages: recentMessages,
+			knownMessages:  knownMessages,
+			scores:         newPeerScorer(),
+			currentBlock:   func() *types.Block { return types.NewBlockWithHeader(header) },
+			relay:          true,
+		}
+	}
+	relayAddr, senderAddr := common.HexToAddress("0x2"), common.HexToAddress("0x3")
+	committee := map[common.Address]struct{}{validator: {}}
+
+	t.Run("messages are relayed with their hops left", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		payload := vote(5, key)
+		sent := make(chan struct{}, 2)
+		done := func(interface{}, interface{}) { sent <- struct{}{} }
+
+		validatorPeer, relayPeer := consensus.NewMockPeer(ctrl), consensus.NewMockPeer(ctrl)
+		validatorPeer.EXPECT().Send(uint64(tendermintMsg), payload).Do(done)
+		relayPeer.EXPECT().Send(uint64(tendermintRelayMsg), relayMsg{Payload: payload, TTL: 1}).Do(done)
+		broadcaster := consensus.NewMockBroadcaster(ctrl)
+		broadcaster.EXPECT().FindPeers(committee).Return(map[common.Address]consensus.Peer{validator: validatorPeer})
+		broadcaster.EXPECT().Peers().Return(map[common.Address]consensus.Peer{validator: validatorPeer, relayAddr: relayPeer})
+
+		b := newBackend()
+		b.SetBroadcaster(broadcaster)
+		b.knownMessages.Add(types.RLPHash(payload), uint64(2))
+		b.Gossip(context.Background(), header.Committee, payload)
+		<-sent
+		<-sent
+	})
+
+	t.Run("messages without hops left reach the committee only", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		payload := vote(5, key)
+		sent := make(chan struct{}, 1)
+
+		validatorPeer := consensus.NewMockPeer(ctrl)
+		validatorPeer.EXPECT().Send(uint64(tendermintMsg), payload).Do(func(interface{}, interface{}) { sent <- struct{}{} })
+		broadcaster := consensus.NewMockBroadcaster(ctrl)
+		broadcaster.EXPECT().FindPeers(committee).Return(map[common.Address]consensus.Peer{validator: validatorPeer})
+
+		b := newBackend()
+		b.SetBroadcaster(broadcaster)
+		b.knownMessages.Add(types.RLPHash(payload), uint64(0))
+		b.Gossip(context.Background(), header.Committee, payload)
+		<-sent
+	})
+
+	t.Run("messages are verified and forwarded without the core", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		payload := vote(5, key)
+		sent := make(chan struct{}, 2)
+		done := func(interface{}, interface{}) { sent <- struct{}{} }
+
+		validatorPeer, relayPeer, senderPeer := consensus.NewMockPeer(ctrl), consensus.NewMockPeer(ctrl), consensus.NewMockPeer(ctrl)
+		validatorPeer.EXPECT().Send(uint64(tendermintMsg), payload).Do(done)
+		relayPeer.EXPECT().Send(uint64(tendermintRelayMsg), relayMsg{Payload: payload, TTL: 1}).Do(done)
+		broadcaster := consensus.NewMockBroadcaster(ctrl)
+		broadcaster.EXPECT().FindPeers(committee).Return(map[common.Address]consensus.Peer{validator: validatorPeer})
+		broadcaster.EXPECT().Peers().Return(map[common.Address]consensus.Peer{
+			validator:  validatorPeer,
+			relayAddr:  relayPeer,
+			senderAddr: senderPeer,
+		})
+
+		b := newBackend()
+		b.SetBroadcaster(broadcaster)
+		if _, err := b.HandleMsg(senderAddr, makeMsg(tendermintRelayMsg, relayMsg{Payload: payload, TTL: 2})); err != nil {
+			t.Fatalf("Expected <nil>, got %v", err)
+		}
+		<-sent
+		<-sent
+		// a duplicate isn't forwarded again
+		if _, err := b.HandleMsg(relayAddr, makeMsg(tendermintRelayMsg, relayMsg{Payload: payload, TTL: 2})); err != nil {
+			t.Fatalf("Expected <nil>, got %v", err)
+		}
+	})
+
+	t.Run("messages of other heights and forged messages are not forwarded", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		b := newBackend()
+		b.SetBroadcaster(consensus.NewMockBroadcaster(ctrl))
+
+		if _, err := b.HandleMsg(senderAddr, makeMsg(tendermintMsg, vote(6, key))); err != nil {
+			t.Fatalf("Expected <nil>, got %v", err)
+		}
+		other, _ := crypto.GenerateKey()
+		if _, err := b.HandleMsg(senderAddr, makeMsg(tendermintMsg, vote(5, other))); err != nil {
+			t.Fatalf("Expected <nil>, got %v", err)
+		}
+		if score := b.PeerScores()[senderAddr]; score.NotCommittee != 1 {
+			t.Fatalf("Expected 1, got %v", score.NotCommittee)
+		}
+	})
+}
+
 func TestVerifyProposal(t *testing.T) {
 	blockchain, backend := newBlockChain(1)
 	blocks := make([]*types.Block, 5)
@@ -207,6 +448,41 @@ func TestVerifyProposal(t *testing.T) {
 	}
 
 }
+
+func TestVerifyProposalCommittee(t *testing.T) {
+	cases := map[string]func(member *types.CommitteeMember){
+		"consensus key": func(member *types.CommitteeMember) {
+			member.ConsensusKey = append(common.CopyBytes(member.ConsensusKey), 1)
+		},
+		"treasury": func(member *types.CommitteeMember) {
+			member.Treasury = common.Address{1}
+		},
+	}
+	for name, tamper := range cases {
+		t.Run(name, func(t *testing.T) {
+			blockchain, backend := newBlockChain(1)
+			block, err := makeBlockWithoutSeal(blockchain, backend, blockchain.Genesis())
+			if err != nil {
+				t.Fatal(err)
+			}
+			header := block.Header()
+			tamper(&header.Committee[0])
+			seal, err := backend.Sign(types.SigHash(header).Bytes())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := types.WriteSeal(header, seal); err != nil {
+				t.Fatal(err)
+			}
+			block = block.WithSeal(header)
+
+			time.Sleep(time.Duration(backend.config.BlockPeriod) * time.Second)
+			if _, err := backend.VerifyProposal(*block); err != consensus.ErrInconsistentCommitteeSet {
+				t.Fatalf("Expected %v, got %v", consensus.ErrInconsistentCommitteeSet, err)
+			}
+		})
+	}
+}
 func TestResetPeerCache(t *testing.T) {
 	addr := common.HexToAddress("0x01234567890")
 	msgCache, err := lru.NewARC(inmemoryMessages)
@@ -263,7 +539,7 @@ func TestSign(t *testing.T) {
 	pubkey, _ := crypto.Ecrecover(hashData, sig)
 	var signer common.Address
 	copy(signer[:], crypto.Keccak256(pubkey[1:])[12:])
-	if signer != b.address {
+	if signer != b.Address() {
 		t.Errorf("address mismatch: have %v, want %s", signer.Hex(), getAddress().Hex())
 	}
 }
@@ -389,7 +665,7 @@ func TestCommit(t *testing.T) {
 func TestSyncPeer(t *testing.T) {
 	t.Run("no broadcaster set, nothing done", func(t *testing.T) {
 		b := &Backend{}
-		b.SyncPeer(common.HexToAddress("0x0123456789"))
+		b.SyncPeer(common.HexToAddress("0x0123456789"), nil)
 	})
 
 	t.Run("valid params given, messages sent", func(t *testing.T) {
@@ -432,7 +708,54 @@ func TestSyncPeer(t *testing.T) {
 		}
 		b.SetBroadcaster(broadcaster)
 
-		b.SyncPeer(peerAddr1)
+		b.SyncPeer(peerAddr1, nil)
+
+		wait := time.NewTimer(time.Second)
+		<-wait.C
+	})
+
+	t.Run("sync request given, missing messages sent", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		header := newTestHeader(3)
+		header.Number = big.NewInt(4)
+		prevote := func(member types.CommitteeMember) *tendermintCore.Message {
+			encoded, err := tendermintCore.Encode(&tendermintCore.Vote{Round: 0, Height: big.NewInt(5)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			// the messages held by the core are decoded
+			msg := new(tendermintCore.Message)
+			payload := (&tendermintCore.Message{Code: tendermintCore.MsgPrevote, Msg: encoded, Address: member.Address}).Payload()
+			if err := msg.FromPayload(payload); err != nil {
+				t.Fatal(err)
+			}
+			return msg
+		}
+		messages := []*tendermintCore.Message{
+			prevote(header.Committee[0]),
+			prevote(header.Committee[1]),
+			prevote(header.Committee[2]),
+		}
+		request := tendermintCore.NewSyncRequest(header, messages[:2])
+
+		peerAddr1 := common.HexToAddress("0x0123456789")
+		peer1Mock := consensus.NewMockPeer(ctrl)
+		peer1Mock.EXPECT().Send(uint64(tendermintMsg), messages[2].Payload()).Times(1)
+		broadcaster := consensus.NewMockBroadcaster(ctrl)
+		broadcaster.EXPECT().FindPeers(map[common.Address]struct{}{peerAddr1: {}}).Return(map[common.Address]consensus.Peer{peerAddr1: peer1Mock})
+		tendermintC := tendermintCore.NewMockTendermint(ctrl)
+		tendermintC.EXPECT().GetCurrentHeightMessages().Return(messages)
+
+		b := &Backend{
+			logger:       log.New("backend", "test", "id", 0),
+			core:         tendermintC,
+			currentBlock: func() *types.Block { return types.NewBlockWithHeader(header) },
+		}
+		b.SetBroadcaster(broadcaster)
+
+		b.SyncPeer(peerAddr1, request)
 
 		wait := time.NewTimer(time.Second)
 		<-wait.C
@@ -682,7 +1005,7 @@ func makeBlockWithoutSeal(chain *core.BlockChain, engine *Backend, parent *types
 
 	//add a few txs
 	txs := make(types.Transactions, 5)
-	nonce := state.GetNonce(engine.address)
+	nonce := state.GetNonce(engine.Address())
 	gasPrice := new(big.Int).SetUint64(1000000)
 	gasPool := new(core.GasPool).AddGas(header.GasLimit)
 	var receipts []*types.Receipt
@@ -27,9 +27,12 @@ import (
 	"github.com/clearmatics/autonity/trie"
 
 	"github.com/clearmatics/autonity/consensus/tendermint/bft"
+	"github.com/clearmatics/autonity/consensus/tendermint/config"
 	"github.com/clearmatics/autonity/consensus/tendermint/crypto"
 	"github.com/clearmatics/autonity/core"
 
+	"github.com/clearmatics/autonity/accounts"
+	"github.com/clearmatics/autonity/autonity"
 	"github.com/clearmatics/autonity/common"
 	"github.com/clearmatics/autonity/common/hexutil"
 	"github.com/clearmatics/autonity/consensus"
@@ -37,6 +40,7 @@ import (
 	"github.com/clearmatics/autonity/consensus/tendermint/events"
 	"github.com/clearmatics/autonity/core/state"
 	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/rlp"
 	"github.com/clearmatics/autonity/rpc"
 )
 
@@ -71,6 +75,15 @@ var (
 	errInvalidTimestamp = errors.New("invalid timestamp")
 	// errInvalidRound is returned if the round exceed maximum round number.
 	errInvalidRound = errors.New("invalid round")
+	// errInvalidPastCommittedSeals is returned if the past committed seals are not a quorum of
+	// valid precommits for the parent block.
+	errInvalidPastCommittedSeals = errors.New("invalid past committed seals")
+	// errInvalidProposer is returned if a header is not signed by the proposer of
+	// one of the rounds up to the round it was committed in.
+	errInvalidProposer = errors.New("invalid proposer")
+	// errInvalidProposerPriorities is returned if the proposer priorities of a
+	// header don't follow from the priorities of its parent.
+	errInvalidProposerPriorities = errors.New("invalid proposer priorities")
 )
 var (
 	defaultDifficulty = big.NewInt(1)
@@ -93,13 +106,21 @@ func (sb *Backend) Author(header *types.Header) (common.Address, error) {
 // given engine. Verifying the seal may be done optionally here, or explicitly
 // via the VerifySeal method.
 func (sb *Backend) VerifyHeader(chain consensus.ChainHeaderReader, header *types.Header, _ bool) error {
-	return sb.verifyHeader(header, chain.GetHeaderByHash(header.ParentHash))
+	return sb.verifyHeader(header, chain.GetHeaderByHash(header.ParentHash), chain.GetHeaderByNumber, true)
 }
 
 // verifyHeader checks whether a header conforms to the consensus rules. It
 // expects the parent header to be provided unless header is the genesis
-// header.
-func (sb *Backend) verifyHeader(header, parent *types.Header) error {
+// header, getHeader is used to retrieve the grandparent header and the headers
+// the evidence refers to.
+//
+// Light clients only store the headers from their trusted checkpoint onwards
+// and don't execute the blocks, they check the header against its parent and
+// its committed seals against the committee of the parent, the committee
+// transitions are followed that way. The checks relying on older headers are
+// left to the committee which sealed the header. The committed seals are only
+// verified if seal is set.
+func (sb *Backend) verifyHeader(header, parent *types.Header, getHeader func(number uint64) *types.Header, seal bool) error {
 	if header.Number == nil {
 		return errUnknownBlock
 	}
@@ -137,11 +158,35 @@ func (sb *Backend) verifyHeader(header, parent *types.Header) error {
 	if parent == nil {
 		return errUnknownBlock
 	}
-	return sb.verifyHeaderAgainstParent(header, parent)
+	if sb.light {
+		return sb.verifyHeaderAgainstParent(header, parent, seal)
+	}
+	if err := verifyEvidence(header, getHeader); err != nil {
+		return err
+	}
+	if err := sb.verifyHeaderAgainstParent(header, parent, true); err != nil {
+		return err
+	}
+
+	var grandParent *types.Header
+	if !parent.IsGenesis() {
+		grandParent = getHeader(parent.Number.Uint64() - 1)
+		if grandParent == nil || grandParent.Hash() != parent.ParentHash {
+			return consensus.ErrUnknownAncestor
+		}
+	}
+	if sb.config.ProposerPolicy == config.WeightedRoundRobin {
+		if err := verifyProposerPriorities(header, parent, grandParent); err != nil {
+			return err
+		}
+	}
+	return sb.verifyPastCommittedSeals(header, parent, grandParent)
 }
 
-// verifyHeaderAgainstParent verifies that the given header is valid with respect to its parent.
-func (sb *Backend) verifyHeaderAgainstParent(header, parent *types.Header) error {
+// verifyHeaderAgainstParent verifies that the given header is valid with respect
+// to its parent, the signer and the committed seals are only verified if seal
+// is set.
+func (sb *Backend) verifyHeaderAgainstParent(header, parent *types.Header, seal bool) error {
 	if parent.Number.Uint64() != header.Number.Uint64()-1 || parent.Hash() != header.ParentHash {
 		return consensus.ErrUnknownAncestor
 	}
@@ -149,6 +194,9 @@ func (sb *Backend) verifyHeaderAgainstParent(header, parent *types.Header) error
 	if parent.Time+sb.config.BlockPeriod > header.Time {
 		return errInvalidTimestamp
 	}
+	if !seal {
+		return nil
+	}
 	if err := sb.verifySigner(header, parent); err != nil {
 		return err
 	}
@@ -160,9 +208,22 @@ func (sb *Backend) verifyHeaderAgainstParent(header, parent *types.Header) error
 // concurrently. The method returns a quit channel to abort the operations and
 // a results channel to retrieve the async verifications (the order is that of
 // the input slice).
+//
+// Light clients only verify the committed seals of the headers requested by
+// seals, of the headers changing the committee and of the last header. The
+// headers in between are sealed by the same committee as the next verified
+// header, which binds them through its parent hash, so that the batch skips
+// ahead to the next committee transition.
 func (sb *Backend) VerifyHeaders(chain consensus.ChainHeaderReader, headers []*types.Header, seals []bool) (chan<- struct{}, <-chan error) {
 	abort := make(chan struct{}, 1)
 	results := make(chan error, len(headers))
+	// evidence can refer to headers of the batch which are not in the chain yet.
+	getHeader := func(number uint64) *types.Header {
+		if first := headers[0].Number.Uint64(); number >= first && number-first < uint64(len(headers)) {
+			return headers[number-first]
+		}
+		return chain.GetHeaderByNumber(number)
+	}
 	go func() {
 		for i, header := range headers {
 			var parent *types.Header
@@ -172,7 +233,9 @@ func (sb *Backend) VerifyHeaders(chain consensus.ChainHeaderReader, headers []*t
 			case i == 0:
 				parent = chain.GetHeaderByHash(header.ParentHash)
 			}
-			err := sb.verifyHeader(header, parent)
+			seal := !sb.light || i == len(headers)-1 || (i < len(seals) && seals[i]) ||
+				parent == nil || !header.Committee.Equal(parent.Committee)
+			err := sb.verifyHeader(header, parent, getHeader, seal)
 			select {
 			case <-abort:
 				return
@@ -205,46 +268,178 @@ func (sb *Backend) verifySigner(header, parent *types.Header) error {
 	}
 
 	// Signer should be in the validator set of previous block's extraData.
-	if parent.CommitteeMember(signer) != nil {
-		return nil
+	member := parent.CommitteeMember(signer)
+	if member == nil {
+		return errUnauthorized
 	}
 
-	return errUnauthorized
+	switch sb.config.ProposerPolicy {
+	case config.RandomBeacon:
+		// a block proposed at a previous round can be proposed again and
+		// committed at a later round.
+		seed := tendermintCore.BeaconSeed(parent)
+		for r := int64(0); r <= int64(header.Round); r++ {
+			if tendermintCore.BeaconProposer(parent.Committee, seed, header.Number, r).Address == signer {
+				return tendermintCore.VerifyBeacon(header, parent, *member)
+			}
+		}
+		return errInvalidProposer
+	case config.WeightedRoundRobin:
+		// a block proposed at a previous round can be proposed again and
+		// committed at a later round.
+		if len(header.ProposerPriorities) != len(parent.Committee) {
+			return errInvalidProposerPriorities
+		}
+		for _, proposer := range tendermintCore.PriorityProposers(parent.Committee, header.ProposerPriorities, int64(header.Round)) {
+			if proposer.Address == signer {
+				return nil
+			}
+		}
+		return errInvalidProposer
+	}
+	return nil
+}
+
+// verifyProposerPriorities checks that the proposer priorities of the header
+// follow from the priorities of its parent.
+func verifyProposerPriorities(header, parent, grandParent *types.Header) error {
+	expected := tendermintCore.NextProposerPriorities(parent, grandParent)
+	if len(header.ProposerPriorities) != len(expected) {
+		return errInvalidProposerPriorities
+	}
+	for i, p := range expected {
+		if header.ProposerPriorities[i].Cmp(p) != 0 {
+			return errInvalidProposerPriorities
+		}
+	}
+	return nil
 }
 
 // verifyCommittedSeals validates that the committed seals for header come from
 // committee members and that the voting power of the committed seals constitutes
 // a quorum.
 func (sb *Backend) verifyCommittedSeals(header, parent *types.Header) error {
+	// The data that was sined over for this block
+	headerSeal := tendermintCore.PrepareCommittedSeal(header.Hash(), int64(header.Round), header.Number)
+	if crypto.SealsAggregated(parent.Committee) {
+		if header.AggregatedSeal.Empty() {
+			return types.ErrEmptyCommittedSeals
+		}
+		if len(header.CommittedSeals) != 0 {
+			return types.ErrInvalidCommittedSeals
+		}
+		return verifyAggregatedSealQuorum(header.AggregatedSeal, headerSeal, parent)
+	}
 	// The length of Committed seals should be larger than 0
 	if len(header.CommittedSeals) == 0 {
 		return types.ErrEmptyCommittedSeals
 	}
+	if !header.AggregatedSeal.Empty() {
+		return types.ErrInvalidCommittedSeals
+	}
+	return sb.verifySealsQuorum(header.CommittedSeals, headerSeal, parent)
+}
+
+// verifyPastCommittedSeals validates that the past committed seals for header are
+// a quorum of precommits for the parent block from the committee which decided
+// on it, that is the committee stored in the grandparent header. The block
+// following the genesis block has no past committed seals.
+func (sb *Backend) verifyPastCommittedSeals(header, parent, grandParent *types.Header) error {
+	if parent.IsGenesis() {
+		if len(header.PastCommittedSeals) != 0 || !header.PastAggregatedSeal.Empty() {
+			return errInvalidPastCommittedSeals
+		}
+		return nil
+	}
+	parentSeal := tendermintCore.PrepareCommittedSeal(parent.Hash(), int64(parent.Round), parent.Number)
+	if crypto.SealsAggregated(grandParent.Committee) {
+		if len(header.PastCommittedSeals) != 0 {
+			return errInvalidPastCommittedSeals
+		}
+		if err := verifyAggregatedSealQuorum(header.PastAggregatedSeal, parentSeal, grandParent); err != nil {
+			return errInvalidPastCommittedSeals
+		}
+		return nil
+	}
+	if len(header.PastCommittedSeals) == 0 || !header.PastAggregatedSeal.Empty() {
+		return errInvalidPastCommittedSeals
+	}
+	if err := sb.verifySealsQuorum(header.PastCommittedSeals, parentSeal, grandParent); err != nil {
+		return errInvalidPastCommittedSeals
+	}
+	return nil
+}
+
+// headerParticipation returns which members of the committee which decided on
+// the parent block have a seal among the past committed seals of the header,
+// which are expected to have been verified.
+func headerParticipation(chain consensus.ChainHeaderReader, header *types.Header) (autonity.Participation, error) {
+	var participation autonity.Participation
+	if len(header.PastCommittedSeals) == 0 && header.PastAggregatedSeal.Empty() {
+		return participation, nil
+	}
 
+	parent := chain.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+	if parent == nil || parent.IsGenesis() {
+		return participation, consensus.ErrUnknownAncestor
+	}
+	grandParent := chain.GetHeader(parent.ParentHash, parent.Number.Uint64()-1)
+	if grandParent == nil {
+		return participation, consensus.ErrUnknownAncestor
+	}
+
+	parentSeal := tendermintCore.PrepareCommittedSeal(parent.Hash(), int64(parent.Round), parent.Number)
+	signed := make(map[common.Address]struct{}, len(grandParent.Committee))
+	if !header.PastAggregatedSeal.Empty() {
+		signers, err := crypto.VerifyAggregatedSeal(grandParent.Committee, parentSeal, header.PastAggregatedSeal)
+		if err != nil {
+			return participation, errInvalidPastCommittedSeals
+		}
+		for _, member := range signers {
+			signed[member.Address] = struct{}{}
+		}
+	}
+	for _, seal := range header.PastCommittedSeals {
+		addr, err := types.GetSignatureAddress(parentSeal, seal)
+		if err != nil {
+			return participation, errInvalidPastCommittedSeals
+		}
+		signed[addr] = struct{}{}
+	}
+
+	for _, member := range grandParent.Committee {
+		if _, ok := signed[member.Address]; ok {
+			participation.Signers = append(participation.Signers, member.Address)
+		} else {
+			participation.Absentees = append(participation.Absentees, member.Address)
+		}
+	}
+	return participation, nil
+}
+
+// verifySealsQuorum checks that every seal has been signed over the seal data by
+// a distinct member of the committee stored in the given header and that their
+// voting power constitutes a quorum.
+func (sb *Backend) verifySealsQuorum(seals [][]byte, sealData []byte, committeeHeader *types.Header) error {
 	// Setup map to track votes made by committee members
-	votes := make(map[common.Address]int, len(parent.Committee))
+	votes := make(map[common.Address]int, len(committeeHeader.Committee))
 
 	// Calculate total voting power
-	var committeeVotingPower uint64
-	for _, member := range parent.Committee {
-		committeeVotingPower += member.VotingPower.Uint64()
-	}
+	committeeVotingPower := committeeHeader.Committee.TotalVotingPower()
 
 	// Total Voting power for this block
 	var power uint64
-	// The data that was sined over for this block
-	headerSeal := tendermintCore.PrepareCommittedSeal(header.Hash(), int64(header.Round), header.Number)
 
 	// 1. Get committed seals from current header
-	for _, signedSeal := range header.CommittedSeals {
+	for _, signedSeal := range seals {
 		// 2. Get the address from signature
-		addr, err := types.GetSignatureAddress(headerSeal, signedSeal)
+		addr, err := types.GetSignatureAddress(sealData, signedSeal)
 		if err != nil {
 			sb.logger.Error("not a valid address", "err", err)
 			return types.ErrInvalidSignature
 		}
 
-		member := parent.CommitteeMember(addr)
+		member := committeeHeader.CommitteeMember(addr)
 		if member == nil {
 			sb.logger.Error(fmt.Sprintf("block had seal from non committee member %q", addr))
 			return types.ErrInvalidCommittedSeals
@@ -266,6 +461,20 @@ func (sb *Backend) verifyCommittedSeals(header, parent *types.Header) error {
 	return nil
 }
 
+// verifyAggregatedSealQuorum checks the aggregated seal against the committee
+// stored in the given header and that the voting power of its signers
+// constitutes a quorum.
+func verifyAggregatedSealQuorum(seal types.AggregatedSeal, sealData []byte, committeeHeader *types.Header) error {
+	signers, err := crypto.VerifyAggregatedSeal(committeeHeader.Committee, sealData, seal)
+	if err != nil {
+		return types.ErrInvalidCommittedSeals
+	}
+	if signers.TotalVotingPower() < bft.Quorum(committeeHeader.Committee.TotalVotingPower()) {
+		return types.ErrInvalidCommittedSeals
+	}
+	return nil
+}
+
 // VerifySeal checks whether the crypto seal on a header is valid according to
 // the consensus rules of the given engine.
 func (sb *Backend) VerifySeal(chain consensus.ChainHeaderReader, header *types.Header) error {
@@ -311,9 +520,48 @@ func (sb *Backend) Prepare(chain consensus.ChainHeaderReader, header *types.Head
 	if int64(header.Time) < time.Now().Unix() {
 		header.Time = uint64(time.Now().Unix())
 	}
+
+	// include the seals the parent block was committed with, as a proof of the
+	// committee members who took part in its height
+	header.PastCommittedSeals = make([][]byte, len(parent.CommittedSeals))
+	for i, seal := range parent.CommittedSeals {
+		header.PastCommittedSeals[i] = common.CopyBytes(seal)
+	}
+	header.PastAggregatedSeal = parent.AggregatedSeal.Copy()
+
+	// include the evidence of misbehaviours so that the offenders get punished
+	header.Evidence = sb.pendingEvidence(chain, header)
+
+	switch sb.config.ProposerPolicy {
+	case config.RandomBeacon:
+		beacon, err := sb.signBeacon(header, parent)
+		if err != nil {
+			return err
+		}
+		header.Beacon = beacon
+	case config.WeightedRoundRobin:
+		var grandParent *types.Header
+		if !parent.IsGenesis() {
+			if grandParent = chain.GetHeader(parent.ParentHash, parent.Number.Uint64()-1); grandParent == nil {
+				return consensus.ErrUnknownAncestor
+			}
+		}
+		header.ProposerPriorities = tendermintCore.NextProposerPriorities(parent, grandParent)
+	}
 	return nil
 }
 
+// signBeacon signs the beacon of the header with the BLS consensus key, the
+// seed of the parent is carried over if no consensus key has been registered.
+func (sb *Backend) signBeacon(header, parent *types.Header) ([]byte, error) {
+	seed := tendermintCore.BeaconSeed(parent)
+	signer := sb.consensusSigner()
+	if member := parent.CommitteeMember(signer.Address()); member == nil || crypto.VerifyConsensusKey(member.ConsensusKey) != nil {
+		return seed.Bytes(), nil
+	}
+	return signer.SignData(accounts.MimetypeTendermintBLSBeacon, tendermintCore.PrepareBeacon(seed, header.Number))
+}
+
 // Finalize runs any post-transaction state modifications (e.g. block rewards)
 // Finaize doesn't modify the passed header.
 func (sb *Backend) Finalize(chain consensus.ChainReader, header *types.Header, state *state.StateDB, txs []*types.Transaction,
@@ -355,7 +603,16 @@ func (sb *Backend) AutonityContractFinalize(header *types.Header, chain consensu
 	sb.contractsMu.Lock()
 	defer sb.contractsMu.Unlock()
 
-	committeeSet, receipt, err := sb.blockchain.GetAutonityContract().FinalizeAndGetCommittee(txs, receipts, header, state)
+	misbehaviours, err := headerMisbehaviours(header)
+	if err != nil {
+		return nil, nil, err
+	}
+	participation, err := headerParticipation(chain, header)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	committeeSet, receipt, err := sb.blockchain.GetAutonityContract().FinalizeAndGetCommittee(txs, receipts, header, state, misbehaviours, participation)
 	if err != nil {
 		sb.logger.Error("Autonity Contract finalize returns err", "err", err)
 		return nil, nil, err
@@ -383,7 +640,7 @@ func (sb *Backend) Seal(chain consensus.ChainReader, block *types.Block, results
 	}
 	nodeAddress := sb.Address()
 	if parent.CommitteeMember(nodeAddress) == nil {
-		sb.logger.Error("error validator errUnauthorized", "addr", sb.address)
+		sb.logger.Error("error validator errUnauthorized", "addr", nodeAddress)
 		return errUnauthorized
 	}
 
@@ -393,23 +650,34 @@ func (sb *Backend) Seal(chain consensus.ChainReader, block *types.Block, results
 		return err
 	}
 
-	// wait for the timestamp of header, use this to adjust the block period
-	delay := time.Unix(int64(block.Header().Time), 0).Sub(now())
-	select {
-	case <-time.After(delay):
-		// nothing to do
-	case <-sb.stopped:
-		return nil
-	case <-stop:
-		return nil
-	}
+	// wait for the timestamp of header, use this to adjust the block period.
+	// The timestamp of an empty block is delayed up to the maximum empty block
+	// interval, the miner isn't held meanwhile so that it replaces the block
+	// as soon as transactions arrive.
+	go func() {
+		delay := time.Unix(int64(block.Header().Time), 0).Sub(now())
+		select {
+		case <-time.After(delay):
+			// nothing to do
+		case <-sb.stopped:
+			return
+		case <-stop:
+			return
+		}
+		// the block could have been replaced while the delay elapsed.
+		select {
+		case <-stop:
+			return
+		default:
+		}
 
-	sb.setResultChan(results)
+		sb.setResultChan(results)
 
-	// post block into BFT engine
-	sb.postEvent(events.NewUnminedBlockEvent{
-		NewUnminedBlock: *block,
-	})
+		// post block into BFT engine
+		sb.postEvent(events.NewUnminedBlockEvent{
+			NewUnminedBlock: *block,
+		})
+	}()
 
 	return nil
 }
@@ -450,10 +718,17 @@ func (sb *Backend) SetProposedBlockHash(hash common.Hash) {
 func (sb *Backend) AddSeal(block *types.Block) (*types.Block, error) {
 	header := block.Header()
 
-	err := crypto.SignHeader(header, sb.privateKey)
+	data, err := rlp.EncodeToBytes(header)
+	if err != nil {
+		return nil, err
+	}
+	seal, err := sb.consensusSigner().SignData(accounts.MimetypeTendermintHeader, data)
 	if err != nil {
 		return nil, err
 	}
+	if err := types.WriteSeal(header, seal); err != nil {
+		return nil, err
+	}
 
 	return block.WithSeal(header), nil
 }
@@ -465,6 +740,10 @@ func (sb *Backend) APIs(chain consensus.ChainReader) []rpc.API {
 		Version:   "1.0",
 		Service:   &API{chain: chain, tendermint: sb, getCommittee: getCommittee},
 		Public:    true,
+	}, {
+		Namespace: "admin",
+		Version:   "1.0",
+		Service:   &AdminAPI{tendermint: sb},
 	}}
 }
 
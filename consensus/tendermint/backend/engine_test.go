@@ -19,6 +19,7 @@ package backend
 import (
 	"bytes"
 	"context"
+	"crypto/ecdsa"
 	"math/big"
 	"reflect"
 	"sync"
@@ -28,11 +29,14 @@ import (
 	"github.com/clearmatics/autonity/common"
 	"github.com/clearmatics/autonity/common/hexutil"
 	"github.com/clearmatics/autonity/consensus"
+	"github.com/clearmatics/autonity/consensus/tendermint/config"
 	tendermintCore "github.com/clearmatics/autonity/consensus/tendermint/core"
+	tendermintCrypto "github.com/clearmatics/autonity/consensus/tendermint/crypto"
 	"github.com/clearmatics/autonity/consensus/tendermint/events"
 	"github.com/clearmatics/autonity/core"
 	"github.com/clearmatics/autonity/core/types"
 	"github.com/clearmatics/autonity/crypto"
+	"github.com/clearmatics/autonity/params"
 	"github.com/golang/mock/gomock"
 )
 
@@ -234,6 +238,362 @@ func TestVerifySeal(t *testing.T) {
 	}
 }
 
+func TestVerifyPastCommittedSeals(t *testing.T) {
+	_, engine := newBlockChain(1)
+	other, _ := crypto.GenerateKey()
+
+	grandParent := &types.Header{
+		Number: big.NewInt(1),
+		Committee: types.Committee{
+			{Address: engine.Address(), VotingPower: big.NewInt(1)},
+		},
+	}
+	parent := &types.Header{
+		ParentHash: grandParent.Hash(),
+		Number:     big.NewInt(2),
+		Round:      1,
+	}
+	signSeal := func(round int64, hash common.Hash) []byte {
+		seal, err := engine.Sign(tendermintCore.PrepareCommittedSeal(hash, round, parent.Number))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return seal
+	}
+
+	// valid seals for the parent block
+	header := &types.Header{PastCommittedSeals: [][]byte{signSeal(1, parent.Hash())}}
+	assertNilError(t, engine.verifyPastCommittedSeals(header, parent, grandParent))
+
+	// no seals
+	header = &types.Header{}
+	assertError(t, errInvalidPastCommittedSeals, engine.verifyPastCommittedSeals(header, parent, grandParent))
+
+	// seals for another round
+	header = &types.Header{PastCommittedSeals: [][]byte{signSeal(0, parent.Hash())}}
+	assertError(t, errInvalidPastCommittedSeals, engine.verifyPastCommittedSeals(header, parent, grandParent))
+
+	// seals from a member who isn't part of the committee
+	otherSeal, err := crypto.Sign(crypto.Keccak256(tendermintCore.PrepareCommittedSeal(parent.Hash(), 1, parent.Number)), other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header = &types.Header{PastCommittedSeals: [][]byte{otherSeal}}
+	assertError(t, errInvalidPastCommittedSeals, engine.verifyPastCommittedSeals(header, parent, grandParent))
+
+	// the block following the genesis block has no past committed seals
+	genesis := &types.Header{Number: big.NewInt(0)}
+	header = &types.Header{PastCommittedSeals: [][]byte{signSeal(1, parent.Hash())}}
+	assertError(t, errInvalidPastCommittedSeals, engine.verifyPastCommittedSeals(header, genesis, nil))
+	assertNilError(t, engine.verifyPastCommittedSeals(&types.Header{}, genesis, nil))
+}
+
+func TestHeaderParticipation(t *testing.T) {
+	_, engine := newBlockChain(1)
+	absentee, _ := crypto.GenerateKey()
+
+	genesis := &types.Header{Number: big.NewInt(0)}
+	grandParent := &types.Header{
+		ParentHash: genesis.Hash(),
+		Number:     big.NewInt(1),
+		Committee: types.Committee{
+			{Address: engine.Address(), VotingPower: big.NewInt(1)},
+			{Address: crypto.PubkeyToAddress(absentee.PublicKey), VotingPower: big.NewInt(1)},
+		},
+	}
+	parent := &types.Header{ParentHash: grandParent.Hash(), Number: big.NewInt(2), Round: 1}
+	chain := testHeaderChain{genesis, grandParent, parent}
+
+	seal, err := engine.Sign(tendermintCore.PrepareCommittedSeal(parent.Hash(), 1, parent.Number))
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := &types.Header{ParentHash: parent.Hash(), Number: big.NewInt(3), PastCommittedSeals: [][]byte{seal}}
+
+	participation, err := headerParticipation(chain, header)
+	assertNilError(t, err)
+	if len(participation.Signers) != 1 || participation.Signers[0] != engine.Address() {
+		t.Fatalf("Expected signers %v, got %v", engine.Address(), participation.Signers)
+	}
+	if len(participation.Absentees) != 1 || participation.Absentees[0] != crypto.PubkeyToAddress(absentee.PublicKey) {
+		t.Fatalf("Expected absentees %v, got %v", crypto.PubkeyToAddress(absentee.PublicKey), participation.Absentees)
+	}
+
+	// the block following the genesis block has no past committed seals
+	header = &types.Header{ParentHash: genesis.Hash(), Number: big.NewInt(1)}
+	participation, err = headerParticipation(chain, header)
+	assertNilError(t, err)
+	if len(participation.Signers) != 0 || len(participation.Absentees) != 0 {
+		t.Fatalf("Expected no participation, got %v", participation)
+	}
+}
+
+func TestVerifyAggregatedCommittedSeals(t *testing.T) {
+	_, engine := newBlockChain(1)
+	other, _ := crypto.GenerateKey()
+	otherBLS, err := tendermintCrypto.DeriveBLSKey(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	genesis := &types.Header{Number: big.NewInt(0)}
+	parent := &types.Header{
+		ParentHash: genesis.Hash(),
+		Number:     big.NewInt(1),
+		Committee: types.Committee{
+			{Address: engine.Address(), VotingPower: big.NewInt(3), ConsensusKey: engine.ConsensusKey()},
+			{Address: crypto.PubkeyToAddress(other.PublicKey), VotingPower: big.NewInt(1), ConsensusKey: otherBLS.ConsensusKey()},
+		},
+	}
+	header := &types.Header{ParentHash: parent.Hash(), Number: big.NewInt(2), Round: 1}
+	headerSeal := tendermintCore.PrepareCommittedSeal(header.Hash(), 1, header.Number)
+	sign := func(signers map[common.Address][]byte) types.AggregatedSeal {
+		seal, err := tendermintCrypto.AggregateSeals(parent.Committee, signers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return seal
+	}
+	ownSeal, err := engine.SignCommittedSeal(headerSeal, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("quorum of seals", func(t *testing.T) {
+		header.AggregatedSeal = sign(map[common.Address][]byte{engine.Address(): ownSeal})
+		assertNilError(t, engine.verifyCommittedSeals(header, parent))
+	})
+
+	t.Run("no quorum", func(t *testing.T) {
+		header.AggregatedSeal = sign(map[common.Address][]byte{parent.Committee[1].Address: otherBLS.Sign(headerSeal)})
+		assertError(t, types.ErrInvalidCommittedSeals, engine.verifyCommittedSeals(header, parent))
+	})
+
+	t.Run("no seals", func(t *testing.T) {
+		header.AggregatedSeal = types.AggregatedSeal{}
+		assertError(t, types.ErrEmptyCommittedSeals, engine.verifyCommittedSeals(header, parent))
+	})
+
+	t.Run("secp256k1 seals are rejected", func(t *testing.T) {
+		seal, err := engine.Sign(headerSeal)
+		if err != nil {
+			t.Fatal(err)
+		}
+		header.AggregatedSeal = sign(map[common.Address][]byte{engine.Address(): ownSeal})
+		header.CommittedSeals = [][]byte{seal}
+		assertError(t, types.ErrInvalidCommittedSeals, engine.verifyCommittedSeals(header, parent))
+		header.CommittedSeals = nil
+	})
+
+	t.Run("aggregated seals are rejected without consensus keys", func(t *testing.T) {
+		legacyParent := types.CopyHeader(parent)
+		legacyParent.Committee[1].ConsensusKey = nil
+		header.AggregatedSeal = sign(map[common.Address][]byte{engine.Address(): ownSeal})
+		assertError(t, types.ErrEmptyCommittedSeals, engine.verifyCommittedSeals(header, legacyParent))
+	})
+
+	t.Run("past aggregated seals", func(t *testing.T) {
+		header.AggregatedSeal = sign(map[common.Address][]byte{engine.Address(): ownSeal})
+		child := &types.Header{ParentHash: header.Hash(), Number: big.NewInt(3), PastAggregatedSeal: header.AggregatedSeal}
+		assertNilError(t, engine.verifyPastCommittedSeals(child, header, parent))
+
+		participation, err := headerParticipation(testHeaderChain{genesis, parent, header}, child)
+		assertNilError(t, err)
+		if len(participation.Signers) != 1 || participation.Signers[0] != engine.Address() {
+			t.Fatalf("Expected signers %v, got %v", engine.Address(), participation.Signers)
+		}
+		if len(participation.Absentees) != 1 || participation.Absentees[0] != parent.Committee[1].Address {
+			t.Fatalf("Expected absentees %v, got %v", parent.Committee[1].Address, participation.Absentees)
+		}
+
+		child.PastAggregatedSeal = types.AggregatedSeal{}
+		assertError(t, errInvalidPastCommittedSeals, engine.verifyPastCommittedSeals(child, header, parent))
+	})
+}
+
+func TestVerifySignerRandomBeacon(t *testing.T) {
+	_, engine := newBlockChain(1)
+	engine.config.ProposerPolicy = config.RandomBeacon
+	defer func() { engine.config.ProposerPolicy = config.WeightedRandomSampling }()
+
+	parent := &types.Header{
+		Number:    big.NewInt(1),
+		Committee: types.Committee{{Address: engine.Address(), VotingPower: big.NewInt(1)}},
+	}
+	newHeader := func(beacon func(*types.Header) []byte) *types.Header {
+		header := &types.Header{ParentHash: parent.Hash(), Number: big.NewInt(2), Coinbase: engine.Address()}
+		header.Beacon = beacon(header)
+		if err := tendermintCrypto.SignHeader(header, engine.privateKey); err != nil {
+			t.Fatal(err)
+		}
+		return header
+	}
+
+	t.Run("seed carried over without a consensus key", func(t *testing.T) {
+		header := newHeader(func(h *types.Header) []byte {
+			beacon, err := engine.signBeacon(h, parent)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return beacon
+		})
+		if !bytes.Equal(header.Beacon, tendermintCore.BeaconSeed(parent).Bytes()) {
+			t.Fatalf("Expected %x, got %x", tendermintCore.BeaconSeed(parent).Bytes(), header.Beacon)
+		}
+		assertNilError(t, engine.verifySigner(header, parent))
+	})
+
+	t.Run("missing beacon", func(t *testing.T) {
+		header := newHeader(func(*types.Header) []byte { return nil })
+		assertError(t, tendermintCore.ErrInvalidBeacon, engine.verifySigner(header, parent))
+	})
+
+	t.Run("secp256k1 beacon", func(t *testing.T) {
+		header := newHeader(func(h *types.Header) []byte {
+			beacon, err := engine.Sign(tendermintCore.PrepareBeacon(tendermintCore.BeaconSeed(parent), h.Number))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return beacon
+		})
+		assertError(t, tendermintCore.ErrInvalidBeacon, engine.verifySigner(header, parent))
+	})
+
+	t.Run("wrong proposer", func(t *testing.T) {
+		other := &types.Header{
+			Number: big.NewInt(1),
+			Committee: types.Committee{
+				{Address: engine.Address(), VotingPower: big.NewInt(1)},
+				{Address: common.HexToAddress("0x1"), VotingPower: big.NewInt(1)},
+			},
+		}
+		// find a parent for which the member isn't the proposer of round 0.
+		for tendermintCore.BeaconProposer(other.Committee, tendermintCore.BeaconSeed(other), big.NewInt(2), 0).Address == engine.Address() {
+			other.Time++
+		}
+		header := &types.Header{ParentHash: other.Hash(), Number: big.NewInt(2), Coinbase: engine.Address(), Beacon: tendermintCore.BeaconSeed(other).Bytes()}
+		if err := tendermintCrypto.SignHeader(header, engine.privateKey); err != nil {
+			t.Fatal(err)
+		}
+		assertError(t, errInvalidProposer, engine.verifySigner(header, other))
+
+		// the block can be committed at a later round the member proposes.
+		for header.Round = 1; tendermintCore.BeaconProposer(other.Committee, tendermintCore.BeaconSeed(other), big.NewInt(2), int64(header.Round)).Address != engine.Address(); header.Round++ {
+		}
+		if err := tendermintCrypto.SignHeader(header, engine.privateKey); err != nil {
+			t.Fatal(err)
+		}
+		assertNilError(t, engine.verifySigner(header, other))
+	})
+
+	t.Run("bls beacon once a consensus key is registered", func(t *testing.T) {
+		blsParent := &types.Header{
+			Number: big.NewInt(1),
+			Committee: types.Committee{{
+				Address:      engine.Address(),
+				VotingPower:  big.NewInt(1),
+				ConsensusKey: engine.ConsensusKey(),
+			}},
+		}
+		header := &types.Header{ParentHash: blsParent.Hash(), Number: big.NewInt(2), Coinbase: engine.Address()}
+		beacon, err := engine.signBeacon(header, blsParent)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(beacon) != tendermintCrypto.BLSSignatureLength {
+			t.Fatalf("Expected a bls signature, got %x", beacon)
+		}
+		header.Beacon = beacon
+		if err := tendermintCrypto.SignHeader(header, engine.privateKey); err != nil {
+			t.Fatal(err)
+		}
+		assertNilError(t, engine.verifySigner(header, blsParent))
+	})
+}
+
+func TestVerifySignerWeightedRoundRobin(t *testing.T) {
+	_, engine := newBlockChain(1)
+	engine.config.ProposerPolicy = config.WeightedRoundRobin
+	defer func() { engine.config.ProposerPolicy = config.WeightedRandomSampling }()
+
+	other, _ := generatePrivateKey()
+	parent := &types.Header{
+		Number: big.NewInt(0),
+		Committee: types.Committee{
+			{Address: engine.Address(), VotingPower: big.NewInt(1)},
+			{Address: crypto.PubkeyToAddress(other.PublicKey), VotingPower: big.NewInt(2)},
+		},
+	}
+	sign := func(header *types.Header) *types.Header {
+		if err := tendermintCrypto.SignHeader(header, engine.privateKey); err != nil {
+			t.Fatal(err)
+		}
+		return header
+	}
+	newHeader := func(round uint64) *types.Header {
+		return sign(&types.Header{
+			ParentHash:         parent.Hash(),
+			Number:             big.NewInt(1),
+			Coinbase:           engine.Address(),
+			MixDigest:          types.BFTDigest,
+			Round:              round,
+			ProposerPriorities: tendermintCore.NextProposerPriorities(parent, nil),
+		})
+	}
+
+	t.Run("proposer of the round", func(t *testing.T) {
+		// the other member proposes at round 0 having the most voting power
+		assertError(t, errInvalidProposer, engine.verifySigner(newHeader(0), parent))
+		assertNilError(t, engine.verifySigner(newHeader(1), parent))
+	})
+
+	t.Run("proposer of a previous round", func(t *testing.T) {
+		assertNilError(t, engine.verifySigner(newHeader(2), parent))
+	})
+
+	t.Run("priorities", func(t *testing.T) {
+		header := newHeader(1)
+		assertNilError(t, verifyProposerPriorities(header, parent, nil))
+
+		header.ProposerPriorities[0] = big.NewInt(5)
+		assertError(t, errInvalidProposerPriorities, verifyProposerPriorities(header, parent, nil))
+
+		header.ProposerPriorities = header.ProposerPriorities[:1]
+		assertError(t, errInvalidProposerPriorities, verifyProposerPriorities(header, parent, nil))
+		assertError(t, errInvalidProposerPriorities, engine.verifySigner(sign(header), parent))
+	})
+}
+
+// testHeaderChain is a chain of headers indexed by number.
+type testHeaderChain []*types.Header
+
+func (c testHeaderChain) Config() *params.ChainConfig { return params.TestChainConfig }
+
+func (c testHeaderChain) CurrentHeader() *types.Header { return c[len(c)-1] }
+
+func (c testHeaderChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if h := c.GetHeaderByNumber(number); h != nil && h.Hash() == hash {
+		return h
+	}
+	return nil
+}
+
+func (c testHeaderChain) GetHeaderByNumber(number uint64) *types.Header {
+	if number >= uint64(len(c)) {
+		return nil
+	}
+	return c[number]
+}
+
+func (c testHeaderChain) GetHeaderByHash(hash common.Hash) *types.Header {
+	for _, h := range c {
+		if h != nil && h.Hash() == hash {
+			return h
+		}
+	}
+	return nil
+}
+
 /* The logic of this needs to change with respect of Autonity contact */
 func TestVerifyHeaders(t *testing.T) {
 	chain, engine := newBlockChain(1)
@@ -415,6 +775,102 @@ OUT3:
 	}
 }
 
+func TestVerifyLightHeaders(t *testing.T) {
+	_, engine := newBlockChain(1)
+	engine.SetLightMode()
+	defer func() { engine.light = false }()
+
+	keys := make([]*ecdsa.PrivateKey, 2)
+	committees := make([]types.Committee, 2)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		committees[i] = types.Committee{{Address: crypto.PubkeyToAddress(keys[i].PublicKey), VotingPower: big.NewInt(1)}}
+	}
+	sealHeader := func(header *types.Header, key *ecdsa.PrivateKey) {
+		seal, err := crypto.Sign(crypto.Keccak256(tendermintCore.PrepareCommittedSeal(header.Hash(), int64(header.Round), header.Number)), key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		header.CommittedSeals = [][]byte{seal}
+	}
+	newHeader := func(parent *types.Header, committee types.Committee, key *ecdsa.PrivateKey) *types.Header {
+		header := &types.Header{
+			ParentHash: parent.Hash(),
+			Number:     new(big.Int).Add(parent.Number, common.Big1),
+			Time:       parent.Time + 1,
+			Coinbase:   crypto.PubkeyToAddress(key.PublicKey),
+			Committee:  committee,
+			MixDigest:  types.BFTDigest,
+			Difficulty: defaultDifficulty,
+			UncleHash:  nilUncleHash,
+		}
+		if err := tendermintCrypto.SignHeader(header, key); err != nil {
+			t.Fatal(err)
+		}
+		sealHeader(header, key)
+		return header
+	}
+
+	// the light client starts from a trusted checkpoint, the older headers are
+	// unknown.
+	checkpoint := &types.Header{Number: big.NewInt(100), Time: 1, Committee: committees[0]}
+	chain := make(testHeaderChain, 101)
+	chain[100] = checkpoint
+	// the committee changes at the third header, it is sealed by the previous one.
+	headers := []*types.Header{newHeader(checkpoint, committees[0], keys[0])}
+	headers = append(headers, newHeader(headers[0], committees[0], keys[0]))
+	headers = append(headers, newHeader(headers[1], committees[1], keys[0]))
+	headers = append(headers, newHeader(headers[2], committees[1], keys[1]))
+	headers = append(headers, newHeader(headers[3], committees[1], keys[1]))
+	now = func() time.Time { return time.Unix(int64(headers[len(headers)-1].Time), 0) }
+	defer func() { now = time.Now }()
+
+	verify := func(seals []bool) error {
+		abort, results := engine.VerifyHeaders(chain, headers, seals)
+		defer close(abort)
+		for range headers {
+			if err := <-results; err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	// tamper replaces the committed seals of the header by the seal of a key
+	// outside of the committee, its hash is unchanged.
+	tamper := func(i int, key *ecdsa.PrivateKey) func() {
+		seals := headers[i].CommittedSeals
+		sealHeader(headers[i], key)
+		return func() { headers[i].CommittedSeals = seals }
+	}
+
+	t.Run("committee transitions are followed", func(t *testing.T) {
+		assertNilError(t, verify(make([]bool, len(headers))))
+		assertNilError(t, verify([]bool{true, true, true, true, true}))
+	})
+
+	t.Run("headers sealed by the same committee are skipped", func(t *testing.T) {
+		defer tamper(0, keys[1])()
+		assertNilError(t, verify(make([]bool, len(headers))))
+		assertError(t, types.ErrInvalidCommittedSeals, verify([]bool{true, false, false, false, false}))
+	})
+
+	t.Run("committee transition is verified", func(t *testing.T) {
+		defer tamper(2, keys[1])()
+		assertError(t, types.ErrInvalidCommittedSeals, verify(make([]bool, len(headers))))
+	})
+
+	t.Run("last header is verified", func(t *testing.T) {
+		defer tamper(4, keys[0])()
+		assertError(t, types.ErrInvalidCommittedSeals, verify(make([]bool, len(headers))))
+	})
+
+	t.Run("full nodes need the older headers", func(t *testing.T) {
+		engine.light = false
+		defer func() { engine.light = true }()
+		assertError(t, consensus.ErrUnknownAncestor, verify(make([]bool, len(headers))))
+	})
+}
+
 func TestWriteCommittedSeals(t *testing.T) {
 
 	expectedCommittedSeal := append([]byte{1, 2, 3}, bytes.Repeat([]byte{0x00}, types.BFTExtraSeal-3)...)
@@ -0,0 +1,168 @@
+package backend
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+
+	"github.com/clearmatics/autonity/autonity"
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/consensus"
+	tendermintCore "github.com/clearmatics/autonity/consensus/tendermint/core"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/rlp"
+)
+
+const (
+	// maxEvidencePerBlock is the maximum number of evidence a block can hold.
+	maxEvidencePerBlock = 16
+	// maxEvidenceAge is the number of blocks after which a misbehaviour can no longer be punished.
+	maxEvidenceAge = 1024
+)
+
+// errInvalidEvidence is returned if a block holds evidence which doesn't prove
+// the misbehaviour of a committee member.
+var errInvalidEvidence = errors.New("invalid evidence")
+
+func decodeEvidence(enc []byte) (*tendermintCore.Evidence, error) {
+	ev := new(tendermintCore.Evidence)
+	if err := rlp.DecodeBytes(enc, ev); err != nil {
+		return nil, err
+	}
+	return ev, nil
+}
+
+// verifyEvidence checks that every evidence held by the header proves a
+// misbehaviour which hasn't expired yet. getHeader returns the header of the
+// given number from the chain the header belongs to.
+func verifyEvidence(header *types.Header, getHeader func(number uint64) *types.Header) error {
+	if len(header.Evidence) > maxEvidencePerBlock {
+		return errInvalidEvidence
+	}
+	seen := make(map[common.Hash]struct{}, len(header.Evidence))
+	for _, enc := range header.Evidence {
+		ev, err := decodeEvidence(enc)
+		if err != nil {
+			return errInvalidEvidence
+		}
+		if _, ok := seen[ev.Hash()]; ok {
+			return errInvalidEvidence
+		}
+		seen[ev.Hash()] = struct{}{}
+
+		_, height, err := ev.Offender()
+		if err != nil || !isEvidenceHeightValid(height.Uint64(), header.Number.Uint64()) {
+			return errInvalidEvidence
+		}
+		parent := getHeader(height.Uint64() - 1)
+		if parent == nil {
+			return errUnknownBlock
+		}
+		if _, err := ev.Verify(parent); err != nil {
+			return errInvalidEvidence
+		}
+	}
+	return nil
+}
+
+// isEvidenceHeightValid reports whether misbehaviours at the given height can
+// be punished by the block of the given number.
+func isEvidenceHeightValid(height, number uint64) bool {
+	return height > 0 && height <= number && number-height <= maxEvidenceAge
+}
+
+// headerMisbehaviours returns the misbehaviours proved by the evidence of the
+// header, which is expected to have been verified.
+func headerMisbehaviours(header *types.Header) ([]autonity.Misbehaviour, error) {
+	if len(header.Evidence) == 0 {
+		return nil, nil
+	}
+	misbehaviours := make([]autonity.Misbehaviour, 0, len(header.Evidence))
+	for _, enc := range header.Evidence {
+		ev, err := decodeEvidence(enc)
+		if err != nil {
+			return nil, errInvalidEvidence
+		}
+		msg, _, err := ev.Messages()
+		if err != nil {
+			return nil, errInvalidEvidence
+		}
+		// decoding was successful, the height and round are known
+		height, _ := msg.Height()
+		round, _ := msg.Round()
+		misbehaviours = append(misbehaviours, autonity.Misbehaviour{
+			Offender: msg.Address,
+			Height:   height,
+			Round:    big.NewInt(round),
+			Step:     uint8(msg.Code),
+			Evidence: ev.Hash(),
+		})
+	}
+	return misbehaviours, nil
+}
+
+// pendingEvidence returns the evidence recorded by the core which can be
+// included in the block being prepared. Evidence which has expired, is invalid
+// or has already been included in the chain is removed from the database.
+func (sb *Backend) pendingEvidence(chain consensus.ChainHeaderReader, header *types.Header) [][]byte {
+	var pending [][]byte
+	number := header.Number.Uint64()
+	// the block couldn't be finalized by a contract unable to punish the
+	// offenders, the evidence is kept until the contract gets upgraded.
+	if _, ok := sb.blockchain.GetAutonityContract().ABIAt(number - 1).Methods["slash"]; !ok {
+		return nil
+	}
+	for _, ev := range tendermintCore.ReadAllEvidence(sb.db) {
+		if len(pending) == maxEvidencePerBlock {
+			break
+		}
+		_, height, err := ev.Offender()
+		if err != nil {
+			sb.deleteEvidence(ev)
+			continue
+		}
+		if height.Uint64() > number {
+			// recorded while syncing, it will be included later on.
+			continue
+		}
+
+		enc, err := rlp.EncodeToBytes(ev)
+		if err != nil || !isEvidenceHeightValid(height.Uint64(), number) || isEvidenceIncluded(chain, enc, height.Uint64(), number) {
+			sb.deleteEvidence(ev)
+			continue
+		}
+		parent := chain.GetHeaderByNumber(height.Uint64() - 1)
+		if parent == nil {
+			continue
+		}
+		if _, err := ev.Verify(parent); err != nil {
+			sb.deleteEvidence(ev)
+			continue
+		}
+		pending = append(pending, enc)
+	}
+	return pending
+}
+
+// isEvidenceIncluded reports whether a block between the evidence height and
+// the one being prepared already holds the evidence.
+func isEvidenceIncluded(chain consensus.ChainHeaderReader, enc []byte, height, number uint64) bool {
+	for n := height; n < number; n++ {
+		h := chain.GetHeaderByNumber(n)
+		if h == nil {
+			continue
+		}
+		for _, included := range h.Evidence {
+			if bytes.Equal(included, enc) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (sb *Backend) deleteEvidence(ev *tendermintCore.Evidence) {
+	if err := tendermintCore.DeleteEvidence(sb.db, ev.Hash()); err != nil {
+		sb.logger.Error("Failed to delete evidence", "hash", ev.Hash(), "err", err)
+	}
+}
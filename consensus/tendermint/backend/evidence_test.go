@@ -0,0 +1,110 @@
+package backend
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/clearmatics/autonity/common"
+	tendermintCore "github.com/clearmatics/autonity/consensus/tendermint/core"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/crypto"
+	"github.com/clearmatics/autonity/rlp"
+)
+
+func TestVerifyEvidence(t *testing.T) {
+	key, _ := generatePrivateKey()
+	offender := crypto.PubkeyToAddress(key.PublicKey)
+	parent := &types.Header{
+		Number:    big.NewInt(4),
+		Committee: types.Committee{{Address: offender, VotingPower: big.NewInt(1)}},
+	}
+	getHeader := func(number uint64) *types.Header {
+		if number == parent.Number.Uint64() {
+			return parent
+		}
+		return nil
+	}
+	ev := newTestEvidence(t, key, big.NewInt(5), common.HexToHash("0x1"), common.HexToHash("0x2"))
+
+	t.Run("valid evidence", func(t *testing.T) {
+		header := &types.Header{Number: big.NewInt(6), Evidence: [][]byte{ev}}
+		if err := verifyEvidence(header, getHeader); err != nil {
+			t.Fatalf("Expected nil, got %v", err)
+		}
+		misbehaviours, err := headerMisbehaviours(header)
+		if err != nil {
+			t.Fatalf("Expected nil, got %v", err)
+		}
+		if len(misbehaviours) != 1 || misbehaviours[0].Offender != offender {
+			t.Fatalf("Expected misbehaviour of %v, got %v", offender, misbehaviours)
+		}
+		m := misbehaviours[0]
+		if m.Height.Cmp(big.NewInt(5)) != 0 || m.Round.Sign() != 0 || uint64(m.Step) != tendermintCore.MsgPrevote {
+			t.Fatalf("Expected prevote fault at height 5 round 0, got %v", m)
+		}
+	})
+
+	t.Run("duplicated evidence", func(t *testing.T) {
+		header := &types.Header{Number: big.NewInt(6), Evidence: [][]byte{ev, ev}}
+		assertError(t, errInvalidEvidence, verifyEvidence(header, getHeader))
+	})
+
+	t.Run("evidence for a future height", func(t *testing.T) {
+		header := &types.Header{Number: big.NewInt(4), Evidence: [][]byte{ev}}
+		assertError(t, errInvalidEvidence, verifyEvidence(header, getHeader))
+	})
+
+	t.Run("expired evidence", func(t *testing.T) {
+		header := &types.Header{Number: big.NewInt(5 + maxEvidenceAge + 1), Evidence: [][]byte{ev}}
+		assertError(t, errInvalidEvidence, verifyEvidence(header, getHeader))
+	})
+
+	t.Run("unknown parent", func(t *testing.T) {
+		header := &types.Header{Number: big.NewInt(6), Evidence: [][]byte{ev}}
+		err := verifyEvidence(header, func(uint64) *types.Header { return nil })
+		assertError(t, errUnknownBlock, err)
+	})
+
+	t.Run("messages not conflicting", func(t *testing.T) {
+		same := newTestEvidence(t, key, big.NewInt(5), common.HexToHash("0x1"), common.HexToHash("0x1"))
+		header := &types.Header{Number: big.NewInt(6), Evidence: [][]byte{same}}
+		assertError(t, errInvalidEvidence, verifyEvidence(header, getHeader))
+	})
+
+	t.Run("too many evidence", func(t *testing.T) {
+		header := &types.Header{Number: big.NewInt(6)}
+		for i := 0; i <= maxEvidencePerBlock; i++ {
+			header.Evidence = append(header.Evidence, ev)
+		}
+		assertError(t, errInvalidEvidence, verifyEvidence(header, getHeader))
+	})
+}
+
+func newTestEvidence(t *testing.T, key *ecdsa.PrivateKey, height *big.Int, first, second common.Hash) []byte {
+	prevote := func(hash common.Hash) *tendermintCore.Message {
+		encodedVote, err := tendermintCore.Encode(&tendermintCore.Vote{Round: 0, Height: height, ProposedBlockHash: hash})
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg := &tendermintCore.Message{
+			Code:    tendermintCore.MsgPrevote,
+			Msg:     encodedVote,
+			Address: crypto.PubkeyToAddress(key.PublicKey),
+		}
+		data, err := msg.PayloadNoSig()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg.Signature, err = crypto.Sign(crypto.Keccak256(data), key); err != nil {
+			t.Fatal(err)
+		}
+		return msg
+	}
+
+	enc, err := rlp.EncodeToBytes(tendermintCore.NewEvidence(prevote(first), prevote(second)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return enc
+}
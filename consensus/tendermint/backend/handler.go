@@ -22,9 +22,12 @@ import (
 	"errors"
 	"github.com/clearmatics/autonity/common"
 	"github.com/clearmatics/autonity/consensus"
+	tendermintCore "github.com/clearmatics/autonity/consensus/tendermint/core"
+	tendermintCrypto "github.com/clearmatics/autonity/consensus/tendermint/crypto"
 	"github.com/clearmatics/autonity/consensus/tendermint/events"
 	"github.com/clearmatics/autonity/core/types"
 	"github.com/clearmatics/autonity/p2p"
+	"github.com/clearmatics/autonity/rlp"
 	"github.com/hashicorp/golang-lru"
 	"io"
 )
@@ -32,8 +35,29 @@ import (
 const (
 	tendermintMsg     = 0x11
 	tendermintSyncMsg = 0x12
+	// tendermintSyncRequestMsg carries a sync request advertising the
+	// messages held, tendermintSyncMsg asks for all of them.
+	tendermintSyncRequestMsg = 0x13
+	// tendermintRelayMsg carries a consensus message relayed through the
+	// nodes outside of the committee along with its hops left.
+	tendermintRelayMsg = 0x14
+	// tendermintTxsRequestMsg asks for the transactions missing to rebuild
+	// the block of a compact proposal, tendermintTxsMsg carries them.
+	tendermintTxsRequestMsg = 0x15
+	tendermintTxsMsg        = 0x16
+
+	// maxRelayHops is the number of nodes outside of the committee a
+	// consensus message can be relayed through.
+	maxRelayHops = 3
 )
 
+// relayMsg is a consensus message relayed to a peer, the peer forwards it to
+// the nodes outside of the committee only if TTL isn't 0.
+type relayMsg struct {
+	Payload []byte
+	TTL     uint64
+}
+
 type UnhandledMsg struct {
 	addr common.Address
 	msg  p2p.Msg
@@ -46,7 +70,7 @@ var (
 
 // Protocol implements consensus.Handler.Protocol
 func (sb *Backend) Protocol() (protocolName string, extraMsgCodes uint64) {
-	return "tendermint", 2 //nolint
+	return "tendermint", 6 //nolint
 }
 
 func (sb *Backend) HandleUnhandledMsgs(ctx context.Context) {
@@ -68,16 +92,24 @@ func (sb *Backend) HandleUnhandledMsgs(ctx context.Context) {
 
 // HandleMsg implements consensus.Handler.HandleMsg
 func (sb *Backend) HandleMsg(addr common.Address, msg p2p.Msg) (bool, error) {
-	if msg.Code != tendermintMsg && msg.Code != tendermintSyncMsg {
+	switch msg.Code {
+	case tendermintMsg, tendermintRelayMsg, tendermintSyncMsg, tendermintSyncRequestMsg, tendermintTxsRequestMsg, tendermintTxsMsg:
+	default:
 		return false, nil
 	}
 
 	sb.coreMu.Lock()
 	defer sb.coreMu.Unlock()
 
+	if sb.scores.banned(addr) {
+		return true, errBannedPeer
+	}
+
 	switch msg.Code {
-	case tendermintMsg:
-		if !sb.coreStarted {
+	case tendermintMsg, tendermintRelayMsg:
+		// the relays verify the messages themselves when the core isn't
+		// running.
+		if !sb.coreStarted && !sb.relay {
 			buffer := new(bytes.Buffer)
 			if _, err := io.Copy(buffer, msg.Payload); err != nil {
 				return true, errDecodeFailed
@@ -88,14 +120,26 @@ func (sb *Backend) HandleMsg(addr common.Address, msg p2p.Msg) (bool, error) {
 			return true, nil //return nil to avoid shutting down connection during block sync.
 		}
 
+		if !sb.scores.allowMsg(addr) {
+			sb.penalise(addr, throttled)
+			return true, nil
+		}
+
 		var data []byte
-		if err := msg.Decode(&data); err != nil {
+		hops := uint64(maxRelayHops)
+		if msg.Code == tendermintRelayMsg {
+			var relayed relayMsg
+			if err := msg.Decode(&relayed); err != nil {
+				return true, errDecodeFailed
+			}
+			data, hops = relayed.Payload, relayed.TTL
+		} else if err := msg.Decode(&data); err != nil {
 			return true, errDecodeFailed
 		}
 
 		hash := types.RLPHash(data)
 
-		// Mark peer's message
+		// Mark peer's message, true tells that the peer sent it
 		ms, ok := sb.recentMessages.Get(addr)
 		var m *lru.ARCCache
 		if ok {
@@ -104,24 +148,55 @@ func (sb *Backend) HandleMsg(addr common.Address, msg p2p.Msg) (bool, error) {
 			m, _ = lru.NewARC(inmemoryMessages)
 			sb.recentMessages.Add(addr, m)
 		}
+		if sent, ok := m.Peek(hash); ok && sent.(bool) {
+			sb.penalise(addr, duplicateMessage)
+		}
 		m.Add(hash, true)
 
 		// Mark self known message
 		if _, ok := sb.knownMessages.Get(hash); ok {
 			return true, nil
 		}
-		sb.knownMessages.Add(hash, true)
+		sb.knownMessages.Add(hash, hops)
+		sb.scores.received(hash, addr)
 
+		if !sb.coreStarted {
+			sb.forward(data)
+			return true, nil
+		}
 		sb.postEvent(events.MessageEvent{
 			Payload: data,
 		})
-	case tendermintSyncMsg:
+	case tendermintSyncMsg, tendermintSyncRequestMsg:
 		if !sb.coreStarted {
 			sb.logger.Info("Sync message received but core not running")
 			return true, nil // we return nil as we don't want to shutdown the connection if core is stopped
 		}
-		sb.logger.Info("Received sync message", "from", addr)
-		sb.postEvent(events.SyncEvent{Addr: addr})
+		if !sb.scores.allowSync(addr) {
+			sb.logger.Debug("Sync message throttled", "from", addr)
+			sb.penalise(addr, throttled)
+			return true, nil
+		}
+		var request rlp.RawValue
+		if msg.Code == tendermintSyncRequestMsg {
+			if err := msg.Decode(&request); err != nil {
+				return true, errDecodeFailed
+			}
+		}
+		sb.logger.Debug("Received sync message", "from", addr)
+		sb.postEvent(events.SyncEvent{Addr: addr, Payload: request})
+	case tendermintTxsRequestMsg, tendermintTxsMsg:
+		if !sb.coreStarted {
+			return true, nil
+		}
+		if !sb.scores.allowMsg(addr) {
+			sb.penalise(addr, throttled)
+			return true, nil
+		}
+		if msg.Code == tendermintTxsRequestMsg {
+			return true, sb.handleTxsRequest(addr, msg)
+		}
+		return true, sb.handleTxsResponse(addr, msg)
 	default:
 		return false, nil
 	}
@@ -129,6 +204,59 @@ func (sb *Backend) HandleMsg(addr common.Address, msg p2p.Msg) (bool, error) {
 	return true, nil
 }
 
+// forward verifies a consensus message on behalf of the core which isn't
+// running and forwards it if it is of the current height.
+func (sb *Backend) forward(payload []byte) {
+	header := sb.currentBlock().Header()
+	ok, err := tendermintCore.VerifyMessage(payload, header)
+	if err != nil {
+		sb.logger.Debug("Failed to verify relayed message", "err", err)
+		sb.ReportInvalidMessage(payload, err)
+		return
+	}
+	if ok {
+		sb.Gossip(context.Background(), header.Committee, payload)
+	}
+}
+
+// ReportInvalidMessage implements tendermint.Backend.ReportInvalidMessage
+func (sb *Backend) ReportInvalidMessage(payload []byte, err error) {
+	m := invalidMessage
+	if err == tendermintCrypto.ErrUnauthorizedAddress {
+		m = notCommittee
+	}
+	sb.penaliseSender(payload, m)
+}
+
+// ReportStaleMessage implements tendermint.Backend.ReportStaleMessage
+func (sb *Backend) ReportStaleMessage(payload []byte) {
+	sb.penaliseSender(payload, staleMessage)
+}
+
+func (sb *Backend) penaliseSender(payload []byte, m misbehaviour) {
+	// our own messages have no sender
+	if addr, ok := sb.scores.sender(types.RLPHash(payload)); ok {
+		sb.penalise(addr, m)
+	}
+}
+
+// penalise scores the misbehaviour of the peer, the peer is disconnected once
+// it is banned.
+func (sb *Backend) penalise(addr common.Address, m misbehaviour) {
+	if !sb.scores.penalise(addr, m) {
+		return
+	}
+	sb.logger.Warn("Banning misbehaving peer", "peer", addr, "duration", banDuration)
+	if sb.broadcaster != nil {
+		sb.broadcaster.DisconnectPeer(addr)
+	}
+}
+
+// PeerScores returns the scores of the peers which sent consensus messages.
+func (sb *Backend) PeerScores() map[common.Address]PeerScore {
+	return sb.scores.scores()
+}
+
 // SetBroadcaster implements consensus.Handler.SetBroadcaster
 func (sb *Backend) SetBroadcaster(broadcaster consensus.Broadcaster) {
 	sb.broadcaster = broadcaster
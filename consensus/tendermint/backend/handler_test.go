@@ -78,6 +78,7 @@ func TestSynchronisationMessage(t *testing.T) {
 			coreStarted: false,
 			logger:      log.New("backend", "test", "id", 0),
 			eventMux:    eventMux,
+			scores:      newPeerScorer(),
 		}
 		msg := makeMsg(tendermintSyncMsg, []byte{})
 		addr := common.BytesToAddress([]byte("address"))
@@ -99,6 +100,7 @@ func TestSynchronisationMessage(t *testing.T) {
 			coreStarted: true,
 			logger:      log.New("backend", "test", "id", 0),
 			eventMux:    eventMux,
+			scores:      newPeerScorer(),
 		}
 		msg := makeMsg(tendermintSyncMsg, []byte{})
 		addr := common.BytesToAddress([]byte("address"))
@@ -121,8 +123,8 @@ func TestProtocol(t *testing.T) {
 	if name != "tendermint" {
 		t.Fatalf("expected 'tendermint', got %v", name)
 	}
-	if code != 2 {
-		t.Fatalf("expected 2, got %v", code)
+	if code != 6 {
+		t.Fatalf("expected 6, got %v", code)
 	}
 }
 
@@ -0,0 +1,80 @@
+package backend
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/consensus"
+	tendermintCore "github.com/clearmatics/autonity/consensus/tendermint/core"
+)
+
+// DoubleSignEngine sends two conflicting prevotes for the first round of every
+// height starting from fromHeight, so that honest nodes record evidence
+// against it.
+type DoubleSignEngine struct {
+	*testing.T
+	*Backend
+	fromHeight uint64
+}
+
+func NewDoubleSigner(t *testing.T, engine consensus.Engine, fromHeight uint64) *DoubleSignEngine {
+	basicEngine, ok := engine.(*Backend)
+	if !ok {
+		panic("*Backend type is expected")
+	}
+	return &DoubleSignEngine{
+		T:          t,
+		Backend:    basicEngine,
+		fromHeight: fromHeight,
+	}
+}
+
+func (m *DoubleSignEngine) NewChainHead() error {
+	if err := m.Backend.NewChainHead(); err != nil {
+		return err
+	}
+
+	lastBlock, _ := m.Backend.LastCommittedProposal()
+	height := new(big.Int).Add(lastBlock.Number(), common.Big1)
+	if height.Uint64() < m.fromHeight {
+		return nil
+	}
+
+	committee := lastBlock.Header().Committee
+	for _, hash := range []common.Hash{lastBlock.Hash(), {}} {
+		payload, err := m.signedPrevote(height, hash)
+		if err != nil {
+			m.Errorf("cant create the conflicting prevote: %v", err)
+			return nil
+		}
+		m.Backend.Gossip(context.Background(), committee, payload)
+	}
+	return nil
+}
+
+func (m *DoubleSignEngine) signedPrevote(height *big.Int, hash common.Hash) ([]byte, error) {
+	encodedVote, err := tendermintCore.Encode(&tendermintCore.Vote{
+		Round:             0,
+		Height:            height,
+		ProposedBlockHash: hash,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &tendermintCore.Message{
+		Code:    tendermintCore.MsgPrevote,
+		Msg:     encodedVote,
+		Address: m.Backend.Address(),
+	}
+	data, err := msg.PayloadNoSig()
+	if err != nil {
+		return nil, err
+	}
+	if msg.Signature, err = m.Backend.Sign(data); err != nil {
+		return nil, err
+	}
+	return msg.Payload(), nil
+}
@@ -58,7 +58,7 @@ func (m *ModifyCommitteeEngine) FinalizeAndAssemble(chain consensus.ChainReader,
 		return block, nil
 	}
 
-	if m.address != header.Coinbase {
+	if m.Address() != header.Coinbase {
 		// if the malicious validator is a proposer
 		return block, nil
 	}
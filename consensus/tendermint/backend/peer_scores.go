@@ -0,0 +1,192 @@
+package backend
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/clearmatics/autonity/common"
+	lru "github.com/hashicorp/golang-lru"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// the penalties added to the score of a peer for each misbehaviour, late
+	// votes and the messages resent on sync requests are expected from honest
+	// peers hence the small penalties of stale and duplicate messages. The
+	// score decays by scoreDecay every second and the peer is banned for
+	// banDuration once its score reaches banScore.
+	invalidMessagePenalty = 50
+	notCommitteePenalty   = 20
+	throttledPenalty      = 5
+	staleMessagePenalty   = 0.5
+	duplicatePenalty      = 0.1
+	scoreDecay            = 1
+	banScore              = 100
+	banDuration           = 10 * time.Minute
+
+	// a peer can send msgRate consensus messages per second in bursts of
+	// msgBurst messages, enough to answer a sync request, and a sync request
+	// every syncInterval, about twice the period of the core.
+	msgRate      = 500
+	msgBurst     = 5000
+	syncInterval = time.Second
+	syncBurst    = 5
+)
+
+var (
+	// errBannedPeer is returned for the messages of a banned peer, so that
+	// the connection is torn down.
+	errBannedPeer = errors.New("banned peer")
+)
+
+type misbehaviour int
+
+const (
+	invalidMessage misbehaviour = iota
+	notCommittee
+	staleMessage
+	duplicateMessage
+	throttled
+)
+
+// PeerScore is the record of the misbehaviours of a peer, the higher the score
+// the worse the peer.
+type PeerScore struct {
+	Score           float64    `json:"score"`
+	InvalidMessages uint64     `json:"invalidMessages"`
+	NotCommittee    uint64     `json:"notCommittee"`
+	StaleMessages   uint64     `json:"staleMessages"`
+	Duplicates      uint64     `json:"duplicates"`
+	Throttled       uint64     `json:"throttled"`
+	BannedUntil     *time.Time `json:"bannedUntil,omitempty"`
+}
+
+type peerState struct {
+	PeerScore
+	updated     time.Time
+	msgLimiter  *rate.Limiter
+	syncLimiter *rate.Limiter
+}
+
+// peerScorer rate limits the consensus traffic of the peers and scores their
+// misbehaviours. The messages of a banned peer are refused until the ban
+// expires.
+type peerScorer struct {
+	peers   map[common.Address]*peerState
+	senders *lru.ARCCache // the first peer which sent each message
+	now     func() time.Time
+	mu      sync.Mutex
+}
+
+func newPeerScorer() *peerScorer {
+	senders, _ := lru.NewARC(inmemoryMessages)
+	return &peerScorer{
+		peers:   make(map[common.Address]*peerState),
+		senders: senders,
+		now:     time.Now,
+	}
+}
+
+// peer returns the state of the peer with its score decayed to now, the lock
+// must be held.
+func (s *peerScorer) peer(addr common.Address) *peerState {
+	now := s.now()
+	p, ok := s.peers[addr]
+	if !ok {
+		p = &peerState{
+			updated:     now,
+			msgLimiter:  rate.NewLimiter(msgRate, msgBurst),
+			syncLimiter: rate.NewLimiter(rate.Every(syncInterval), syncBurst),
+		}
+		s.peers[addr] = p
+	}
+	p.Score -= now.Sub(p.updated).Seconds() * scoreDecay
+	if p.Score < 0 {
+		p.Score = 0
+	}
+	p.updated = now
+	if p.BannedUntil != nil && !now.Before(*p.BannedUntil) {
+		p.BannedUntil = nil
+	}
+	return p
+}
+
+// banned checks whether the peer is banned.
+func (s *peerScorer) banned(addr common.Address) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.peer(addr).BannedUntil != nil
+}
+
+// allowMsg checks whether the peer is within its rate of consensus messages.
+func (s *peerScorer) allowMsg(addr common.Address) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.peer(addr).msgLimiter.AllowN(s.now(), 1)
+}
+
+// allowSync checks whether the peer is within its rate of sync requests.
+func (s *peerScorer) allowSync(addr common.Address) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.peer(addr).syncLimiter.AllowN(s.now(), 1)
+}
+
+// penalise adds the penalty of the misbehaviour to the score of the peer, it
+// returns true if the peer has just been banned.
+func (s *peerScorer) penalise(addr common.Address, m misbehaviour) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.peer(addr)
+	switch m {
+	case invalidMessage:
+		p.InvalidMessages++
+		p.Score += invalidMessagePenalty
+	case notCommittee:
+		p.NotCommittee++
+		p.Score += notCommitteePenalty
+	case staleMessage:
+		p.StaleMessages++
+		p.Score += staleMessagePenalty
+	case duplicateMessage:
+		p.Duplicates++
+		p.Score += duplicatePenalty
+	case throttled:
+		p.Throttled++
+		p.Score += throttledPenalty
+	}
+	if p.BannedUntil != nil || p.Score < banScore {
+		return false
+	}
+	until := s.now().Add(banDuration)
+	p.BannedUntil = &until
+	p.Score = 0
+	return true
+}
+
+// received records the peer which sent a new message, the messages failing
+// the verification are reported by the core after the fact.
+func (s *peerScorer) received(hash common.Hash, addr common.Address) {
+	s.senders.Add(hash, addr)
+}
+
+// sender returns the peer which sent the message first.
+func (s *peerScorer) sender(hash common.Hash) (common.Address, bool) {
+	addr, ok := s.senders.Get(hash)
+	if !ok {
+		return common.Address{}, false
+	}
+	return addr.(common.Address), true
+}
+
+// scores returns the scores of the peers known.
+func (s *peerScorer) scores() map[common.Address]PeerScore {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	scores := make(map[common.Address]PeerScore, len(s.peers))
+	for addr := range s.peers {
+		scores[addr] = s.peer(addr).PeerScore
+	}
+	return scores
+}
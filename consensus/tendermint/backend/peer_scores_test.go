@@ -0,0 +1,155 @@
+package backend
+
+import (
+	"testing"
+	"time"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/consensus"
+	tendermintCrypto "github.com/clearmatics/autonity/consensus/tendermint/crypto"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/event"
+	"github.com/clearmatics/autonity/log"
+	"github.com/golang/mock/gomock"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+func TestPeerScorer(t *testing.T) {
+	addr := common.HexToAddress("0x1")
+	now := time.Unix(1000, 0)
+	newScorer := func() *peerScorer {
+		s := newPeerScorer()
+		s.now = func() time.Time { return now }
+		return s
+	}
+
+	t.Run("scores decay", func(t *testing.T) {
+		s := newScorer()
+		s.penalise(addr, notCommittee)
+		s.penalise(addr, staleMessage)
+		score := s.scores()[addr]
+		if score.Score != notCommitteePenalty+staleMessagePenalty || score.NotCommittee != 1 || score.StaleMessages != 1 {
+			t.Fatalf("Expected %v, got %v", notCommitteePenalty+staleMessagePenalty, score.Score)
+		}
+		now = now.Add(10 * time.Second)
+		if expected := notCommitteePenalty + staleMessagePenalty - 10*scoreDecay; s.scores()[addr].Score != expected {
+			t.Fatalf("Expected %v, got %v", expected, s.scores()[addr].Score)
+		}
+		now = now.Add(time.Hour)
+		if s.scores()[addr].Score != 0 {
+			t.Fatalf("Expected 0, got %v", s.scores()[addr].Score)
+		}
+	})
+
+	t.Run("peers are banned for a while", func(t *testing.T) {
+		s := newScorer()
+		if s.penalise(addr, invalidMessage) {
+			t.Fatalf("Expected no ban")
+		}
+		if !s.penalise(addr, invalidMessage) || !s.banned(addr) {
+			t.Fatalf("Expected a ban")
+		}
+		// a banned peer is not banned again
+		if s.penalise(addr, invalidMessage) {
+			t.Fatalf("Expected no ban")
+		}
+		now = now.Add(banDuration)
+		if s.banned(addr) {
+			t.Fatalf("Expected the ban to expire")
+		}
+	})
+
+	t.Run("sync requests are rate limited", func(t *testing.T) {
+		s := newScorer()
+		for i := 0; i < syncBurst; i++ {
+			if !s.allowSync(addr) {
+				t.Fatalf("Expected sync request %d to be allowed", i)
+			}
+		}
+		if s.allowSync(addr) {
+			t.Fatalf("Expected the sync request to be throttled")
+		}
+		now = now.Add(syncInterval)
+		if !s.allowSync(addr) {
+			t.Fatalf("Expected the sync request to be allowed")
+		}
+	})
+}
+
+func TestMisbehavingPeers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	newBackend := func() *Backend {
+		recentMessages, _ := lru.NewARC(inmemoryPeers)
+		knownMessages, _ := lru.NewARC(inmemoryMessages)
+		scores := newPeerScorer()
+		now := time.Now()
+		scores.now = func() time.Time { return now }
+		return &Backend{
+			coreStarted:    true,
+			logger:         log.New("backend", "test", "id", 0),
+			eventMux:       event.NewTypeMuxSilent(log.New("backend", "test", "id", 0)),
+			recentMessages: recentMessages,
+			knownMessages:  knownMessages,
+			scores:         scores,
+		}
+	}
+	addr := common.HexToAddress("0x1")
+
+	t.Run("invalid messages are reported to their sender", func(t *testing.T) {
+		b := newBackend()
+		data := []byte("data")
+		if _, err := b.HandleMsg(addr, makeMsg(tendermintMsg, data)); err != nil {
+			t.Fatalf("Expected <nil>, got %v", err)
+		}
+		b.ReportInvalidMessage(data, tendermintCrypto.ErrUnauthorizedAddress)
+		b.ReportStaleMessage(data)
+		// our own messages have no sender
+		b.ReportInvalidMessage([]byte("own"), errDecodeFailed)
+
+		scores := b.PeerScores()
+		if len(scores) != 1 || scores[addr].NotCommittee != 1 || scores[addr].StaleMessages != 1 || scores[addr].InvalidMessages != 0 {
+			t.Fatalf("Expected the misbehaviours of %v, got %v", addr, scores)
+		}
+	})
+
+	t.Run("duplicates are counted", func(t *testing.T) {
+		b := newBackend()
+		for i := 0; i < 2; i++ {
+			if _, err := b.HandleMsg(addr, makeMsg(tendermintMsg, []byte("data"))); err != nil {
+				t.Fatalf("Expected <nil>, got %v", err)
+			}
+		}
+		// the messages gossiped to the peer are not its duplicates
+		m, _ := b.recentMessages.Get(addr)
+		m.(*lru.ARCCache).Add(types.RLPHash([]byte("gossip")), false)
+		if _, err := b.HandleMsg(addr, makeMsg(tendermintMsg, []byte("gossip"))); err != nil {
+			t.Fatalf("Expected <nil>, got %v", err)
+		}
+		if duplicates := b.PeerScores()[addr].Duplicates; duplicates != 1 {
+			t.Fatalf("Expected 1, got %v", duplicates)
+		}
+	})
+
+	t.Run("banned peers are disconnected", func(t *testing.T) {
+		b := newBackend()
+		broadcaster := consensus.NewMockBroadcaster(ctrl)
+		broadcaster.EXPECT().DisconnectPeer(addr).Times(1)
+		b.SetBroadcaster(broadcaster)
+
+		data := []byte("data")
+		if _, err := b.HandleMsg(addr, makeMsg(tendermintMsg, data)); err != nil {
+			t.Fatalf("Expected <nil>, got %v", err)
+		}
+		b.ReportInvalidMessage(data, errDecodeFailed)
+		b.ReportInvalidMessage(data, errDecodeFailed)
+
+		if _, err := b.HandleMsg(addr, makeMsg(tendermintMsg, []byte("other"))); err != errBannedPeer {
+			t.Fatalf("Expected %v, got %v", errBannedPeer, err)
+		}
+		if _, err := b.HandleMsg(addr, makeMsg(tendermintSyncMsg, []byte{})); err != errBannedPeer {
+			t.Fatalf("Expected %v, got %v", errBannedPeer, err)
+		}
+	})
+}
@@ -0,0 +1,211 @@
+package backend
+
+import (
+	"sync"
+
+	"github.com/clearmatics/autonity/common"
+	tendermintCore "github.com/clearmatics/autonity/consensus/tendermint/core"
+	"github.com/clearmatics/autonity/consensus/tendermint/events"
+	"github.com/clearmatics/autonity/core"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/p2p"
+)
+
+const (
+	// inmemoryProposalTxs is the number of blocks whose transactions are
+	// kept for the compact proposals.
+	inmemoryProposalTxs = 16
+	// txsResponseSoftLimit bounds the size of the transactions sent back to a
+	// request, the remaining ones are asked for again.
+	txsResponseSoftLimit = 2 * 1024 * 1024
+)
+
+// txsRequest asks for the transactions of a block at the given indexes.
+type txsRequest struct {
+	BlockHash common.Hash
+	Indexes   []uint64
+}
+
+// txsResponse carries the transactions of a block at the given indexes.
+type txsResponse struct {
+	BlockHash common.Hash
+	Indexes   []uint64
+	Txs       []*types.Transaction
+}
+
+// proposalTxs holds the known transactions of the block of a compact proposal
+// by index, along with the short hashes of those requested.
+type proposalTxs struct {
+	mu        sync.Mutex
+	txs       map[uint64]*types.Transaction
+	requested map[uint64]tendermintCore.ShortTxHash
+}
+
+// SetTxPool implements tendermint.Backend.SetTxPool
+func (sb *Backend) SetTxPool(pool *core.TxPool) {
+	sb.txPool = pool
+}
+
+// proposalTransactions returns the transactions held for the block, they are
+// created if create is set.
+func (sb *Backend) proposalTransactions(blockHash common.Hash, create bool) *proposalTxs {
+	sb.proposalTxsMu.Lock()
+	defer sb.proposalTxsMu.Unlock()
+	if txs, ok := sb.proposalTxs.Get(blockHash); ok {
+		return txs.(*proposalTxs)
+	}
+	if !create {
+		return nil
+	}
+	txs := &proposalTxs{
+		txs:       make(map[uint64]*types.Transaction),
+		requested: make(map[uint64]tendermintCore.ShortTxHash),
+	}
+	sb.proposalTxs.Add(blockHash, txs)
+	return txs
+}
+
+// ServeTransactions implements tendermint.Backend.ServeTransactions
+func (sb *Backend) ServeTransactions(block *types.Block) {
+	held := sb.proposalTransactions(block.Hash(), true)
+	held.mu.Lock()
+	defer held.mu.Unlock()
+	for i, tx := range block.Transactions() {
+		held.txs[uint64(i)] = tx
+	}
+	held.requested = make(map[uint64]tendermintCore.ShortTxHash)
+}
+
+// LookupTransactions implements tendermint.Backend.LookupTransactions, the
+// transactions fetched for the block take precedence over those of the pool.
+func (sb *Backend) LookupTransactions(blockHash common.Hash, hashes []tendermintCore.ShortTxHash) []*types.Transaction {
+	txs := make([]*types.Transaction, len(hashes))
+	missing := len(hashes)
+	if held := sb.proposalTransactions(blockHash, false); held != nil {
+		held.mu.Lock()
+		for i := range txs {
+			if tx, ok := held.txs[uint64(i)]; ok {
+				txs[i] = tx
+				missing--
+			}
+		}
+		held.mu.Unlock()
+	}
+	if missing == 0 || sb.txPool == nil {
+		return txs
+	}
+
+	// the transactions of the block may be queued in our pool if some of the
+	// previous ones of their sender are missing.
+	pool := make(map[tendermintCore.ShortTxHash]*types.Transaction)
+	pending, queued := sb.txPool.Content()
+	for _, content := range []map[common.Address]types.Transactions{pending, queued} {
+		for _, list := range content {
+			for _, tx := range list {
+				pool[tendermintCore.NewShortTxHash(tx.Hash())] = tx
+			}
+		}
+	}
+	for i, hash := range hashes {
+		if txs[i] == nil {
+			txs[i] = pool[hash]
+		}
+	}
+	return txs
+}
+
+// RequestTransactions implements tendermint.Backend.RequestTransactions, the
+// request is sent to every peer if the proposer isn't connected, the members
+// which rebuilt the block serve it as well.
+func (sb *Backend) RequestTransactions(proposer common.Address, blockHash common.Hash, missing map[uint64]tendermintCore.ShortTxHash) {
+	if sb.broadcaster == nil {
+		return
+	}
+	held := sb.proposalTransactions(blockHash, true)
+	request := txsRequest{BlockHash: blockHash, Indexes: make([]uint64, 0, len(missing))}
+	held.mu.Lock()
+	for i, hash := range missing {
+		held.requested[i] = hash
+		request.Indexes = append(request.Indexes, i)
+	}
+	held.mu.Unlock()
+
+	ps := sb.broadcaster.FindPeers(map[common.Address]struct{}{proposer: {}})
+	if len(ps) == 0 {
+		ps = sb.broadcaster.Peers()
+	}
+	for _, p := range ps {
+		go p.Send(tendermintTxsRequestMsg, request) //nolint
+	}
+}
+
+// handleTxsRequest sends back the transactions held for the block, up to
+// txsResponseSoftLimit.
+func (sb *Backend) handleTxsRequest(addr common.Address, msg p2p.Msg) error {
+	var request txsRequest
+	if err := msg.Decode(&request); err != nil {
+		return errDecodeFailed
+	}
+	held := sb.proposalTransactions(request.BlockHash, false)
+	if held == nil || sb.broadcaster == nil {
+		return nil
+	}
+	response := txsResponse{BlockHash: request.BlockHash}
+	size := common.StorageSize(0)
+	held.mu.Lock()
+	for _, i := range request.Indexes {
+		tx, ok := held.txs[i]
+		if !ok {
+			continue
+		}
+		response.Indexes = append(response.Indexes, i)
+		response.Txs = append(response.Txs, tx)
+		if size += tx.Size(); size >= txsResponseSoftLimit {
+			break
+		}
+	}
+	held.mu.Unlock()
+	if len(response.Txs) == 0 {
+		return nil
+	}
+	p, connected := sb.broadcaster.FindPeers(map[common.Address]struct{}{addr: {}})[addr]
+	if !connected {
+		return nil
+	}
+	go p.Send(tendermintTxsMsg, response) //nolint
+	return nil
+}
+
+// handleTxsResponse holds the transactions requested, the core is notified so
+// that it rebuilds the block. The transactions are matched against the short
+// hashes of the block, the wrong ones are dropped.
+func (sb *Backend) handleTxsResponse(addr common.Address, msg p2p.Msg) error {
+	var response txsResponse
+	if err := msg.Decode(&response); err != nil || len(response.Indexes) != len(response.Txs) {
+		return errDecodeFailed
+	}
+	held := sb.proposalTransactions(response.BlockHash, false)
+	if held == nil {
+		return nil
+	}
+	received := 0
+	held.mu.Lock()
+	for j, i := range response.Indexes {
+		hash, ok := held.requested[i]
+		if !ok {
+			continue
+		}
+		if tendermintCore.NewShortTxHash(response.Txs[j].Hash()) != hash {
+			sb.logger.Debug("Unexpected transaction received", "from", addr, "block", response.BlockHash, "index", i)
+			continue
+		}
+		held.txs[i] = response.Txs[j]
+		delete(held.requested, i)
+		received++
+	}
+	held.mu.Unlock()
+	if received > 0 {
+		sb.postEvent(events.ProposalTransactionsEvent{BlockHash: response.BlockHash})
+	}
+	return nil
+}
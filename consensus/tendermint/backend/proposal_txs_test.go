@@ -0,0 +1,124 @@
+package backend
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/consensus"
+	tendermintCore "github.com/clearmatics/autonity/consensus/tendermint/core"
+	"github.com/clearmatics/autonity/consensus/tendermint/events"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/event"
+	"github.com/clearmatics/autonity/log"
+)
+
+func TestProposalTransactions(t *testing.T) {
+	txs := make([]*types.Transaction, 3)
+	for i := range txs {
+		txs[i] = types.NewTransaction(uint64(i), common.Address{}, big.NewInt(1), 21000, big.NewInt(1), nil)
+	}
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1)}).WithBody(txs, nil)
+	peerAddr := common.HexToAddress("0x0123456789")
+
+	newBackend := func(broadcaster consensus.Broadcaster) *Backend {
+		proposalTxs, err := lru.NewARC(inmemoryProposalTxs)
+		if err != nil {
+			t.Fatalf("Expected <nil>, got %v", err)
+		}
+		logger := log.New("backend", "test", "id", 0)
+		return &Backend{
+			logger:      logger,
+			eventMux:    event.NewTypeMuxSilent(logger),
+			proposalTxs: proposalTxs,
+			broadcaster: broadcaster,
+		}
+	}
+
+	t.Run("served transactions are sent back", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		sent := make(chan interface{}, 1)
+		peer := consensus.NewMockPeer(ctrl)
+		peer.EXPECT().Send(uint64(tendermintTxsMsg), gomock.Any()).Do(func(_, data interface{}) {
+			sent <- data
+		})
+		broadcaster := consensus.NewMockBroadcaster(ctrl)
+		broadcaster.EXPECT().FindPeers(map[common.Address]struct{}{peerAddr: {}}).Return(map[common.Address]consensus.Peer{peerAddr: peer})
+
+		b := newBackend(broadcaster)
+		b.ServeTransactions(block)
+		request := txsRequest{BlockHash: block.Hash(), Indexes: []uint64{2, 0, 5}}
+		if err := b.handleTxsRequest(peerAddr, makeMsg(tendermintTxsRequestMsg, request)); err != nil {
+			t.Fatalf("Expected <nil>, got %v", err)
+		}
+
+		expected := txsResponse{BlockHash: block.Hash(), Indexes: []uint64{2, 0}, Txs: []*types.Transaction{txs[2], txs[0]}}
+		select {
+		case data := <-sent:
+			if !reflect.DeepEqual(data, expected) {
+				t.Fatalf("Expected %v, got %v", expected, data)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Expected the transactions to be sent back")
+		}
+	})
+
+	t.Run("requested transactions are received", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		sent := make(chan interface{}, 1)
+		peer := consensus.NewMockPeer(ctrl)
+		peer.EXPECT().Send(uint64(tendermintTxsRequestMsg), gomock.Any()).Do(func(_, data interface{}) {
+			sent <- data
+		})
+		broadcaster := consensus.NewMockBroadcaster(ctrl)
+		broadcaster.EXPECT().FindPeers(map[common.Address]struct{}{peerAddr: {}}).Return(map[common.Address]consensus.Peer{peerAddr: peer})
+
+		b := newBackend(broadcaster)
+		sub := b.Subscribe(events.ProposalTransactionsEvent{})
+		defer sub.Unsubscribe()
+
+		hashes := make([]tendermintCore.ShortTxHash, len(txs))
+		for i, tx := range txs {
+			hashes[i] = tendermintCore.NewShortTxHash(tx.Hash())
+		}
+		if found := b.LookupTransactions(block.Hash(), hashes); !reflect.DeepEqual(found, make([]*types.Transaction, len(txs))) {
+			t.Fatalf("Expected no transactions, got %v", found)
+		}
+		b.RequestTransactions(peerAddr, block.Hash(), map[uint64]tendermintCore.ShortTxHash{1: hashes[1], 2: hashes[2]})
+		select {
+		case data := <-sent:
+			if request := data.(txsRequest); request.BlockHash != block.Hash() || len(request.Indexes) != 2 {
+				t.Fatalf("Unexpected request %v", request)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Expected the transactions to be requested")
+		}
+
+		// the transaction which doesn't match the requested hash is dropped
+		response := txsResponse{BlockHash: block.Hash(), Indexes: []uint64{1, 2}, Txs: []*types.Transaction{txs[1], txs[0]}}
+		if err := b.handleTxsResponse(peerAddr, makeMsg(tendermintTxsMsg, response)); err != nil {
+			t.Fatalf("Expected <nil>, got %v", err)
+		}
+		select {
+		case ev := <-sub.Chan():
+			if e := ev.Data.(events.ProposalTransactionsEvent); e.BlockHash != block.Hash() {
+				t.Fatalf("Expected %v, got %v", block.Hash(), e.BlockHash)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Expected the core to be notified")
+		}
+		found := b.LookupTransactions(block.Hash(), hashes)
+		if found[0] != nil || found[1].Hash() != txs[1].Hash() || found[2] != nil {
+			t.Fatalf("Expected only the second transaction, got %v", found)
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package backend
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/common/hexutil"
+	tendermintConfig "github.com/clearmatics/autonity/consensus/tendermint/config"
+	tendermintCrypto "github.com/clearmatics/autonity/consensus/tendermint/crypto"
+	"github.com/clearmatics/autonity/crypto"
+	"github.com/clearmatics/autonity/rpc"
+)
+
+// ConsensusSigner holds the consensus keys of the node and signs the data of
+// the consensus protocol, whose content type is one of the tendermint
+// mimetypes defined in the accounts package. The in-process key signer of the
+// tendermint crypto package is the default implementation.
+type ConsensusSigner interface {
+	// Address returns the address of the key signing the consensus messages.
+	Address() common.Address
+	// ConsensusKey returns the BLS public key followed by its proof of
+	// possession, as registered in the autonity contract.
+	ConsensusKey() []byte
+	// SignData signs the data of the given content type.
+	SignData(contentType string, data []byte) ([]byte, error)
+}
+
+// newConsensusSigners returns the signers of the consensus keys configured, the
+// node key is used if there is none. Several key files are listed while a key
+// is rotated, so that the node keeps signing with the key in the committee.
+func newConsensusSigners(config *tendermintConfig.Config, nodeKey *ecdsa.PrivateKey) ([]ConsensusSigner, error) {
+	if config.ExternalSigner != "" {
+		signer, err := NewExternalSigner(config.ExternalSigner)
+		if err != nil {
+			return nil, err
+		}
+		return []ConsensusSigner{signer}, nil
+	}
+
+	keys := []*ecdsa.PrivateKey{nodeKey}
+	if len(config.ConsensusKeyFiles) > 0 {
+		keys = keys[:0]
+		for _, file := range config.ConsensusKeyFiles {
+			key, err := crypto.LoadECDSA(file)
+			if err != nil {
+				return nil, fmt.Errorf("invalid consensus key file %s: %v", file, err)
+			}
+			keys = append(keys, key)
+		}
+	}
+	signers := make([]ConsensusSigner, len(keys))
+	for i, key := range keys {
+		signer, err := tendermintCrypto.NewKeySigner(key)
+		if err != nil {
+			return nil, err
+		}
+		signers[i] = signer
+	}
+	return signers, nil
+}
+
+// ExternalSigner is a consensus signer running in a separate process, like
+// clef, which is reached over IPC or HTTP. The external signer checks the data
+// it is asked to sign on its own, so that it doesn't sign conflicting messages
+// even if the node is compromised.
+type ExternalSigner struct {
+	client       *rpc.Client
+	address      common.Address
+	consensusKey []byte
+}
+
+// NewExternalSigner connects to the external signer at the given endpoint and
+// retrieves its keys.
+func NewExternalSigner(endpoint string) (*ExternalSigner, error) {
+	client, err := rpc.Dial(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := newExternalSigner(client)
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("external signer %s unreachable: %v", endpoint, err)
+	}
+	return signer, nil
+}
+
+func newExternalSigner(client *rpc.Client) (*ExternalSigner, error) {
+	signer := &ExternalSigner{client: client}
+	if err := client.Call(&signer.address, "consensus_address"); err != nil {
+		return nil, err
+	}
+	var consensusKey hexutil.Bytes
+	if err := client.Call(&consensusKey, "consensus_consensusKey"); err != nil {
+		return nil, err
+	}
+	signer.consensusKey = consensusKey
+	return signer, nil
+}
+
+// Address implements ConsensusSigner.Address
+func (s *ExternalSigner) Address() common.Address {
+	return s.address
+}
+
+// ConsensusKey implements ConsensusSigner.ConsensusKey
+func (s *ExternalSigner) ConsensusKey() []byte {
+	return common.CopyBytes(s.consensusKey)
+}
+
+// SignData implements ConsensusSigner.SignData
+func (s *ExternalSigner) SignData(contentType string, data []byte) ([]byte, error) {
+	var res hexutil.Bytes
+	if err := s.client.Call(&res, "consensus_signData", contentType, hexutil.Bytes(data)); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Close closes the connection to the external signer.
+func (s *ExternalSigner) Close() {
+	s.client.Close()
+}
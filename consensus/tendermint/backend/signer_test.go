@@ -0,0 +1,203 @@
+package backend
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/clearmatics/autonity/common"
+	tendermintConfig "github.com/clearmatics/autonity/consensus/tendermint/config"
+	tendermintCore "github.com/clearmatics/autonity/consensus/tendermint/core"
+	tendermintCrypto "github.com/clearmatics/autonity/consensus/tendermint/crypto"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/crypto"
+	"github.com/clearmatics/autonity/rpc"
+	signerCore "github.com/clearmatics/autonity/signer/core"
+)
+
+func TestExternalSigner(t *testing.T) {
+	key, err := generatePrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "consensus-signer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	markPath := filepath.Join(dir, "consensus-mark.json")
+
+	newBackend := func() *Backend {
+		api, err := signerCore.NewConsensusSignerAPI(key, markPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		server := rpc.NewServer()
+		if err := server.RegisterName("consensus", api); err != nil {
+			t.Fatal(err)
+		}
+		signer, err := newExternalSigner(rpc.DialInProc(server))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &Backend{signers: []ConsensusSigner{signer}}
+	}
+	prevote := func(round int64, height *big.Int, hash common.Hash) []byte {
+		encodedVote, err := tendermintCore.Encode(&tendermintCore.Vote{Round: round, Height: height, ProposedBlockHash: hash})
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg := &tendermintCore.Message{Code: tendermintCore.MsgPrevote, Msg: encodedVote, Address: crypto.PubkeyToAddress(key.PublicKey)}
+		data, err := msg.PayloadNoSig()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	first, second := common.HexToHash("0x1"), common.HexToHash("0x2")
+	b := newBackend()
+
+	t.Run("keys", func(t *testing.T) {
+		if expected := crypto.PubkeyToAddress(key.PublicKey); b.Address() != expected {
+			t.Fatalf("Expected %v, got %v", expected, b.Address())
+		}
+		local, err := tendermintCrypto.NewKeySigner(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(b.ConsensusKey(), local.ConsensusKey()) {
+			t.Fatalf("Expected %x, got %x", local.ConsensusKey(), b.ConsensusKey())
+		}
+	})
+
+	t.Run("votes are signed once per step", func(t *testing.T) {
+		data := prevote(1, big.NewInt(5), first)
+		sig, err := b.Sign(data)
+		assertNilError(t, err)
+		if err := b.CheckSignature(data, b.Address(), sig); err != nil {
+			t.Fatalf("Expected nil, got %v", err)
+		}
+		// signing the same vote again is harmless
+		_, err = b.Sign(data)
+		assertNilError(t, err)
+		// the committed seal is at a later step of the same round
+		_, err = b.SignCommittedSeal(tendermintCore.PrepareCommittedSeal(first, 1, big.NewInt(5)), false)
+		assertNilError(t, err)
+
+		_, err = b.SignCommittedSeal(tendermintCore.PrepareCommittedSeal(second, 1, big.NewInt(5)), false)
+		assertSignerError(t, err)
+		_, err = b.Sign(prevote(1, big.NewInt(5), first))
+		assertSignerError(t, err)
+		_, err = b.Sign(prevote(0, big.NewInt(5), second))
+		assertSignerError(t, err)
+	})
+
+	t.Run("blocks below the mark are refused", func(t *testing.T) {
+		block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(4), MixDigest: types.BFTDigest, Difficulty: big.NewInt(1)})
+		_, err := b.AddSeal(block)
+		assertSignerError(t, err)
+
+		block = types.NewBlockWithHeader(&types.Header{Number: big.NewInt(6), MixDigest: types.BFTDigest, Difficulty: big.NewInt(1)})
+		sealed, err := b.AddSeal(block)
+		assertNilError(t, err)
+		author, err := b.Author(sealed.Header())
+		assertNilError(t, err)
+		if author != b.Address() {
+			t.Fatalf("Expected %v, got %v", b.Address(), author)
+		}
+	})
+
+	t.Run("the mark survives restarts", func(t *testing.T) {
+		restarted := newBackend()
+		_, err := restarted.Sign(prevote(0, big.NewInt(5), first))
+		assertSignerError(t, err)
+		_, err = restarted.Sign(prevote(0, big.NewInt(6), first))
+		assertNilError(t, err)
+	})
+}
+
+// assertSignerError checks that the external signer refused to sign, the
+// error is a string once it went through the RPC layer.
+func assertSignerError(t *testing.T, err error) {
+	t.Helper()
+	if err == nil || err.Error() != signerCore.ErrDoubleSign.Error() {
+		t.Fatalf("Expected %v, got %v", signerCore.ErrDoubleSign, err)
+	}
+}
+
+func TestConsensusKeyRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "consensus-keys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	nodeKey, _ := crypto.GenerateKey()
+	keys := make([]*ecdsa.PrivateKey, 2)
+	files := make([]string, 2)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		files[i] = filepath.Join(dir, fmt.Sprintf("consensus%d.key", i))
+		if err := crypto.SaveECDSA(files[i], keys[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	current, next := crypto.PubkeyToAddress(keys[0].PublicKey), crypto.PubkeyToAddress(keys[1].PublicKey)
+
+	t.Run("node key by default", func(t *testing.T) {
+		signers, err := newConsensusSigners(&tendermintConfig.Config{}, nodeKey)
+		assertNilError(t, err)
+		if len(signers) != 1 || signers[0].Address() != crypto.PubkeyToAddress(nodeKey.PublicKey) {
+			t.Fatalf("Expected the node key, got %v signers", len(signers))
+		}
+	})
+
+	t.Run("invalid key file", func(t *testing.T) {
+		config := &tendermintConfig.Config{ConsensusKeyFiles: []string{filepath.Join(dir, "missing.key")}}
+		if _, err := newConsensusSigners(config, nodeKey); err == nil {
+			t.Fatalf("Expected an error, got nil")
+		}
+	})
+
+	t.Run("the key in the committee signs", func(t *testing.T) {
+		signers, err := newConsensusSigners(&tendermintConfig.Config{ConsensusKeyFiles: files}, nodeKey)
+		assertNilError(t, err)
+
+		var head *types.Block
+		b := &Backend{signers: signers, currentBlock: func() *types.Block { return head }}
+		committee := func(address common.Address) *types.Block {
+			return types.NewBlockWithHeader(&types.Header{
+				Number:    big.NewInt(1),
+				Committee: types.Committee{{Address: address, VotingPower: big.NewInt(1)}},
+			})
+		}
+		for _, c := range []struct {
+			member   common.Address
+			expected common.Address
+		}{
+			{current, current},
+			{next, next},
+			// the first key is used when the node is not in the committee
+			{common.HexToAddress("0x1"), current},
+		} {
+			head = committee(c.member)
+			if b.Address() != c.expected {
+				t.Fatalf("Expected %v, got %v", c.expected, b.Address())
+			}
+			data := []byte("message")
+			sig, err := b.Sign(data)
+			assertNilError(t, err)
+			if err := b.CheckSignature(data, c.expected, sig); err != nil {
+				t.Fatalf("Expected nil, got %v", err)
+			}
+		}
+		if keys := b.ConsensusKeys(); len(keys) != 2 || len(keys[current]) == 0 || len(keys[next]) == 0 {
+			t.Fatalf("Expected the keys of %v and %v, got %v", current, next, keys)
+		}
+	})
+}
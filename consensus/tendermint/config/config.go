@@ -16,7 +16,11 @@
 
 package config
 
-import "flag"
+import (
+	"errors"
+	"flag"
+	"fmt"
+)
 
 var blockPeriod = flag.Uint64("blockperiod", 0, "The minimum time between blocks in seconds")
 
@@ -25,27 +29,162 @@ type ProposerPolicy uint64
 const (
 	RoundRobin ProposerPolicy = iota
 	WeightedRandomSampling
+	// RandomBeacon samples the proposers by voting power from the beacon
+	// signed by the proposer of the previous block.
+	RandomBeacon
+	// WeightedRoundRobin elects the member with the highest accumulated
+	// priority, so that members propose in proportion to their voting power.
+	WeightedRoundRobin
+)
+
+// Default step timeouts in milliseconds, the timeout of a step at round r is
+// its initial value plus r times its delta.
+const (
+	DefaultProposeTimeout        = 2000
+	DefaultProposeTimeoutDelta   = 500
+	DefaultPrevoteTimeout        = 1000
+	DefaultPrevoteTimeoutDelta   = 500
+	DefaultPrecommitTimeout      = 1000
+	DefaultPrecommitTimeoutDelta = 500
+
+	// Default bounds of the adaptive step timeouts at round 0 in milliseconds.
+	DefaultMinAdaptiveTimeout = 200
+	DefaultMaxAdaptiveTimeout = 10000
+
+	// MaxTimeout is the maximum value of the initial step timeouts and of their
+	// deltas in milliseconds.
+	MaxTimeout = 60000
 )
 
 type Config struct {
 	BlockPeriod    uint64         `toml:",omitempty" json:"block-period"` // Default minimum difference between two consecutive block's timestamps in second
 	ProposerPolicy ProposerPolicy `toml:",omitempty" json:"policy"`       // The policy for proposer selection
+
+	MaxEmptyBlockInterval uint64 `toml:",omitempty" json:"max-empty-block-interval,omitempty"` // Maximum time between two blocks in seconds when there are no transactions to include, empty blocks are produced every block period if zero
+
+	// The step timeouts are left nil if unset, the ones of the node config
+	// override the ones of the chain config, zero included.
+	ProposeTimeout        *uint64 `toml:",omitempty" json:"propose-timeout,omitempty"`         // Timeout of the propose step at round 0 in milliseconds
+	ProposeTimeoutDelta   *uint64 `toml:",omitempty" json:"propose-timeout-delta,omitempty"`   // Increase of the propose timeout at each round in milliseconds
+	PrevoteTimeout        *uint64 `toml:",omitempty" json:"prevote-timeout,omitempty"`         // Timeout of the prevote step at round 0 in milliseconds
+	PrevoteTimeoutDelta   *uint64 `toml:",omitempty" json:"prevote-timeout-delta,omitempty"`   // Increase of the prevote timeout at each round in milliseconds
+	PrecommitTimeout      *uint64 `toml:",omitempty" json:"precommit-timeout,omitempty"`       // Timeout of the precommit step at round 0 in milliseconds
+	PrecommitTimeoutDelta *uint64 `toml:",omitempty" json:"precommit-timeout-delta,omitempty"` // Increase of the precommit timeout at each round in milliseconds
+	ExponentialTimeouts   *bool   `toml:",omitempty" json:"exponential-timeouts,omitempty"`    // Double the timeout deltas at each round instead of adding them linearly
+	AdaptiveTimeouts      *bool   `toml:",omitempty" json:"adaptive-timeouts,omitempty"`       // Adjust the timeouts at round 0 to the step durations observed at the previous height
+	MinAdaptiveTimeout    *uint64 `toml:",omitempty" json:"min-adaptive-timeout,omitempty"`    // Lower bound of the adaptive timeouts in milliseconds
+	MaxAdaptiveTimeout    *uint64 `toml:",omitempty" json:"max-adaptive-timeout,omitempty"`    // Upper bound of the adaptive timeouts in milliseconds
+
+	ExternalSigner    string   `toml:",omitempty" json:"-"` // Endpoint of the external signer holding the consensus keys, the node key is used if empty
+	ConsensusKeyFiles []string `toml:",omitempty" json:"-"` // Files of the consensus keys, the one in the committee signs. The node key is used if empty
+	Relay             bool     `toml:",omitempty" json:"-"` // Forward the consensus messages of the current height to every peer, not only to the committee
+	CompactProposals  bool     `toml:",omitempty" json:"-"` // Propose the blocks with the short hashes of their transactions, the members fetch those missing from their pool
 }
 
 func (c *Config) String() string {
 	return "tendermint"
 }
 
+// ApplyChainConfig sets the values of the chain config, the timeouts of the
+// node config are only set from it if they are unset, the defaults are used if
+// neither of them specifies a timeout.
+func (c *Config) ApplyChainConfig(chain *Config) {
+	if chain == nil {
+		chain = &Config{}
+	} else {
+		// the proposer of a block is checked against the policy of the chain,
+		// round robin included.
+		c.ProposerPolicy = chain.ProposerPolicy
+		// the committee waits for the empty blocks the proposers delay, none
+		// are if the chain leaves it unset.
+		c.MaxEmptyBlockInterval = chain.MaxEmptyBlockInterval
+	}
+	if chain.BlockPeriod != 0 {
+		c.BlockPeriod = chain.BlockPeriod
+	}
+	for _, t := range []struct {
+		node  **bool
+		chain *bool
+	}{
+		{&c.ExponentialTimeouts, chain.ExponentialTimeouts},
+		{&c.AdaptiveTimeouts, chain.AdaptiveTimeouts},
+	} {
+		if *t.node == nil {
+			*t.node = t.chain
+		}
+		if *t.node == nil {
+			*t.node = new(bool)
+		}
+	}
+	for _, t := range []struct {
+		node  **uint64
+		chain *uint64
+		def   uint64
+	}{
+		{&c.ProposeTimeout, chain.ProposeTimeout, DefaultProposeTimeout},
+		{&c.ProposeTimeoutDelta, chain.ProposeTimeoutDelta, DefaultProposeTimeoutDelta},
+		{&c.PrevoteTimeout, chain.PrevoteTimeout, DefaultPrevoteTimeout},
+		{&c.PrevoteTimeoutDelta, chain.PrevoteTimeoutDelta, DefaultPrevoteTimeoutDelta},
+		{&c.PrecommitTimeout, chain.PrecommitTimeout, DefaultPrecommitTimeout},
+		{&c.PrecommitTimeoutDelta, chain.PrecommitTimeoutDelta, DefaultPrecommitTimeoutDelta},
+		{&c.MinAdaptiveTimeout, chain.MinAdaptiveTimeout, DefaultMinAdaptiveTimeout},
+		{&c.MaxAdaptiveTimeout, chain.MaxAdaptiveTimeout, DefaultMaxAdaptiveTimeout},
+	} {
+		if *t.node == nil {
+			*t.node = t.chain
+		}
+		if *t.node == nil {
+			def := t.def
+			*t.node = &def
+		}
+	}
+}
+
+// ValidateTimeouts checks that every step timeout is set, that neither them
+// nor their deltas exceed MaxTimeout and that the adaptive bounds are ordered.
+func (c *Config) ValidateTimeouts() error {
+	for _, t := range []*uint64{c.ProposeTimeout, c.ProposeTimeoutDelta, c.PrevoteTimeout, c.PrevoteTimeoutDelta,
+		c.PrecommitTimeout, c.PrecommitTimeoutDelta, c.MinAdaptiveTimeout, c.MaxAdaptiveTimeout} {
+		if t == nil {
+			return errors.New("timeouts must be set")
+		}
+	}
+	if *c.MinAdaptiveTimeout == 0 || *c.MinAdaptiveTimeout > *c.MaxAdaptiveTimeout || *c.MaxAdaptiveTimeout > MaxTimeout {
+		return fmt.Errorf("adaptive timeout bounds must satisfy 0 < min <= max <= %d milliseconds, got min %d and max %d", MaxTimeout, *c.MinAdaptiveTimeout, *c.MaxAdaptiveTimeout)
+	}
+	for _, t := range []struct {
+		name  string
+		value uint64
+		delta uint64
+	}{
+		{"propose", *c.ProposeTimeout, *c.ProposeTimeoutDelta},
+		{"prevote", *c.PrevoteTimeout, *c.PrevoteTimeoutDelta},
+		{"precommit", *c.PrecommitTimeout, *c.PrecommitTimeoutDelta},
+	} {
+		if t.value == 0 || t.value > MaxTimeout {
+			return fmt.Errorf("%s timeout must be between 1 and %d milliseconds, got %d", t.name, MaxTimeout, t.value)
+		}
+		if t.delta > MaxTimeout {
+			return fmt.Errorf("%s timeout delta must not exceed %d milliseconds, got %d", t.name, MaxTimeout, t.delta)
+		}
+	}
+	return nil
+}
+
 func DefaultConfig() *Config {
-	return &Config{
+	config := &Config{
 		BlockPeriod:    *blockPeriod,
 		ProposerPolicy: WeightedRandomSampling,
 	}
+	config.ApplyChainConfig(nil)
+	return config
 }
 
 func RoundRobinConfig() *Config {
-	return &Config{
+	config := &Config{
 		BlockPeriod:    *blockPeriod,
 		ProposerPolicy: RoundRobin,
 	}
+	config.ApplyChainConfig(nil)
+	return config
 }
@@ -0,0 +1,115 @@
+package config
+
+import "testing"
+
+func uint64Ptr(v uint64) *uint64 { return &v }
+
+func boolPtr(v bool) *bool { return &v }
+
+func TestApplyChainConfig(t *testing.T) {
+	chain := &Config{BlockPeriod: 5, PrevoteTimeout: uint64Ptr(3000), PrecommitTimeout: uint64Ptr(4000), ProposerPolicy: RandomBeacon, MaxEmptyBlockInterval: 60}
+	node := &Config{BlockPeriod: 1, PrecommitTimeout: uint64Ptr(200)}
+	node.ApplyChainConfig(chain)
+
+	if node.BlockPeriod != 5 {
+		t.Fatalf("Expected %v, got %v", 5, node.BlockPeriod)
+	}
+	if node.ProposerPolicy != RandomBeacon {
+		t.Fatalf("Expected %v, got %v", RandomBeacon, node.ProposerPolicy)
+	}
+	if node.MaxEmptyBlockInterval != 60 {
+		t.Fatalf("Expected %v, got %v", 60, node.MaxEmptyBlockInterval)
+	}
+	if *node.ProposeTimeout != DefaultProposeTimeout {
+		t.Fatalf("Expected %v, got %v", DefaultProposeTimeout, *node.ProposeTimeout)
+	}
+	if *node.PrevoteTimeout != 3000 {
+		t.Fatalf("Expected %v, got %v", 3000, *node.PrevoteTimeout)
+	}
+	if *node.PrecommitTimeout != 200 {
+		t.Fatalf("Expected %v, got %v", 200, *node.PrecommitTimeout)
+	}
+	if *node.ExponentialTimeouts || *node.AdaptiveTimeouts {
+		t.Fatal("Expected the timeouts to be neither exponential nor adaptive")
+	}
+	if err := node.ValidateTimeouts(); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+
+	t.Run("node zero delta", func(t *testing.T) {
+		node := &Config{ProposeTimeoutDelta: uint64Ptr(0)}
+		node.ApplyChainConfig(&Config{ProposeTimeoutDelta: uint64Ptr(1000)})
+		if *node.ProposeTimeoutDelta != 0 {
+			t.Fatalf("Expected %v, got %v", 0, *node.ProposeTimeoutDelta)
+		}
+		if err := node.ValidateTimeouts(); err != nil {
+			t.Fatalf("Expected nil, got %v", err)
+		}
+	})
+
+	t.Run("chain zero delta", func(t *testing.T) {
+		node := &Config{}
+		node.ApplyChainConfig(&Config{PrevoteTimeoutDelta: uint64Ptr(0)})
+		if *node.PrevoteTimeoutDelta != 0 {
+			t.Fatalf("Expected %v, got %v", 0, *node.PrevoteTimeoutDelta)
+		}
+	})
+
+	t.Run("node turns timeouts off", func(t *testing.T) {
+		node := &Config{ExponentialTimeouts: boolPtr(false), AdaptiveTimeouts: boolPtr(false)}
+		node.ApplyChainConfig(&Config{ExponentialTimeouts: boolPtr(true), AdaptiveTimeouts: boolPtr(true)})
+		if *node.ExponentialTimeouts || *node.AdaptiveTimeouts {
+			t.Fatal("Expected the node config to take precedence")
+		}
+	})
+
+	t.Run("chain turns timeouts on", func(t *testing.T) {
+		node := &Config{}
+		node.ApplyChainConfig(&Config{ExponentialTimeouts: boolPtr(true), AdaptiveTimeouts: boolPtr(true)})
+		if !*node.ExponentialTimeouts || !*node.AdaptiveTimeouts {
+			t.Fatal("Expected the chain config to apply")
+		}
+	})
+
+	t.Run("round robin chain", func(t *testing.T) {
+		node := &Config{ProposerPolicy: RandomBeacon}
+		node.ApplyChainConfig(&Config{ProposerPolicy: RoundRobin})
+		if node.ProposerPolicy != RoundRobin {
+			t.Fatalf("Expected %v, got %v", RoundRobin, node.ProposerPolicy)
+		}
+	})
+
+	t.Run("chain without empty block delay", func(t *testing.T) {
+		node := &Config{MaxEmptyBlockInterval: 60}
+		node.ApplyChainConfig(&Config{})
+		if node.MaxEmptyBlockInterval != 0 {
+			t.Fatalf("Expected %v, got %v", 0, node.MaxEmptyBlockInterval)
+		}
+	})
+
+	t.Run("no chain config", func(t *testing.T) {
+		node := &Config{ProposerPolicy: WeightedRandomSampling}
+		node.ApplyChainConfig(nil)
+		if node.ProposerPolicy != WeightedRandomSampling {
+			t.Fatalf("Expected %v, got %v", WeightedRandomSampling, node.ProposerPolicy)
+		}
+	})
+}
+
+func TestValidateTimeouts(t *testing.T) {
+	for name, update := range map[string]func(*Config){
+		"unset timeout":    func(c *Config) { c.ProposeTimeout = nil },
+		"zero timeout":     func(c *Config) { c.ProposeTimeout = uint64Ptr(0) },
+		"timeout too long": func(c *Config) { c.PrevoteTimeout = uint64Ptr(MaxTimeout + 1) },
+		"delta too long":   func(c *Config) { c.PrecommitTimeoutDelta = uint64Ptr(MaxTimeout + 1) },
+		"adaptive bounds":  func(c *Config) { c.MinAdaptiveTimeout = uint64Ptr(*c.MaxAdaptiveTimeout + 1) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := DefaultConfig()
+			update(c)
+			if err := c.ValidateTimeouts(); err == nil {
+				t.Fatal("Expected error, got nil")
+			}
+		})
+	}
+}
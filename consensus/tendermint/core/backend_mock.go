@@ -108,6 +108,20 @@ func (mr *MockBackendMockRecorder) Commit(proposalBlock, round, seals interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Commit", reflect.TypeOf((*MockBackend)(nil).Commit), proposalBlock, round, seals)
 }
 
+// CommitAggregated mocks base method
+func (m *MockBackend) CommitAggregated(proposalBlock *types.Block, round int64, seal types.AggregatedSeal) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CommitAggregated", proposalBlock, round, seal)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CommitAggregated indicates an expected call of CommitAggregated
+func (mr *MockBackendMockRecorder) CommitAggregated(proposalBlock, round, seal interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitAggregated", reflect.TypeOf((*MockBackend)(nil).CommitAggregated), proposalBlock, round, seal)
+}
+
 // GetContractABI mocks base method
 func (m *MockBackend) GetContractABI() string {
 	m.ctrl.T.Helper()
@@ -162,6 +176,18 @@ func (mr *MockBackendMockRecorder) Gossip(ctx, committee, payload interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Gossip", reflect.TypeOf((*MockBackend)(nil).Gossip), ctx, committee, payload)
 }
 
+// GossipParts mocks base method
+func (m *MockBackend) GossipParts(ctx context.Context, committee types.Committee, payloads [][]byte) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GossipParts", ctx, committee, payloads)
+}
+
+// GossipParts indicates an expected call of GossipParts
+func (mr *MockBackendMockRecorder) GossipParts(ctx, committee, payloads interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GossipParts", reflect.TypeOf((*MockBackend)(nil).GossipParts), ctx, committee, payloads)
+}
+
 // HandleUnhandledMsgs mocks base method
 func (m *MockBackend) HandleUnhandledMsgs(ctx context.Context) {
 	m.ctrl.T.Helper()
@@ -201,6 +227,44 @@ func (mr *MockBackendMockRecorder) Post(ev interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Post", reflect.TypeOf((*MockBackend)(nil).Post), ev)
 }
 
+// LookupTransactions mocks base method
+func (m *MockBackend) LookupTransactions(blockHash common.Hash, hashes []ShortTxHash) []*types.Transaction {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LookupTransactions", blockHash, hashes)
+	ret0, _ := ret[0].([]*types.Transaction)
+	return ret0
+}
+
+// LookupTransactions indicates an expected call of LookupTransactions
+func (mr *MockBackendMockRecorder) LookupTransactions(blockHash, hashes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LookupTransactions", reflect.TypeOf((*MockBackend)(nil).LookupTransactions), blockHash, hashes)
+}
+
+// RequestTransactions mocks base method
+func (m *MockBackend) RequestTransactions(proposer common.Address, blockHash common.Hash, missing map[uint64]ShortTxHash) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RequestTransactions", proposer, blockHash, missing)
+}
+
+// RequestTransactions indicates an expected call of RequestTransactions
+func (mr *MockBackendMockRecorder) RequestTransactions(proposer, blockHash, missing interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestTransactions", reflect.TypeOf((*MockBackend)(nil).RequestTransactions), proposer, blockHash, missing)
+}
+
+// ServeTransactions mocks base method
+func (m *MockBackend) ServeTransactions(block *types.Block) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ServeTransactions", block)
+}
+
+// ServeTransactions indicates an expected call of ServeTransactions
+func (mr *MockBackendMockRecorder) ServeTransactions(block interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ServeTransactions", reflect.TypeOf((*MockBackend)(nil).ServeTransactions), block)
+}
+
 // SetProposedBlockHash mocks base method
 func (m *MockBackend) SetProposedBlockHash(hash common.Hash) {
 	m.ctrl.T.Helper()
@@ -228,6 +292,21 @@ func (mr *MockBackendMockRecorder) Sign(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sign", reflect.TypeOf((*MockBackend)(nil).Sign), arg0)
 }
 
+// SignCommittedSeal mocks base method
+func (m *MockBackend) SignCommittedSeal(seal []byte, aggregated bool) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignCommittedSeal", seal, aggregated)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SignCommittedSeal indicates an expected call of SignCommittedSeal
+func (mr *MockBackendMockRecorder) SignCommittedSeal(seal, aggregated interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignCommittedSeal", reflect.TypeOf((*MockBackend)(nil).SignCommittedSeal), seal, aggregated)
+}
+
 // Subscribe mocks base method
 func (m *MockBackend) Subscribe(types ...interface{}) *event.TypeMuxSubscription {
 	m.ctrl.T.Helper()
@@ -247,15 +326,15 @@ func (mr *MockBackendMockRecorder) Subscribe(types ...interface{}) *gomock.Call
 }
 
 // SyncPeer mocks base method
-func (m *MockBackend) SyncPeer(address common.Address) {
+func (m *MockBackend) SyncPeer(address common.Address, request *SyncRequest) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "SyncPeer", address)
+	m.ctrl.Call(m, "SyncPeer", address, request)
 }
 
 // SyncPeer indicates an expected call of SyncPeer
-func (mr *MockBackendMockRecorder) SyncPeer(address interface{}) *gomock.Call {
+func (mr *MockBackendMockRecorder) SyncPeer(address, request interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncPeer", reflect.TypeOf((*MockBackend)(nil).SyncPeer), address)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncPeer", reflect.TypeOf((*MockBackend)(nil).SyncPeer), address, request)
 }
 
 // VerifyProposal mocks base method
@@ -313,6 +392,18 @@ func (mr *MockBackendMockRecorder) SetBlockchain(bc interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBlockchain", reflect.TypeOf((*MockBackend)(nil).SetBlockchain), bc)
 }
 
+// SetTxPool mocks base method
+func (m *MockBackend) SetTxPool(pool *ethcore.TxPool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetTxPool", pool)
+}
+
+// SetTxPool indicates an expected call of SetTxPool
+func (mr *MockBackendMockRecorder) SetTxPool(pool interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTxPool", reflect.TypeOf((*MockBackend)(nil).SetTxPool), pool)
+}
+
 // RemoveMessageFromLocalCache mocks base method
 func (m *MockBackend) RemoveMessageFromLocalCache(payload []byte) {
 	m.ctrl.T.Helper()
@@ -325,6 +416,30 @@ func (mr *MockBackendMockRecorder) RemoveMessageFromLocalCache(payload interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMessageFromLocalCache", reflect.TypeOf((*MockBackend)(nil).RemoveMessageFromLocalCache), payload)
 }
 
+// ReportInvalidMessage mocks base method
+func (m *MockBackend) ReportInvalidMessage(payload []byte, err error) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReportInvalidMessage", payload, err)
+}
+
+// ReportInvalidMessage indicates an expected call of ReportInvalidMessage
+func (mr *MockBackendMockRecorder) ReportInvalidMessage(payload, err interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportInvalidMessage", reflect.TypeOf((*MockBackend)(nil).ReportInvalidMessage), payload, err)
+}
+
+// ReportStaleMessage mocks base method
+func (m *MockBackend) ReportStaleMessage(payload []byte) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReportStaleMessage", payload)
+}
+
+// ReportStaleMessage indicates an expected call of ReportStaleMessage
+func (mr *MockBackendMockRecorder) ReportStaleMessage(payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportStaleMessage", reflect.TypeOf((*MockBackend)(nil).ReportStaleMessage), payload)
+}
+
 // MockTendermint is a mock of Tendermint interface
 type MockTendermint struct {
 	ctrl     *gomock.Controller
@@ -25,11 +25,12 @@ const MaxSizeBacklogUnchecked = 1000
 
 var (
 	// msgPriority is defined for calculating processing priority to speedup consensus
-	// msgProposal > msgPrecommit > msgPrevote
+	// msgProposal = msgProposalPart > msgPrecommit > msgPrevote
 	msgPriority = map[uint64]int{
-		msgProposal:  1,
-		msgPrecommit: 2,
-		msgPrevote:   3,
+		msgProposal:     1,
+		msgProposalPart: 1,
+		msgPrecommit:    2,
+		msgPrevote:      3,
 	}
 )
 
@@ -65,6 +66,15 @@ func (c *core) checkMessage(round int64, height *big.Int, step Step) error {
 	return nil
 }
 
+// msgStep returns the step of the messages of the given code, the parts of the
+// proposed block are sent along with the proposal.
+func msgStep(code uint64) Step {
+	if code == msgProposalPart {
+		return propose
+	}
+	return Step(code)
+}
+
 func (c *core) storeBacklog(msg *Message, src common.Address) {
 	logger := c.logger.New("from", src, "step", c.step)
 
@@ -133,7 +143,7 @@ func (c *core) processBacklog() {
 
 				r, _ := curMsg.Round()
 				h, _ := curMsg.Height()
-				err := c.checkMessage(r, h, Step(curMsg.Code))
+				err := c.checkMessage(r, h, msgStep(curMsg.Code))
 				if err == errFutureHeightMessage || err == errFutureRoundMessage || err == errFutureStepMessage {
 					logger.Debug("Futrue message in backlog", "msg", curMsg, "err", err)
 					continue
@@ -206,12 +206,7 @@ func TestStoreBacklog(t *testing.T) {
 			backlogs: make(map[common.Address][]*Message),
 		}
 
-		proposal := &Proposal{
-			Round:         1,
-			Height:        big.NewInt(2),
-			ValidRound:    1,
-			ProposalBlock: types.NewBlockWithHeader(&types.Header{}),
-		}
+		proposal := NewProposal(1, big.NewInt(2), 1, types.NewBlockWithHeader(&types.Header{}))
 
 		proposalPayload, err := Encode(proposal)
 		if err != nil {
@@ -241,12 +236,7 @@ func TestStoreBacklog(t *testing.T) {
 
 func TestProcessBacklog(t *testing.T) {
 	t.Run("valid proposal received", func(t *testing.T) {
-		proposal := &Proposal{
-			Round:         1,
-			Height:        big.NewInt(2),
-			ValidRound:    1,
-			ProposalBlock: types.NewBlockWithHeader(&types.Header{}),
-		}
+		proposal := NewProposal(1, big.NewInt(2), 1, types.NewBlockWithHeader(&types.Header{}))
 
 		proposalPayload, err := Encode(proposal)
 		if err != nil {
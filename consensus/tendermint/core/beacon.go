@@ -0,0 +1,80 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/consensus/tendermint/crypto"
+	"github.com/clearmatics/autonity/core/types"
+	ethcrypto "github.com/clearmatics/autonity/crypto"
+)
+
+// beaconPrefix separates the beacon signatures from any other signed data.
+var beaconPrefix = []byte("tendermint-beacon")
+
+// ErrInvalidBeacon is returned if the beacon of a header is not the signature
+// of its proposer over the seed of the parent header.
+var ErrInvalidBeacon = errors.New("invalid random beacon")
+
+// BeaconSeed returns the randomness produced by the header, which seeds the
+// proposer selection at the next height. Headers without a beacon, like the
+// genesis header, are their own seed.
+func BeaconSeed(header *types.Header) common.Hash {
+	if len(header.Beacon) == 0 {
+		return header.Hash()
+	}
+	return ethcrypto.Keccak256Hash(header.Beacon)
+}
+
+// PrepareBeacon returns the data signed by the proposer of the given height to
+// produce its beacon.
+func PrepareBeacon(parentSeed common.Hash, height *big.Int) []byte {
+	return append(append(append([]byte{}, beaconPrefix...), parentSeed.Bytes()...), common.LeftPadBytes(height.Bytes(), 32)...)
+}
+
+// VerifyBeacon checks that the beacon of the header has been signed with BLS
+// by its proposer over the seed of the parent header, unique signatures
+// prevent the proposers from grinding the beacon to bias the selection of the
+// next proposers. Proposers without a valid consensus key can't sign a beacon,
+// the seed of the parent header is carried over instead.
+func VerifyBeacon(header, parent *types.Header, proposer types.CommitteeMember) error {
+	if crypto.VerifyConsensusKey(proposer.ConsensusKey) != nil {
+		if !bytes.Equal(header.Beacon, BeaconSeed(parent).Bytes()) {
+			return ErrInvalidBeacon
+		}
+		return nil
+	}
+	data := PrepareBeacon(BeaconSeed(parent), header.Number)
+	if crypto.VerifySeal(proposer.ConsensusKey, data, header.Beacon) != nil {
+		return ErrInvalidBeacon
+	}
+	return nil
+}
+
+// BeaconProposer returns the proposer of the given height and round, the
+// committee members are sampled with a probability proportional to their
+// voting power from the seed of the parent header.
+func BeaconProposer(committee types.Committee, parentSeed common.Hash, height *big.Int, round int64) types.CommitteeMember {
+	total := new(big.Int)
+	for _, member := range committee {
+		total.Add(total, member.VotingPower)
+	}
+	if total.Sign() == 0 {
+		return committee[round%int64(len(committee))]
+	}
+	roundBytes := common.LeftPadBytes(big.NewInt(round).Bytes(), 32)
+	value := new(big.Int).SetBytes(ethcrypto.Keccak256(parentSeed.Bytes(), common.LeftPadBytes(height.Bytes(), 32), roundBytes))
+	index := value.Mod(value, total)
+
+	counter := new(big.Int)
+	for _, member := range committee {
+		counter.Add(counter, member.VotingPower)
+		if index.Cmp(counter) < 0 {
+			return member
+		}
+	}
+	// unreachable, the index is lower than the total voting power.
+	return committee[len(committee)-1]
+}
@@ -0,0 +1,129 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/clearmatics/autonity/common"
+	tendermintCrypto "github.com/clearmatics/autonity/consensus/tendermint/crypto"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/crypto"
+)
+
+func TestBeaconProposer(t *testing.T) {
+	members, _ := generateCommittee(4)
+	for i := range members {
+		members[i].VotingPower = big.NewInt(int64(i + 1))
+	}
+	seed := common.HexToHash("0x1")
+
+	t.Run("deterministic", func(t *testing.T) {
+		first := BeaconProposer(members, seed, big.NewInt(5), 2)
+		second := BeaconProposer(copyMembers(members), seed, big.NewInt(5), 2)
+		if first.Address != second.Address {
+			t.Fatalf("Expected %v, got %v", first.Address, second.Address)
+		}
+	})
+
+	t.Run("stake weighted", func(t *testing.T) {
+		selected := make(map[common.Address]int)
+		for round := int64(0); round < 10000; round++ {
+			selected[BeaconProposer(members, seed, big.NewInt(5), round).Address]++
+		}
+		// the member with the most voting power holds 40% of it
+		if count := selected[members[3].Address]; count < 3500 || count > 4500 {
+			t.Fatalf("Expected about 4000 selections, got %v", count)
+		}
+		if count := selected[members[0].Address]; count < 700 || count > 1300 {
+			t.Fatalf("Expected about 1000 selections, got %v", count)
+		}
+	})
+
+	t.Run("seeds and views are not interchangeable", func(t *testing.T) {
+		var differ bool
+		for h := int64(1); h < 20; h++ {
+			next := BeaconProposer(members, seed, big.NewInt(h+1), 0)
+			if BeaconProposer(members, seed, big.NewInt(h), 1).Address != next.Address {
+				differ = true
+			}
+		}
+		if !differ {
+			t.Fatalf("Expected (h, r+1) and (h+1, r) to select different proposers")
+		}
+	})
+
+	t.Run("committee", func(t *testing.T) {
+		parent := &types.Header{Number: big.NewInt(4), Committee: members, Beacon: []byte{0x1}}
+		set, err := newRandomBeaconCommittee(parent)
+		if err != nil {
+			t.Fatalf("Expected nil, got %v", err)
+		}
+		expected := BeaconProposer(members, BeaconSeed(parent), big.NewInt(5), 3)
+		if proposer := set.GetProposer(3); proposer.Address != expected.Address {
+			t.Fatalf("Expected %v, got %v", expected.Address, proposer.Address)
+		}
+		if _, err := newRandomBeaconCommittee(&types.Header{Number: big.NewInt(4)}); err != ErrEmptyCommitteeSet {
+			t.Fatalf("Expected %v, got %v", ErrEmptyCommitteeSet, err)
+		}
+	})
+}
+
+func TestVerifyBeacon(t *testing.T) {
+	members, keys := generateCommittee(2)
+	parent := &types.Header{Number: big.NewInt(4), Committee: members}
+	header := &types.Header{Number: big.NewInt(5)}
+	data := PrepareBeacon(BeaconSeed(parent), header.Number)
+
+	t.Run("seed carried over without a consensus key", func(t *testing.T) {
+		header.Beacon = BeaconSeed(parent).Bytes()
+		if err := VerifyBeacon(header, parent, members[0]); err != nil {
+			t.Fatalf("Expected nil, got %v", err)
+		}
+		// the proposers can't grind secp256k1 signatures to bias the seed
+		beacon, err := crypto.Sign(crypto.Keccak256(data), keys[members[0].Address])
+		if err != nil {
+			t.Fatal(err)
+		}
+		header.Beacon = beacon
+		if err := VerifyBeacon(header, parent, members[0]); err != ErrInvalidBeacon {
+			t.Fatalf("Expected %v, got %v", ErrInvalidBeacon, err)
+		}
+	})
+
+	t.Run("bls beacon", func(t *testing.T) {
+		blsKey, err := tendermintCrypto.DeriveBLSKey(keys[members[0].Address])
+		if err != nil {
+			t.Fatal(err)
+		}
+		member := members[0]
+		member.ConsensusKey = blsKey.ConsensusKey()
+		header.Beacon = blsKey.Sign(data)
+		if err := VerifyBeacon(header, parent, member); err != nil {
+			t.Fatalf("Expected nil, got %v", err)
+		}
+		// secp256k1 beacons are not accepted once a consensus key is registered
+		if header.Beacon, err = crypto.Sign(crypto.Keccak256(data), keys[members[0].Address]); err != nil {
+			t.Fatal(err)
+		}
+		if err := VerifyBeacon(header, parent, member); err != ErrInvalidBeacon {
+			t.Fatalf("Expected %v, got %v", ErrInvalidBeacon, err)
+		}
+	})
+
+	t.Run("beacon over another seed", func(t *testing.T) {
+		blsKey, err := tendermintCrypto.DeriveBLSKey(keys[members[0].Address])
+		if err != nil {
+			t.Fatal(err)
+		}
+		member := members[0]
+		member.ConsensusKey = blsKey.ConsensusKey()
+		header.Beacon = blsKey.Sign(PrepareBeacon(common.HexToHash("0x1"), header.Number))
+		if err := VerifyBeacon(header, parent, member); err != ErrInvalidBeacon {
+			t.Fatalf("Expected %v, got %v", ErrInvalidBeacon, err)
+		}
+		// members without a consensus key carry over the seed of the parent
+		if err := VerifyBeacon(header, parent, members[0]); err != ErrInvalidBeacon {
+			t.Fatalf("Expected %v, got %v", ErrInvalidBeacon, err)
+		}
+	})
+}
@@ -1,7 +1,9 @@
 package core
 
 import (
+	"bytes"
 	"errors"
+	"math/big"
 	"sort"
 	"sync"
 
@@ -209,6 +211,231 @@ func (w *weightedRandomSamplingCommittee) F() uint64 {
 	return bft.F(w.previousHeader.TotalVotingPower())
 }
 
+type randomBeaconCommittee struct {
+	members    types.Committee
+	parentSeed common.Hash
+	height     *big.Int
+	totalPower uint64
+	proposers  map[int64]types.CommitteeMember // cached computed values
+	mu         sync.Mutex
+}
+
+func newRandomBeaconCommittee(lastHeader *types.Header) (*randomBeaconCommittee, error) {
+	if len(lastHeader.Committee) == 0 {
+		return nil, ErrEmptyCommitteeSet
+	}
+	committee := &randomBeaconCommittee{
+		members:    copyMembers(lastHeader.Committee),
+		parentSeed: BeaconSeed(lastHeader),
+		height:     new(big.Int).Add(lastHeader.Number, common.Big1),
+		proposers:  make(map[int64]types.CommitteeMember),
+	}
+	sort.Sort(committee.members)
+	committee.totalPower = committee.members.TotalVotingPower()
+	return committee, nil
+}
+
+func (set *randomBeaconCommittee) Committee() types.Committee {
+	return copyMembers(set.members)
+}
+
+func (set *randomBeaconCommittee) GetByIndex(i int) (types.CommitteeMember, error) {
+	if i < 0 || i >= len(set.members) {
+		return types.CommitteeMember{}, consensus.ErrCommitteeMemberNotFound
+	}
+	return set.members[i], nil
+}
+
+func (set *randomBeaconCommittee) GetByAddress(addr common.Address) (int, types.CommitteeMember, error) {
+	for i, member := range set.members {
+		if addr == member.Address {
+			return i, member, nil
+		}
+	}
+	return -1, types.CommitteeMember{}, consensus.ErrCommitteeMemberNotFound
+}
+
+func (set *randomBeaconCommittee) GetProposer(round int64) types.CommitteeMember {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+
+	v, ok := set.proposers[round]
+	if !ok {
+		v = BeaconProposer(set.members, set.parentSeed, set.height, round)
+		set.proposers[round] = v
+	}
+	return v
+}
+
+func (set *randomBeaconCommittee) Quorum() uint64 {
+	return bft.Quorum(set.totalPower)
+}
+
+func (set *randomBeaconCommittee) F() uint64 {
+	return bft.F(set.totalPower)
+}
+
+type weightedRoundRobinCommittee struct {
+	members    types.Committee
+	priorities []*big.Int // priorities of the members at round 0
+	totalPower uint64
+	proposers  types.Committee // proposers of the rounds computed so far
+	mu         sync.Mutex
+}
+
+// newWeightedRoundRobinCommittee returns the committee deciding the height
+// following lastHeader, parent being the parent of lastHeader or nil if
+// lastHeader is the genesis header.
+func newWeightedRoundRobinCommittee(lastHeader, parent *types.Header) (*weightedRoundRobinCommittee, error) {
+	if len(lastHeader.Committee) == 0 {
+		return nil, ErrEmptyCommitteeSet
+	}
+	priorities := make(map[common.Address]*big.Int)
+	for i, p := range NextProposerPriorities(lastHeader, parent) {
+		priorities[lastHeader.Committee[i].Address] = p
+	}
+	committee := &weightedRoundRobinCommittee{
+		members: copyMembers(lastHeader.Committee),
+	}
+	sort.Sort(committee.members)
+	for _, member := range committee.members {
+		committee.priorities = append(committee.priorities, priorities[member.Address])
+	}
+	committee.totalPower = committee.members.TotalVotingPower()
+	return committee, nil
+}
+
+func (set *weightedRoundRobinCommittee) Committee() types.Committee {
+	return copyMembers(set.members)
+}
+
+func (set *weightedRoundRobinCommittee) GetByIndex(i int) (types.CommitteeMember, error) {
+	if i < 0 || i >= len(set.members) {
+		return types.CommitteeMember{}, consensus.ErrCommitteeMemberNotFound
+	}
+	return set.members[i], nil
+}
+
+func (set *weightedRoundRobinCommittee) GetByAddress(addr common.Address) (int, types.CommitteeMember, error) {
+	for i, member := range set.members {
+		if addr == member.Address {
+			return i, member, nil
+		}
+	}
+	return -1, types.CommitteeMember{}, consensus.ErrCommitteeMemberNotFound
+}
+
+func (set *weightedRoundRobinCommittee) GetProposer(round int64) types.CommitteeMember {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+
+	if round >= int64(len(set.proposers)) {
+		set.proposers = PriorityProposers(set.members, set.priorities, round)
+	}
+	return set.proposers[round]
+}
+
+func (set *weightedRoundRobinCommittee) Quorum() uint64 {
+	return bft.Quorum(set.totalPower)
+}
+
+func (set *weightedRoundRobinCommittee) F() uint64 {
+	return bft.F(set.totalPower)
+}
+
+// PriorityProposers returns the proposers of the rounds up to the given round
+// for the committee starting the height with the given priorities. At every
+// round each member gains a priority equal to its voting power, the member
+// with the highest priority proposes and loses the total voting power.
+func PriorityProposers(committee types.Committee, priorities []*big.Int, round int64) types.Committee {
+	current := copyPriorities(priorities)
+	proposers := make(types.Committee, 0, round+1)
+	for r := int64(0); r <= round; r++ {
+		proposers = append(proposers, committee[accumulatePriorities(committee, current)])
+	}
+	return proposers
+}
+
+// NextProposerPriorities returns the priorities of the committee of header at
+// the start of the next height. The priorities of header are accumulated over
+// the rounds of its height by the committee of parent, then carried over to
+// the members of the committee of header. Members joining the committee start
+// with the lowest priority so that leaving and joining again is not rewarded.
+// Priorities are shifted so that the lowest one is zero, the selection of the
+// proposers is not affected by the shift.
+func NextProposerPriorities(header, parent *types.Header) []*big.Int {
+	next := make([]*big.Int, len(header.Committee))
+	if parent == nil || len(header.ProposerPriorities) != len(parent.Committee) {
+		for i := range next {
+			next[i] = new(big.Int)
+		}
+		return next
+	}
+
+	current := copyPriorities(header.ProposerPriorities)
+	for r := uint64(0); r <= header.Round; r++ {
+		accumulatePriorities(parent.Committee, current)
+	}
+	carried := make(map[common.Address]*big.Int)
+	lowest := new(big.Int)
+	for i, member := range parent.Committee {
+		carried[member.Address] = current[i]
+		if i == 0 || current[i].Cmp(lowest) < 0 {
+			lowest = current[i]
+		}
+	}
+	for i, member := range header.Committee {
+		p, ok := carried[member.Address]
+		if !ok {
+			p = lowest
+		}
+		next[i] = new(big.Int).Set(p)
+	}
+
+	shift := new(big.Int)
+	for i := range next {
+		if i == 0 || next[i].Cmp(shift) < 0 {
+			shift.Set(next[i])
+		}
+	}
+	for i := range next {
+		next[i].Sub(next[i], shift)
+	}
+	return next
+}
+
+// accumulatePriorities runs a round of the priority accumulator and returns
+// the index of its proposer, ties are broken by the lowest address.
+func accumulatePriorities(committee types.Committee, priorities []*big.Int) int {
+	total := new(big.Int)
+	selected := 0
+	for i, member := range committee {
+		total.Add(total, member.VotingPower)
+		priorities[i].Add(priorities[i], member.VotingPower)
+		if i == 0 {
+			continue
+		}
+		switch priorities[i].Cmp(priorities[selected]) {
+		case 1:
+			selected = i
+		case 0:
+			if bytes.Compare(member.Address.Bytes(), committee[selected].Address.Bytes()) < 0 {
+				selected = i
+			}
+		}
+	}
+	priorities[selected].Sub(priorities[selected], total)
+	return selected
+}
+
+func copyPriorities(priorities []*big.Int) []*big.Int {
+	cpy := make([]*big.Int, len(priorities))
+	for i, p := range priorities {
+		cpy[i] = new(big.Int).Set(p)
+	}
+	return cpy
+}
+
 var ErrEmptyCommitteeSet = errors.New("committee set can't be empty")
 
 func copyMembers(members types.Committee) types.Committee {
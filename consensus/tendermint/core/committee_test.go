@@ -362,3 +362,79 @@ func getTotalPowerDistribution(p, n int64) (int64, int64) {
 func genRandUint64(min, max int) int64 {
 	return int64(rand.Intn(max-min+1) + min)
 }
+
+func TestWeightedRoundRobin(t *testing.T) {
+	members, _ := generateCommittee(4)
+	for i := range members {
+		members[i].VotingPower = big.NewInt(int64(i + 1))
+	}
+	genesis := &types.Header{Number: big.NewInt(0), Committee: members}
+
+	t.Run("proposers in proportion to voting power", func(t *testing.T) {
+		set, err := newWeightedRoundRobinCommittee(genesis, nil)
+		require.NoError(t, err)
+		// any window of total voting power rounds, starting from equal priorities
+		for start := 0; start < 30; start += 10 {
+			selected := make(map[common.Address]int64)
+			for r := start; r < start+10; r++ {
+				selected[set.GetProposer(int64(r)).Address]++
+			}
+			for _, m := range members {
+				assert.Equal(t, m.VotingPower.Int64(), selected[m.Address], "window %d", start)
+			}
+		}
+		// highest voting power proposes first
+		assert.Equal(t, members[3].Address, set.GetProposer(0).Address)
+	})
+
+	t.Run("priorities carried over heights", func(t *testing.T) {
+		// a chain deciding every height at round 0 elects the same proposers as
+		// a single height going through the same number of rounds.
+		expected := PriorityProposers(members, NextProposerPriorities(genesis, nil), 19)
+		chain := []*types.Header{genesis}
+		for h := 1; h <= 20; h++ {
+			var parent *types.Header
+			if h > 1 {
+				parent = chain[h-2]
+			}
+			set, err := newWeightedRoundRobinCommittee(chain[h-1], parent)
+			require.NoError(t, err)
+			assert.Equal(t, expected[h-1].Address, set.GetProposer(0).Address, "height %d", h)
+
+			chain = append(chain, &types.Header{
+				Number:             big.NewInt(int64(h)),
+				Committee:          members,
+				ProposerPriorities: NextProposerPriorities(chain[h-1], parent),
+			})
+		}
+	})
+
+	t.Run("rounds of the parent height are accounted", func(t *testing.T) {
+		header := &types.Header{Number: big.NewInt(1), Committee: members, Round: 2, ProposerPriorities: NextProposerPriorities(genesis, nil)}
+		expected := PriorityProposers(members, header.ProposerPriorities, 3)[3]
+		set, err := newWeightedRoundRobinCommittee(header, genesis)
+		require.NoError(t, err)
+		assert.Equal(t, expected.Address, set.GetProposer(0).Address)
+	})
+
+	t.Run("joining members start with the lowest priority", func(t *testing.T) {
+		newcomer, _ := generateCommittee(1)
+		newcomer[0].VotingPower = big.NewInt(1)
+		header := &types.Header{
+			Number:             big.NewInt(1),
+			Committee:          append(copyMembers(members[1:]), newcomer[0]),
+			ProposerPriorities: NextProposerPriorities(genesis, nil),
+		}
+		priorities := NextProposerPriorities(header, genesis)
+		require.Len(t, priorities, 4)
+		assert.Equal(t, int64(0), priorities[3].Int64())
+		for _, p := range priorities {
+			assert.True(t, p.Sign() >= 0)
+		}
+	})
+
+	t.Run("empty committee", func(t *testing.T) {
+		_, err := newWeightedRoundRobinCommittee(&types.Header{Number: big.NewInt(0)}, nil)
+		assert.Equal(t, ErrEmptyCommitteeSet, err)
+	})
+}
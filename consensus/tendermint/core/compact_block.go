@@ -0,0 +1,107 @@
+package core
+
+import (
+	"context"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/rlp"
+	"github.com/clearmatics/autonity/trie"
+)
+
+// ShortTxHash is the prefix of the hash of a transaction, the transactions of
+// a compact proposal are referenced by their short hashes.
+type ShortTxHash [8]byte
+
+func NewShortTxHash(hash common.Hash) ShortTxHash {
+	var s ShortTxHash
+	copy(s[:], hash[:len(s)])
+	return s
+}
+
+// compactBlock is the encoding of the block of a compact proposal, most of its
+// transactions are already in the pool of the members which only fetch the
+// missing ones. The blocks have no uncles.
+type compactBlock struct {
+	Header   *types.Header
+	TxHashes []ShortTxHash
+}
+
+func newCompactBlock(block *types.Block) *compactBlock {
+	hashes := make([]ShortTxHash, len(block.Transactions()))
+	for i, tx := range block.Transactions() {
+		hashes[i] = NewShortTxHash(tx.Hash())
+	}
+	return &compactBlock{Header: block.Header(), TxHashes: hashes}
+}
+
+// assembleCompactBlock reassembles the compact block of the proposal from its
+// parts, see assembleParts.
+func assembleCompactBlock(proposal *Proposal, parts map[uint64]*ProposalPart) (*compactBlock, error) {
+	enc, err := assembleParts(proposal, parts)
+	if err != nil {
+		return nil, err
+	}
+	compact := new(compactBlock)
+	if err := rlp.DecodeBytes(enc, compact); err != nil {
+		return nil, errInvalidProposalParts
+	}
+	if compact.Header == nil || compact.Header.Hash() != proposal.BlockHash {
+		return nil, errInvalidProposalParts
+	}
+	return compact, nil
+}
+
+// block returns the block of the transactions, which must match the
+// transactions root of the header. The short hashes of a transaction of the
+// pool could collide with the one of the block.
+func (b *compactBlock) block(txs []*types.Transaction) (*types.Block, error) {
+	if types.DeriveSha(types.Transactions(txs), trie.NewStackTrie(nil)) != b.Header.TxHash {
+		return nil, errInvalidProposalParts
+	}
+	return types.NewBlockWithHeader(b.Header).WithBody(txs, nil), nil
+}
+
+// rebuildBlock rebuilds the block of a compact proposal from its parts and the
+// transactions known to the backend. The missing transactions are asked for,
+// errIncompleteProposal is returned until they are received.
+func (c *core) rebuildBlock(proposal *Proposal, parts map[uint64]*ProposalPart, proposer common.Address) (*types.Block, error) {
+	compact, err := assembleCompactBlock(proposal, parts)
+	if err != nil {
+		return nil, err
+	}
+	txs := c.backend.LookupTransactions(proposal.BlockHash, compact.TxHashes)
+	missing := make(map[uint64]ShortTxHash)
+	for i, tx := range txs {
+		if tx == nil {
+			missing[uint64(i)] = compact.TxHashes[i]
+		}
+	}
+	if len(missing) > 0 {
+		c.logger.Debug("Fetching the missing transactions of a compact proposal", "hash", proposal.BlockHash, "missing", len(missing), "total", len(txs))
+		c.backend.RequestTransactions(proposer, proposal.BlockHash, missing)
+		return nil, errIncompleteProposal
+	}
+	block, err := compact.block(txs)
+	if err != nil {
+		return nil, err
+	}
+	// the other members may fetch the transactions from us as well
+	c.backend.ServeTransactions(block)
+	return block, nil
+}
+
+// handleProposalTransactions handles again the proposals held while the
+// transactions of their block were fetched.
+func (c *core) handleProposalTransactions(ctx context.Context, hash common.Hash) {
+	for _, r := range c.messages.getRounds() {
+		roundMsgs := c.messages.getOrCreate(r)
+		proposalMsg, proposal := roundMsgs.ProposalMsg(), roundMsgs.Proposal()
+		if proposalMsg == nil || proposal.BlockHash != hash || proposal.ProposalBlock != nil {
+			continue
+		}
+		if err := c.handleProposal(ctx, proposalMsg); err != nil && err != errIncompleteProposal {
+			c.logger.Debug("Failed to handle rebuilt proposal", "err", err)
+		}
+	}
+}
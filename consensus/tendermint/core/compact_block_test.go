@@ -0,0 +1,113 @@
+package core
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/trie"
+	"github.com/golang/mock/gomock"
+)
+
+func TestCompactProposal(t *testing.T) {
+	addr := common.HexToAddress("0x0123456789")
+	txs := make([]*types.Transaction, 3)
+	for i := range txs {
+		txs[i] = types.NewTransaction(uint64(i), common.Address{}, big.NewInt(1), 21000, big.NewInt(1), nil)
+	}
+	block := types.NewBlock(&types.Header{Number: big.NewInt(1)}, txs, nil, nil, new(trie.Trie))
+	proposal := NewCompactProposal(2, big.NewInt(1), -1, block)
+	if !proposal.PartSet.Compact {
+		t.Fatalf("Expected a compact proposal")
+	}
+	encoded, err := Encode(proposal)
+	if err != nil {
+		t.Fatalf("Expected <nil>, got %v", err)
+	}
+	proposalMsg := func() *Message {
+		return &Message{Code: msgProposal, Msg: encoded, Address: addr, CommittedSeal: []byte{}, power: 1}
+	}
+
+	// newCore returns a core holding the parts of the proposal.
+	newCore := func(t *testing.T, backendMock *MockBackend) *core {
+		c := newPartsTestCore(t, backendMock, addr)
+		for _, part := range proposal.Parts() {
+			enc, err := Encode(part)
+			if err != nil {
+				t.Fatalf("Expected <nil>, got %v", err)
+			}
+			partMsg := &Message{Code: msgProposalPart, Msg: enc, Address: addr, CommittedSeal: []byte{}, power: 1}
+			if err := c.handleProposalPart(context.Background(), partMsg); err != nil {
+				t.Fatalf("Expected <nil>, got %v", err)
+			}
+		}
+		return c
+	}
+	// the rebuilt block is served, verified and prevoted for
+	expectPrevote := func(backendMock *MockBackend) {
+		backendMock.EXPECT().ServeTransactions(gomock.Any())
+		backendMock.EXPECT().VerifyProposal(gomock.Any()).Do(func(b types.Block) {
+			if b.Hash() != block.Hash() || len(b.Transactions()) != len(txs) {
+				t.Fatalf("Expected the block to be rebuilt")
+			}
+		})
+		backendMock.EXPECT().Sign(gomock.Any())
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any(), gomock.Any())
+	}
+
+	t.Run("block rebuilt from the pool", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		backendMock := NewMockBackend(ctrl)
+		c := newCore(t, backendMock)
+
+		backendMock.EXPECT().LookupTransactions(block.Hash(), gomock.Len(len(txs))).Return(txs)
+		expectPrevote(backendMock)
+		if err := c.handleProposal(context.Background(), proposalMsg()); err != nil {
+			t.Fatalf("Expected <nil>, got %v", err)
+		}
+		if c.step != prevote {
+			t.Fatalf("Expected step %v, got %v", prevote, c.step)
+		}
+	})
+
+	t.Run("missing transactions are fetched", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		backendMock := NewMockBackend(ctrl)
+		c := newCore(t, backendMock)
+
+		backendMock.EXPECT().LookupTransactions(block.Hash(), gomock.Any()).Return([]*types.Transaction{txs[0], nil, txs[2]})
+		backendMock.EXPECT().RequestTransactions(addr, block.Hash(), map[uint64]ShortTxHash{1: NewShortTxHash(txs[1].Hash())})
+		if err := c.handleProposal(context.Background(), proposalMsg()); err != errIncompleteProposal {
+			t.Fatalf("Expected %v, got %v", errIncompleteProposal, err)
+		}
+		if c.step != propose {
+			t.Fatalf("Expected step %v, got %v", propose, c.step)
+		}
+
+		backendMock.EXPECT().LookupTransactions(block.Hash(), gomock.Any()).Return(txs)
+		expectPrevote(backendMock)
+		c.handleProposalTransactions(context.Background(), block.Hash())
+		if hash := c.curRoundMessages.GetProposalHash(); hash != block.Hash() {
+			t.Fatalf("Expected %v, got %v", block.Hash(), hash)
+		}
+		if c.step != prevote {
+			t.Fatalf("Expected step %v, got %v", prevote, c.step)
+		}
+	})
+
+	t.Run("colliding transaction", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		backendMock := NewMockBackend(ctrl)
+		c := newCore(t, backendMock)
+
+		backendMock.EXPECT().LookupTransactions(block.Hash(), gomock.Any()).Return([]*types.Transaction{txs[0], txs[0], txs[2]})
+		if err := c.handleProposal(context.Background(), proposalMsg()); err != errInvalidProposalParts {
+			t.Fatalf("Expected %v, got %v", errInvalidProposalParts, err)
+		}
+	})
+}
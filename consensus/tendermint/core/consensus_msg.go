@@ -32,16 +32,31 @@ type ConsensusMsg interface {
 	GetHeight() *big.Int
 }
 
+// PartSetHeader commits to the parts the rlp encoded block of a proposal is
+// split into, Root is the merkle root of the parts. The parts of a compact
+// proposal encode the block without its transactions, see compactBlock.
+type PartSetHeader struct {
+	Total   uint64
+	Root    common.Hash
+	Compact bool
+}
+
+// Proposal is sent without its block, which is gossiped in parts, see
+// ProposalPart. ProposalBlock is only set once the block is reassembled.
 type Proposal struct {
 	Round         int64
 	Height        *big.Int
 	ValidRound    int64
+	BlockHash     common.Hash
+	PartSet       PartSetHeader
 	ProposalBlock *types.Block
+
+	parts []*ProposalPart // the parts of our own proposals
 }
 
 func (p *Proposal) String() string {
-	return fmt.Sprintf("{Round: %v, Height: %v, ValidRound: %v, ProposedBlockHash: %v}",
-		p.Round, p.Height.Uint64(), p.ValidRound, p.ProposalBlock.Hash().String())
+	return fmt.Sprintf("{Round: %v, Height: %v, ValidRound: %v, ProposedBlockHash: %v, Parts: %v}",
+		p.Round, p.Height.Uint64(), p.ValidRound, p.BlockHash.String(), p.PartSet.Total)
 }
 
 func (p *Proposal) GetRound() int64 {
@@ -52,21 +67,52 @@ func (p *Proposal) GetHeight() *big.Int {
 	return p.Height
 }
 
+// Parts returns the parts of the block of a proposal created with NewProposal.
+func (p *Proposal) Parts() []*ProposalPart {
+	return p.parts
+}
+
 func NewProposal(r int64, h *big.Int, vr int64, p *types.Block) *Proposal {
-	return &Proposal{
+	return newProposal(r, h, vr, p, false)
+}
+
+// NewCompactProposal returns a proposal whose block references its
+// transactions by their short hashes, the members rebuild it from their pool.
+func NewCompactProposal(r int64, h *big.Int, vr int64, p *types.Block) *Proposal {
+	return newProposal(r, h, vr, p, true)
+}
+
+func newProposal(r int64, h *big.Int, vr int64, p *types.Block, compact bool) *Proposal {
+	partSet, parts := splitBlock(p, compact)
+	proposal := &Proposal{
 		Round:         r,
 		Height:        h,
 		ValidRound:    vr,
+		BlockHash:     p.Hash(),
+		PartSet:       partSet,
 		ProposalBlock: p,
+		parts:         make([]*ProposalPart, len(parts)),
 	}
+	for i, part := range parts {
+		proposal.parts[i] = &ProposalPart{
+			Round:     r,
+			Height:    h,
+			BlockHash: proposal.BlockHash,
+			Index:     uint64(i),
+			Total:     partSet.Total,
+			Bytes:     part.bytes,
+			Proof:     part.proof,
+		}
+	}
+	return proposal
 }
 
 // RLP encoding doesn't support negative big.Int, so we have to pass one additionnal field to represents validRound = -1.
 // Note that we could have as well indexed rounds starting by 1, but we want to stay close as possible to the spec.
 func (p *Proposal) EncodeRLP(w io.Writer) error {
-	if p.ProposalBlock == nil {
+	if p.PartSet.Total == 0 {
 		// Should never happen
-		return errors.New("encoderlp proposal with nil block")
+		return errors.New("encoderlp proposal without parts")
 	}
 
 	isValidRoundNil := false
@@ -83,7 +129,8 @@ func (p *Proposal) EncodeRLP(w io.Writer) error {
 		p.Height,
 		validRound,
 		isValidRoundNil,
-		p.ProposalBlock,
+		p.BlockHash,
+		p.PartSet,
 	})
 }
 
@@ -94,7 +141,8 @@ func (p *Proposal) DecodeRLP(s *rlp.Stream) error {
 		Height          *big.Int
 		ValidRound      uint64
 		IsValidRoundNil bool
-		ProposalBlock   *types.Block
+		BlockHash       common.Hash
+		PartSet         PartSetHeader
 	}
 
 	if err := s.Decode(&proposal); err != nil {
@@ -114,18 +162,78 @@ func (p *Proposal) DecodeRLP(s *rlp.Stream) error {
 		return errors.New("bad proposal with invalid rounds")
 	}
 
-	if proposal.ProposalBlock == nil {
-		return errors.New("bad proposal with nil decoded block")
+	if proposal.PartSet.Total == 0 || proposal.PartSet.Total > maxProposalParts {
+		return errors.New("bad proposal with invalid number of parts")
 	}
 
 	p.Round = int64(proposal.Round)
 	p.Height = proposal.Height
 	p.ValidRound = validRound
-	p.ProposalBlock = proposal.ProposalBlock
+	p.BlockHash = proposal.BlockHash
+	p.PartSet = proposal.PartSet
+	p.ProposalBlock = nil
 
 	return nil
 }
 
+// ProposalPart is a part of the rlp encoded block of a proposal, along with the
+// merkle proof of its inclusion in the part set of the proposal.
+type ProposalPart struct {
+	Round     int64
+	Height    *big.Int
+	BlockHash common.Hash
+	Index     uint64
+	Total     uint64
+	Bytes     []byte
+	Proof     []common.Hash
+}
+
+func (p *ProposalPart) GetRound() int64 {
+	return p.Round
+}
+
+func (p *ProposalPart) GetHeight() *big.Int {
+	return p.Height
+}
+
+// EncodeRLP serializes p into the Ethereum RLP format.
+func (p *ProposalPart) EncodeRLP(w io.Writer) error {
+	return rlp.Encode(w, []interface{}{uint64(p.Round), p.Height, p.BlockHash, p.Index, p.Total, p.Bytes, p.Proof})
+}
+
+// DecodeRLP implements rlp.Decoder, and load the consensus fields from a RLP stream.
+func (p *ProposalPart) DecodeRLP(s *rlp.Stream) error {
+	var part struct {
+		Round     uint64
+		Height    *big.Int
+		BlockHash common.Hash
+		Index     uint64
+		Total     uint64
+		Bytes     []byte
+		Proof     []common.Hash
+	}
+
+	if err := s.Decode(&part); err != nil {
+		return err
+	}
+	if part.Round > MaxRound || part.Total == 0 || part.Total > maxProposalParts || part.Index >= part.Total ||
+		len(part.Bytes) > proposalPartSize || len(part.Proof) > maxProposalPartProof {
+		return errInvalidMessage
+	}
+	p.Round = int64(part.Round)
+	p.Height = part.Height
+	p.BlockHash = part.BlockHash
+	p.Index = part.Index
+	p.Total = part.Total
+	p.Bytes = part.Bytes
+	p.Proof = part.Proof
+	return nil
+}
+
+func (p *ProposalPart) String() string {
+	return fmt.Sprintf("{Round: %v, Height: %v, ProposedBlockHash: %v, Part: %v/%v}", p.Round, p.Height, p.BlockHash.String(), p.Index, p.Total)
+}
+
 type Vote struct {
 	Round             int64
 	Height            *big.Int
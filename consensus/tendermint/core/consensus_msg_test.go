@@ -44,6 +44,14 @@ func TestProposalEncodeDecode(t *testing.T) {
 		if decProposal.ValidRound != proposal.ValidRound {
 			t.Errorf("Valid Rounds are not the same: have %v, want %v", decProposal.ValidRound, proposal.ValidRound)
 		}
+
+		if decProposal.BlockHash != proposal.ProposalBlock.Hash() || decProposal.PartSet != proposal.PartSet {
+			t.Errorf("Blocks are not the same: have %v %v, want %v %v", decProposal.BlockHash, decProposal.PartSet, proposal.ProposalBlock.Hash(), proposal.PartSet)
+		}
+
+		if decProposal.ProposalBlock != nil {
+			t.Errorf("Block decoded with the proposal: have %v, want nil", decProposal.ProposalBlock)
+		}
 	})
 
 	t.Run("Valid round is negative", func(t *testing.T) {
@@ -82,6 +90,38 @@ func TestProposalEncodeDecode(t *testing.T) {
 
 }
 
+func TestProposalPartEncodeDecode(t *testing.T) {
+	// the block is split in two parts
+	proposal := NewProposal(1, big.NewInt(2), -1, types.NewBlockWithHeader(&types.Header{Extra: make([]byte, proposalPartSize)}))
+	part := proposal.Parts()[1]
+
+	buf := &bytes.Buffer{}
+	if err := part.EncodeRLP(buf); err != nil {
+		t.Fatalf("have %v, want nil", err)
+	}
+
+	decPart := &ProposalPart{}
+	if err := decPart.DecodeRLP(rlp.NewStream(buf, 0)); err != nil {
+		t.Fatalf("have %v, want nil", err)
+	}
+
+	if !reflect.DeepEqual(part, decPart) {
+		t.Errorf("Parts are not the same: have %v, want %v", decPart, part)
+	}
+
+	t.Run("part out of the part set", func(t *testing.T) {
+		invalid := *part
+		invalid.Index = invalid.Total
+		enc, err := rlp.EncodeToBytes(&invalid)
+		if err != nil {
+			t.Fatalf("have %v, want nil", err)
+		}
+		if err := rlp.DecodeBytes(enc, &ProposalPart{}); err != errInvalidMessage {
+			t.Errorf("have %v, want %v", err, errInvalidMessage)
+		}
+	})
+}
+
 func TestVoteEncodeDecode(t *testing.T) {
 	vote := &Vote{
 		Round:             1,
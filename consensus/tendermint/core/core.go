@@ -23,13 +23,16 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"runtime"
 	"sync"
 	"time"
 
 	"github.com/clearmatics/autonity/autonity"
 	"github.com/clearmatics/autonity/common"
 	"github.com/clearmatics/autonity/consensus/tendermint/config"
+	"github.com/clearmatics/autonity/consensus/tendermint/crypto"
 	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/ethdb"
 	"github.com/clearmatics/autonity/event"
 	"github.com/clearmatics/autonity/log"
 )
@@ -57,6 +60,16 @@ var (
 	errInvalidSenderOfCommittedSeal = errors.New("invalid sender of committed seal")
 	// errFailedDecodeProposal is returned when the PROPOSAL message is malformed.
 	errFailedDecodeProposal = errors.New("failed to decode PROPOSAL")
+	// errFailedDecodeProposalPart is returned when the PROPOSAL PART message is malformed.
+	errFailedDecodeProposalPart = errors.New("failed to decode PROPOSAL PART")
+	// errIncompleteProposal is returned when a proposal is received before all the parts of its block.
+	errIncompleteProposal = errors.New("incomplete proposal")
+	// errInvalidProposalPart is returned when a part doesn't belong to the proposal of its round.
+	errInvalidProposalPart = errors.New("invalid proposal part")
+	// errDuplicateProposalPart is returned when a part of the same index is held already.
+	errDuplicateProposalPart = errors.New("duplicate proposal part")
+	// errInvalidProposalParts is returned when the parts of a proposal don't make up its block.
+	errInvalidProposalParts = errors.New("proposal parts don't make up the proposed block")
 	// errFailedDecodePrevote is returned when the PREVOTE message is malformed.
 	errFailedDecodePrevote = errors.New("failed to decode PREVOTE")
 	// errFailedDecodePrecommit is returned when the PRECOMMIT message is malformed.
@@ -75,8 +88,9 @@ const (
 	MaxRound = 99 // consequence of backlog priority
 )
 
-// New creates an Tendermint consensus core
-func New(backend Backend, config *config.Config) *core {
+// New creates an Tendermint consensus core, db is used to persist the consensus write-ahead log
+// and the evidence of misbehaving committee members.
+func New(backend Backend, config *config.Config, db ethdb.KeyValueStore) *core {
 	addr := backend.Address()
 	logger := log.New("addr", addr.String())
 	messagesMap := newMessagesMap()
@@ -84,6 +98,9 @@ func New(backend Backend, config *config.Config) *core {
 	return &core{
 		proposerPolicy:        config.ProposerPolicy,
 		blockPeriod:           config.BlockPeriod,
+		maxEmptyBlockInterval: config.MaxEmptyBlockInterval,
+		compactProposals:      config.CompactProposals,
+		timeouts:              newTimeoutConfig(config),
 		address:               addr,
 		logger:                logger,
 		backend:               backend,
@@ -101,14 +118,27 @@ func New(backend Backend, config *config.Config) *core {
 		proposeTimeout:        newTimeout(propose, logger),
 		prevoteTimeout:        newTimeout(prevote, logger),
 		precommitTimeout:      newTimeout(precommit, logger),
+		db:                    db,
+		wal:                   newWAL(db),
+		verifier:              newMsgVerifier(crypto.CheckValidatorSignature, runtime.NumCPU()),
 	}
 }
 
 type core struct {
 	proposerPolicy config.ProposerPolicy
 	blockPeriod    uint64
+	timeouts       *timeoutConfig
 	address        common.Address
 	logger         log.Logger
+	// maxEmptyBlockInterval is the maximum time in seconds the proposers wait
+	// for transactions before proposing an empty block, see emptyBlockDelay.
+	maxEmptyBlockInterval uint64
+	// emptyBlockDelayed is set if the propose timeout has been extended at the
+	// current height, the propose step durations are then meaningless.
+	emptyBlockDelayed bool
+	// compactProposals is set to propose the blocks without the transactions
+	// the members already have, see NewCompactProposal.
+	compactProposals bool
 
 	backend Backend
 	cancel  context.CancelFunc
@@ -161,6 +191,14 @@ type core struct {
 	futureRoundChange map[int64]map[common.Address]uint64
 
 	autonityContract *autonity.Contract
+
+	// db stores the evidence of misbehaving committee members.
+	db ethdb.KeyValueStore
+	// wal persists the signed messages and lock state of the current height.
+	wal *wal
+	// verifier checks the signatures of the messages received ahead of the
+	// main event loop.
+	verifier *msgVerifier
 }
 
 func (c *core) GetCurrentHeightMessages() []*Message {
@@ -197,6 +235,12 @@ func (c *core) broadcast(ctx context.Context, msg *Message) {
 		return
 	}
 
+	// The message must be persisted before leaving the node, otherwise we could sign a conflicting one after a restart.
+	if err = c.persistState(msg); err != nil {
+		logger.Error("Failed to write message to WAL", "msg", msg, "err", err)
+		return
+	}
+
 	// Broadcast payload
 	logger.Debug("broadcasting", "msg", msg.String())
 	if err = c.backend.Broadcast(ctx, c.committeeSet().Committee(), payload); err != nil {
@@ -234,6 +278,22 @@ func (c *core) commit(round int64, messages *roundMessages) {
 
 	c.logger.Info("commit a block", "hash", proposal.ProposalBlock.Header().Hash())
 
+	if c.sealsAggregated() {
+		seals := make(map[common.Address][]byte)
+		for _, v := range messages.CommitedSeals(proposal.ProposalBlock.Hash()) {
+			seals[v.Address] = v.CommittedSeal
+		}
+		seal, err := crypto.AggregateSeals(c.committeeSet().Committee(), seals)
+		if err != nil {
+			c.logger.Error("failed to aggregate the committed seals", "err", err)
+			return
+		}
+		if err := c.backend.CommitAggregated(proposal.ProposalBlock, round, seal); err != nil {
+			c.logger.Error("failed to commit a block", "err", err)
+		}
+		return
+	}
+
 	committedSeals := make([][]byte, 0)
 	for _, v := range messages.CommitedSeals(proposal.ProposalBlock.Hash()) {
 		seal := make([]byte, types.BFTExtraSeal)
@@ -247,6 +307,16 @@ func (c *core) commit(round int64, messages *roundMessages) {
 	}
 }
 
+// sealsAggregated reports whether the committed seals of the current height are
+// BLS signatures aggregated in the block header.
+func (c *core) sealsAggregated() bool {
+	set := c.committeeSet()
+	if set == nil {
+		return false
+	}
+	return crypto.SealsAggregated(set.Committee())
+}
+
 // Metric collecton of round change and height change.
 func (c *core) measureHeightRoundMetrics(round int64) {
 	if round == 0 {
@@ -291,6 +361,11 @@ func (c *core) startRound(ctx context.Context, round int64) {
 		c.sendProposal(ctx, p)
 	} else {
 		timeoutDuration := c.timeoutPropose(round)
+		if delay := c.emptyBlockDelay(); delay > 0 {
+			// the proposer may be waiting for transactions.
+			timeoutDuration += delay
+			c.emptyBlockDelayed = true
+		}
 		c.proposeTimeout.scheduleTimeout(timeoutDuration, round, c.Height(), c.onTimeoutPropose)
 		c.logger.Debug("Scheduled Propose Timeout", "Timeout Duration", timeoutDuration)
 	}
@@ -321,16 +396,41 @@ func (c *core) setInitialState(r int64) {
 			}
 		case config.WeightedRandomSampling:
 			committeeSet = newWeightedRandomSamplingCommittee(lastBlockMined, c.autonityContract, c.backend.BlockChain())
+		case config.RandomBeacon:
+			committeeSet, err = newRandomBeaconCommittee(lastHeader)
+			if err != nil {
+				panic(fmt.Sprintf("failed to construct committee %v", err))
+			}
+		case config.WeightedRoundRobin:
+			var parent *types.Header
+			if !lastHeader.IsGenesis() {
+				parent = c.backend.BlockChain().GetHeader(lastHeader.ParentHash, lastHeader.Number.Uint64()-1)
+				if parent == nil {
+					panic(fmt.Sprintf("unable to retrieve the parent of header %q", lastHeader.Hash()))
+				}
+			}
+			committeeSet, err = newWeightedRoundRobinCommittee(lastHeader, parent)
+			if err != nil {
+				panic(fmt.Sprintf("failed to construct committee %v", err))
+			}
 		default:
 			panic(fmt.Sprintf("unrecognised proposer policy %q", c.proposerPolicy))
 		}
 
 		c.lastHeader = lastHeader
 		c.setCommitteeSet(committeeSet)
+		// the node signs with the consensus key of the new committee, in case
+		// it has been rotated.
+		c.address = c.backend.Address()
 		c.lockedRound = -1
 		c.lockedValue = nil
 		c.validRound = -1
 		c.validValue = nil
+		// restore the lock state in case we were restarted in the middle of this height
+		if state := c.wal.load(c.Height()); state != nil {
+			c.lockedRound, c.lockedValue = state.LockedRound, state.LockedValue
+			c.validRound, c.validValue = state.ValidRound, state.ValidValue
+		}
 		c.messages.reset()
 		c.futureRoundChange = make(map[int64]map[common.Address]uint64)
 	}
@@ -338,6 +438,9 @@ func (c *core) setInitialState(r int64) {
 	c.proposeTimeout.reset(propose)
 	c.prevoteTimeout.reset(prevote)
 	c.precommitTimeout.reset(precommit)
+	if r == 0 {
+		c.adaptTimeouts()
+	}
 	c.curRoundMessages = c.messages.getOrCreate(r)
 	c.sentProposal = false
 	c.sentPrevote = false
@@ -347,11 +450,15 @@ func (c *core) setInitialState(r int64) {
 }
 
 func (c *core) acceptVote(roundMsgs *roundMessages, step Step, hash common.Hash, msg Message) {
+	var conflicting *Message
 	switch step {
 	case prevote:
-		roundMsgs.AddPrevote(hash, msg)
+		conflicting = roundMsgs.AddPrevote(hash, msg)
 	case precommit:
-		roundMsgs.AddPrecommit(hash, msg)
+		conflicting = roundMsgs.AddPrecommit(hash, msg)
+	}
+	if conflicting != nil {
+		c.recordEvidence(conflicting, &msg)
 	}
 }
 
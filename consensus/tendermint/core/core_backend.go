@@ -26,11 +26,19 @@ type Backend interface {
 	// The delivered proposal will be put into blockchain.
 	Commit(proposalBlock *types.Block, round int64, seals [][]byte) error
 
+	// CommitAggregated delivers an approved proposal to backend with the
+	// aggregation of the BLS committed seals.
+	CommitAggregated(proposalBlock *types.Block, round int64, seal types.AggregatedSeal) error
+
 	GetContractABI() string
 
 	// Gossip sends a message to all validators (exclude self)
 	Gossip(ctx context.Context, committee types.Committee, payload []byte)
 
+	// GossipParts spreads the parts of a proposed block among the validators
+	// (exclude self), each validator receives a few parts and gossips them.
+	GossipParts(ctx context.Context, committee types.Committee, payloads [][]byte)
+
 	KnownMsgHash() []common.Hash
 
 	HandleUnhandledMsgs(ctx context.Context)
@@ -38,17 +46,36 @@ type Backend interface {
 	// LastCommittedProposal retrieves latest committed proposal and the address of proposer
 	LastCommittedProposal() (*types.Block, common.Address)
 
+	// LookupTransactions returns the transactions of the block of a compact
+	// proposal from the pool and from those fetched, nil if missing.
+	LookupTransactions(blockHash common.Hash, hashes []ShortTxHash) []*types.Transaction
+
 	Post(ev interface{})
 
+	// RequestTransactions asks the proposer of a compact proposal for the
+	// missing transactions of its block, an events.ProposalTransactionsEvent
+	// is posted once some are received.
+	RequestTransactions(proposer common.Address, blockHash common.Hash, missing map[uint64]ShortTxHash)
+
+	// ServeTransactions makes the transactions of the block available to the
+	// members rebuilding it from a compact proposal.
+	ServeTransactions(block *types.Block)
+
 	// Setter for proposed block hash
 	SetProposedBlockHash(hash common.Hash)
 
 	// Sign signs input data with the backend's private key
 	Sign([]byte) ([]byte, error)
 
+	// SignCommittedSeal signs a committed seal, with the BLS consensus key if
+	// the seals are aggregated or with the backend's private key otherwise
+	SignCommittedSeal(seal []byte, aggregated bool) ([]byte, error)
+
 	Subscribe(types ...interface{}) *event.TypeMuxSubscription
 
-	SyncPeer(address common.Address)
+	// SyncPeer sends the messages of the current height to the peer, only the
+	// ones missing according to the request if any.
+	SyncPeer(address common.Address, request *SyncRequest)
 
 	// VerifyProposal verifies the proposal. If a consensus.ErrFutureBlock error is returned,
 	// the time difference of the proposal and current time is also returned.
@@ -61,9 +88,19 @@ type Backend interface {
 	//Used to set the blockchain on this
 	SetBlockchain(bc *ethcore.BlockChain)
 
+	// SetTxPool sets the pool the blocks of the compact proposals are rebuilt from.
+	SetTxPool(pool *ethcore.TxPool)
+
 	// RemoveMessageFromLocalCache removes a local message from the known messages cache.
 	// It is called by core when some unprocessed messages are removed from the untrusted backlog buffer.
 	RemoveMessageFromLocalCache(payload []byte)
+
+	// ReportInvalidMessage reports a message received which failed the verification,
+	// the peer which sent it is penalised.
+	ReportInvalidMessage(payload []byte, err error)
+
+	// ReportStaleMessage reports a message received for a height already decided.
+	ReportStaleMessage(payload []byte)
 }
 
 type Tendermint interface {
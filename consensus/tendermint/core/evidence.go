@@ -0,0 +1,195 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/common/hexutil"
+	"github.com/clearmatics/autonity/consensus/tendermint/crypto"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/ethdb"
+	"github.com/clearmatics/autonity/log"
+	"github.com/clearmatics/autonity/rlp"
+)
+
+var (
+	// evidencePrefix + evidence hash -> rlp encoded evidence
+	evidencePrefix = []byte("tendermint-evidence-")
+
+	// errEvidenceDifferentSigners is returned when the messages of an evidence are not from the same sender.
+	errEvidenceDifferentSigners = errors.New("evidence messages have different senders")
+	// errEvidenceDifferentViews is returned when the messages of an evidence are not for the same height, round and step.
+	errEvidenceDifferentViews = errors.New("evidence messages are for different views")
+	// errEvidenceWrongStep is returned when the messages of an evidence are neither proposals nor votes.
+	errEvidenceWrongStep = errors.New("evidence messages are neither proposals nor votes")
+	// errEvidenceNotCanonical is returned when the messages of an evidence are not sorted.
+	errEvidenceNotCanonical = errors.New("evidence messages are not in canonical order")
+	// errEvidenceNoConflict is returned when the messages of an evidence hold the same value.
+	errEvidenceNoConflict = errors.New("evidence messages are not conflicting")
+	// errEvidenceWrongParent is returned when the parent header given is not the one of the evidence height.
+	errEvidenceWrongParent = errors.New("evidence is not for the height following the parent header")
+	// errConflictingProposal is returned when a proposer sends a second proposal for the same round.
+	errConflictingProposal = errors.New("conflicting proposal")
+)
+
+// Evidence is the proof that a committee member has signed two conflicting
+// messages of the same step for the same height and round. It holds both
+// signed payloads as they were received so that anyone can verify it.
+type Evidence struct {
+	First  hexutil.Bytes `json:"first"`
+	Second hexutil.Bytes `json:"second"`
+}
+
+// NewEvidence packages two conflicting signed messages into an evidence. The
+// messages are sorted so that a pair has a single encoding and hash, whichever
+// of them has been received first.
+func NewEvidence(first, second *Message) *Evidence {
+	ev := &Evidence{
+		First:  common.CopyBytes(first.Payload()),
+		Second: common.CopyBytes(second.Payload()),
+	}
+	if bytes.Compare(ev.First, ev.Second) > 0 {
+		ev.First, ev.Second = ev.Second, ev.First
+	}
+	return ev
+}
+
+// Hash returns the keccak256 hash of the rlp encoded evidence.
+func (e *Evidence) Hash() common.Hash {
+	return types.RLPHash(e)
+}
+
+// Messages decodes the two signed messages held by the evidence.
+func (e *Evidence) Messages() (*Message, *Message, error) {
+	first, second := new(Message), new(Message)
+	if err := first.FromPayload(e.First); err != nil {
+		return nil, nil, err
+	}
+	if err := second.FromPayload(e.Second); err != nil {
+		return nil, nil, err
+	}
+	return first, second, nil
+}
+
+// Offender returns the sender of the evidence messages and the height at which
+// they were signed. The evidence is not verified.
+func (e *Evidence) Offender() (common.Address, *big.Int, error) {
+	first, _, err := e.Messages()
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+	height, _ := first.Height()
+	return first.Address, height, nil
+}
+
+// Verify checks the evidence against the committee stored in the parent header
+// of the height at which the messages were signed, and returns the committee
+// member who has signed both of them.
+func (e *Evidence) Verify(parent *types.Header) (common.Address, error) {
+	first, second, err := e.Messages()
+	if err != nil {
+		return common.Address{}, err
+	}
+	if bytes.Compare(e.First, e.Second) > 0 {
+		return common.Address{}, errEvidenceNotCanonical
+	}
+	if first.Address != second.Address {
+		return common.Address{}, errEvidenceDifferentSigners
+	}
+	if first.Code != second.Code {
+		return common.Address{}, errEvidenceDifferentViews
+	}
+	// a member signs a single message per height, round and step. The parts of
+	// a proposed block differ from each other, the conflicting proposals are the
+	// evidence.
+	switch first.Code {
+	case msgProposal, msgPrevote, msgPrecommit:
+	default:
+		return common.Address{}, errEvidenceWrongStep
+	}
+	// decoding was successful, the height and round are known
+	firstHeight, _ := first.Height()
+	secondHeight, _ := second.Height()
+	firstRound, _ := first.Round()
+	secondRound, _ := second.Round()
+	if firstHeight.Cmp(secondHeight) != 0 || firstRound != secondRound {
+		return common.Address{}, errEvidenceDifferentViews
+	}
+	if parent.Number.Uint64()+1 != firstHeight.Uint64() {
+		return common.Address{}, errEvidenceWrongParent
+	}
+	if bytes.Equal(first.Msg, second.Msg) {
+		return common.Address{}, errEvidenceNoConflict
+	}
+	if _, err := first.Validate(crypto.CheckValidatorSignature, parent); err != nil {
+		return common.Address{}, err
+	}
+	if _, err := second.Validate(crypto.CheckValidatorSignature, parent); err != nil {
+		return common.Address{}, err
+	}
+	return first.Address, nil
+}
+
+func evidenceKey(hash common.Hash) []byte {
+	return append(append([]byte{}, evidencePrefix...), hash.Bytes()...)
+}
+
+// WriteEvidence stores an evidence in the database.
+func WriteEvidence(db ethdb.KeyValueWriter, ev *Evidence) error {
+	enc, err := rlp.EncodeToBytes(ev)
+	if err != nil {
+		return err
+	}
+	return db.Put(evidenceKey(ev.Hash()), enc)
+}
+
+// ReadEvidence retrieves the evidence corresponding to the hash.
+func ReadEvidence(db ethdb.KeyValueReader, hash common.Hash) *Evidence {
+	enc, err := db.Get(evidenceKey(hash))
+	if err != nil || len(enc) == 0 {
+		return nil
+	}
+	ev := new(Evidence)
+	if err := rlp.DecodeBytes(enc, ev); err != nil {
+		log.Error("Invalid evidence RLP", "hash", hash, "err", err)
+		return nil
+	}
+	return ev
+}
+
+// ReadAllEvidence retrieves every evidence stored in the database.
+func ReadAllEvidence(db ethdb.Iteratee) []*Evidence {
+	it := db.NewIterator(evidencePrefix, nil)
+	defer it.Release()
+
+	var evidence []*Evidence
+	for it.Next() {
+		ev := new(Evidence)
+		if err := rlp.DecodeBytes(it.Value(), ev); err != nil {
+			log.Error("Invalid evidence RLP", "key", it.Key(), "err", err)
+			continue
+		}
+		evidence = append(evidence, ev)
+	}
+	return evidence
+}
+
+// DeleteEvidence removes the evidence corresponding to the hash.
+func DeleteEvidence(db ethdb.KeyValueWriter, hash common.Hash) error {
+	return db.Delete(evidenceKey(hash))
+}
+
+// recordEvidence is called when a committee member has sent two conflicting
+// messages, the evidence is persisted so that it can be acted upon later.
+func (c *core) recordEvidence(first, second *Message) {
+	tendermintEquivocationMeter.Mark(1)
+	c.logger.Warn("Conflicting messages received", "offender", second.Address, "first", first, "second", second)
+	if c.db == nil {
+		return
+	}
+	if err := WriteEvidence(c.db, NewEvidence(first, second)); err != nil {
+		c.logger.Error("Failed to store evidence", "err", err)
+	}
+}
@@ -0,0 +1,184 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/ethdb/memorydb"
+	"github.com/clearmatics/autonity/log"
+)
+
+func TestEvidenceVerify(t *testing.T) {
+	committeeSet, keys := newTestCommitteeSetWithKeys(4)
+	members := committeeSet.Committee()
+	offender := members[0]
+	height := big.NewInt(5)
+	parent := &types.Header{Number: big.NewInt(4), Committee: members}
+
+	first, _, _ := prepareVote(t, msgPrevote, 1, height, common.HexToHash("0x1"), offender.Address, keys[offender.Address])
+	second, _, _ := prepareVote(t, msgPrevote, 1, height, common.HexToHash("0x2"), offender.Address, keys[offender.Address])
+
+	t.Run("conflicting prevotes", func(t *testing.T) {
+		addr, err := NewEvidence(first, second).Verify(parent)
+		if err != nil {
+			t.Fatalf("Expected nil, got %v", err)
+		}
+		if addr != offender.Address {
+			t.Fatalf("Expected %v, got %v", offender.Address, addr)
+		}
+	})
+
+	t.Run("conflicting precommits", func(t *testing.T) {
+		first, _, _ := prepareVote(t, msgPrecommit, 1, height, common.HexToHash("0x1"), offender.Address, keys[offender.Address])
+		second, _, _ := prepareVote(t, msgPrecommit, 1, height, common.Hash{}, offender.Address, keys[offender.Address])
+		if _, err := NewEvidence(first, second).Verify(parent); err != nil {
+			t.Fatalf("Expected nil, got %v", err)
+		}
+	})
+
+	t.Run("messages in any order", func(t *testing.T) {
+		ev := NewEvidence(second, first)
+		if ev.Hash() != NewEvidence(first, second).Hash() {
+			t.Fatalf("Expected %v, got %v", NewEvidence(first, second).Hash(), ev.Hash())
+		}
+		reversed := &Evidence{First: ev.Second, Second: ev.First}
+		if _, err := reversed.Verify(parent); err != errEvidenceNotCanonical {
+			t.Fatalf("Expected %v, got %v", errEvidenceNotCanonical, err)
+		}
+	})
+
+	t.Run("same vote twice", func(t *testing.T) {
+		if _, err := NewEvidence(first, first).Verify(parent); err != errEvidenceNoConflict {
+			t.Fatalf("Expected %v, got %v", errEvidenceNoConflict, err)
+		}
+	})
+
+	t.Run("votes from different members", func(t *testing.T) {
+		other, _, _ := prepareVote(t, msgPrevote, 1, height, common.HexToHash("0x2"), members[1].Address, keys[members[1].Address])
+		if _, err := NewEvidence(first, other).Verify(parent); err != errEvidenceDifferentSigners {
+			t.Fatalf("Expected %v, got %v", errEvidenceDifferentSigners, err)
+		}
+	})
+
+	t.Run("votes for different rounds", func(t *testing.T) {
+		other, _, _ := prepareVote(t, msgPrevote, 2, height, common.HexToHash("0x2"), offender.Address, keys[offender.Address])
+		if _, err := NewEvidence(first, other).Verify(parent); err != errEvidenceDifferentViews {
+			t.Fatalf("Expected %v, got %v", errEvidenceDifferentViews, err)
+		}
+	})
+
+	t.Run("proposal parts", func(t *testing.T) {
+		part := func(data []byte) *Message {
+			enc, err := Encode(&ProposalPart{Round: 1, Height: height, BlockHash: common.HexToHash("0x1"), Total: 2, Bytes: data})
+			if err != nil {
+				t.Fatal(err)
+			}
+			msg := &Message{Code: msgProposalPart, Msg: enc, Address: offender.Address}
+			payload, err := msg.PayloadNoSig()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if msg.Signature, err = sign(payload, keys[offender.Address]); err != nil {
+				t.Fatal(err)
+			}
+			return msg
+		}
+		if _, err := NewEvidence(part([]byte{1}), part([]byte{2})).Verify(parent); err != errEvidenceWrongStep {
+			t.Fatalf("Expected %v, got %v", errEvidenceWrongStep, err)
+		}
+	})
+
+	t.Run("wrong parent header", func(t *testing.T) {
+		wrongParent := &types.Header{Number: big.NewInt(5), Committee: members}
+		if _, err := NewEvidence(first, second).Verify(wrongParent); err != errEvidenceWrongParent {
+			t.Fatalf("Expected %v, got %v", errEvidenceWrongParent, err)
+		}
+	})
+
+	t.Run("forged signature", func(t *testing.T) {
+		forged, _, _ := prepareVote(t, msgPrevote, 1, height, common.HexToHash("0x2"), offender.Address, keys[members[1].Address])
+		if _, err := NewEvidence(first, forged).Verify(parent); err != ErrUnauthorizedAddress {
+			t.Fatalf("Expected %v, got %v", ErrUnauthorizedAddress, err)
+		}
+	})
+
+	t.Run("offender not in the committee", func(t *testing.T) {
+		otherParent := &types.Header{Number: big.NewInt(4), Committee: members[1:]}
+		if _, err := NewEvidence(first, second).Verify(otherParent); err == nil {
+			t.Fatalf("Expected error, got nil")
+		}
+	})
+}
+
+func TestEvidenceStore(t *testing.T) {
+	committeeSet, keys := newTestCommitteeSetWithKeys(4)
+	member := committeeSet.Committee()[0]
+	height := big.NewInt(5)
+
+	first, _, _ := prepareVote(t, msgPrevote, 1, height, common.HexToHash("0x1"), member.Address, keys[member.Address])
+	second, _, _ := prepareVote(t, msgPrevote, 1, height, common.HexToHash("0x2"), member.Address, keys[member.Address])
+	ev := NewEvidence(first, second)
+
+	db := memorydb.New()
+	if err := WriteEvidence(db, ev); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if got := ReadEvidence(db, ev.Hash()); got == nil || got.Hash() != ev.Hash() {
+		t.Fatalf("Expected %v, got %v", ev, got)
+	}
+	if all := ReadAllEvidence(db); len(all) != 1 || all[0].Hash() != ev.Hash() {
+		t.Fatalf("Expected 1 evidence, got %v", all)
+	}
+	if err := DeleteEvidence(db, ev.Hash()); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if got := ReadEvidence(db, ev.Hash()); got != nil {
+		t.Fatalf("Expected nil, got %v", got)
+	}
+}
+
+func TestConflictingVotesRecordEvidence(t *testing.T) {
+	committeeSet, keys := newTestCommitteeSetWithKeys(4)
+	member := committeeSet.Committee()[0]
+	height := big.NewInt(5)
+	parent := &types.Header{Number: big.NewInt(4), Committee: committeeSet.Committee()}
+
+	messages := newMessagesMap()
+	c := &core{
+		logger:           log.New("backend", "test", "id", 0),
+		height:           height,
+		committee:        committeeSet,
+		messages:         messages,
+		curRoundMessages: messages.getOrCreate(1),
+		db:               memorydb.New(),
+	}
+
+	first, _, _ := prepareVote(t, msgPrecommit, 1, height, common.HexToHash("0x1"), member.Address, keys[member.Address])
+	second, _, _ := prepareVote(t, msgPrecommit, 1, height, common.HexToHash("0x2"), member.Address, keys[member.Address])
+
+	c.acceptVote(c.curRoundMessages, precommit, common.HexToHash("0x1"), *first)
+	// receiving the same vote twice is not a fault
+	c.acceptVote(c.curRoundMessages, precommit, common.HexToHash("0x1"), *first)
+	if all := ReadAllEvidence(c.db); len(all) != 0 {
+		t.Fatalf("Expected no evidence, got %v", all)
+	}
+
+	c.acceptVote(c.curRoundMessages, precommit, common.HexToHash("0x2"), *second)
+	if power := c.curRoundMessages.PrecommitsPower(common.HexToHash("0x2")); power != 0 {
+		t.Fatalf("conflicting vote should not be counted, got power %v", power)
+	}
+
+	all := ReadAllEvidence(c.db)
+	if len(all) != 1 {
+		t.Fatalf("Expected 1 evidence, got %v", all)
+	}
+	addr, err := all[0].Verify(parent)
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if addr != member.Address {
+		t.Fatalf("Expected %v, got %v", member.Address, addr)
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/clearmatics/autonity/common"
 	"github.com/clearmatics/autonity/consensus/tendermint/crypto"
 	"github.com/clearmatics/autonity/consensus/tendermint/events"
+	"github.com/clearmatics/autonity/rlp"
 )
 
 // Start implements core.Tendermint.Start
@@ -34,6 +35,7 @@ func (c *core) Start(ctx context.Context, contract *autonity.Contract) {
 	ctx, c.cancel = context.WithCancel(ctx)
 
 	c.subscribeEvents()
+	c.verifier.start(ctx)
 
 	// core.height needs to be set beforehand for unmined block's logic.
 	lastBlockMined, _ := c.backend.LastCommittedProposal()
@@ -64,10 +66,11 @@ func (c *core) Stop() {
 	<-c.stopped
 	<-c.stopped
 	<-c.stopped
+	c.verifier.wait()
 }
 
 func (c *core) subscribeEvents() {
-	s := c.backend.Subscribe(events.MessageEvent{}, backlogEvent{}, backlogUncheckedEvent{}, coreStateRequestEvent{})
+	s := c.backend.Subscribe(events.MessageEvent{}, backlogEvent{}, backlogUncheckedEvent{}, coreStateRequestEvent{}, events.ProposalTransactionsEvent{})
 	c.messageEventSub = s
 
 	s1 := c.backend.Subscribe(events.NewUnminedBlockEvent{})
@@ -115,8 +118,8 @@ eventLoop:
 }
 
 func (c *core) mainEventLoop(ctx context.Context) {
-	// Start a new round from last height + 1
-	c.startRound(ctx, 0)
+	// Start a new round from last height + 1, or from where we stopped if the WAL holds its state
+	c.resume(ctx)
 
 	go c.syncLoop(ctx)
 
@@ -130,36 +133,28 @@ eventLoop:
 			// A real ev arrived, process interesting content
 			switch e := ev.Data.(type) {
 			case events.MessageEvent:
-				msg := new(Message)
-				if err := msg.FromPayload(e.Payload); err != nil {
-					c.logger.Error("consensus message invalid payload", "err", err)
-					continue
-				}
-				if err := c.handleMsg(ctx, msg); err != nil {
-					c.logger.Debug("MessageEvent payload failed", "err", err)
-					continue
-				}
-				c.backend.Gossip(ctx, c.committeeSet().Committee(), e.Payload)
+				c.verifyMsg(ctx, e.Payload)
 			case backlogEvent:
 				// No need to check signature for internal messages
 				c.logger.Debug("started handling backlogEvent")
-				if err := c.handleCheckedMsg(ctx, e.msg); err != nil {
+				if err := c.handleCheckedMsg(ctx, e.msg); err != nil && err != errIncompleteProposal {
 					c.logger.Debug("backlogEvent message handling failed", "err", err)
 					continue
 				}
 				c.backend.Gossip(ctx, c.committeeSet().Committee(), e.msg.Payload())
 
 			case backlogUncheckedEvent:
+				// The committee of the message height is known by now.
 				c.logger.Debug("started handling backlogUncheckedEvent")
-				if err := c.handleMsg(ctx, e.msg); err != nil {
-					c.logger.Debug("backlogUncheckedEvent message failed", "err", err)
-					continue
-				}
-				c.backend.Gossip(ctx, c.committeeSet().Committee(), e.msg.Payload())
+				c.verifyMsg(ctx, e.msg.Payload())
 			case coreStateRequestEvent:
 				// Process Tendermint state dump request.
 				c.handleStateDump(e)
+			case events.ProposalTransactionsEvent:
+				c.handleProposalTransactions(ctx, e.BlockHash)
 			}
+		case res := <-c.verifier.results:
+			c.handleVerifiedMsg(ctx, res)
 		case ev, ok := <-c.timeoutEventSub.Chan():
 			if !ok {
 				break eventLoop
@@ -196,36 +191,35 @@ func (c *core) syncLoop(ctx context.Context) {
 		this method is responsible for asking the network to send us the current consensus state
 		and to process sync queries events.
 	*/
-	timer := time.NewTimer(10 * time.Second)
-
-	round := c.Round()
-	height := c.Height()
-
-	// Ask for sync when the engine starts
+	// Ask for sync when the engine starts and periodically after that, the
+	// requests advertise the messages we hold so that only the missing ones
+	// are sent back.
 	c.backend.AskSync(c.lastHeader)
+	ticker := time.NewTicker(syncPeriod)
+	defer ticker.Stop()
 
 eventLoop:
 	for {
 		select {
-		case <-timer.C:
-			currentRound := c.Round()
-			currentHeight := c.Height()
-
-			// we only ask for sync if the current view stayed the same for the past 10 seconds
-			if currentHeight.Cmp(height) == 0 && currentRound == round {
-				c.backend.AskSync(c.lastHeader)
-			}
-			round = currentRound
-			height = currentHeight
-			timer = time.NewTimer(10 * time.Second)
+		case <-ticker.C:
+			c.backend.AskSync(c.lastHeader)
 
 		case ev, ok := <-c.syncEventSub.Chan():
 			if !ok {
 				break eventLoop
 			}
 			event := ev.Data.(events.SyncEvent)
-			c.logger.Info("Processing sync message", "from", event.Addr)
-			c.backend.SyncPeer(event.Addr)
+			// legacy sync requests have no payload, all the messages are sent
+			var request *SyncRequest
+			if len(event.Payload) > 0 {
+				request = new(SyncRequest)
+				if err := rlp.DecodeBytes(event.Payload, request); err != nil {
+					c.logger.Debug("Invalid sync request", "from", event.Addr, "err", err)
+					continue
+				}
+			}
+			c.logger.Debug("Processing sync message", "from", event.Addr)
+			c.backend.SyncPeer(event.Addr, request)
 		case <-ctx.Done():
 			c.logger.Info("syncLoop is stopped", "event", ctx.Err())
 			break eventLoop
@@ -264,6 +258,59 @@ func (c *core) handleMsg(ctx context.Context, msg *Message) error {
 	return c.handleCheckedMsg(ctx, msg)
 }
 
+// verifyMsg hands the payload to the verifier, it is verified by the main event
+// loop if the verifier is saturated.
+func (c *core) verifyMsg(ctx context.Context, payload []byte) {
+	if !c.verifier.submit(payload, c.lastHeader) {
+		c.handleVerifiedMsg(ctx, c.verifier.verify(payload, c.lastHeader))
+	}
+}
+
+// handleVerifiedMsg handles a message once it has been through the verifier.
+// The height may have changed in the meantime: the messages of an old height
+// are dropped and the messages which were ahead of the committee known at the
+// time are either verified again or stored in the unchecked backlog.
+func (c *core) handleVerifiedMsg(ctx context.Context, res verifiedMsg) {
+	if res.msg == nil {
+		c.logger.Error("consensus message invalid payload", "err", res.err)
+		c.backend.ReportInvalidMessage(res.payload, res.err)
+		return
+	}
+	msgHeight, err := res.msg.Height()
+	if err != nil {
+		c.logger.Error("consensus message invalid payload", "err", err)
+		c.backend.ReportInvalidMessage(res.payload, err)
+		return
+	}
+	switch msgHeight.Cmp(c.Height()) {
+	case -1:
+		// the message is only stale if it was when received
+		if res.err == errOldHeightMessage {
+			c.backend.ReportStaleMessage(res.payload)
+		}
+		return
+	case 1:
+		// Future height message. Skip processing and put it in the untrusted backlog buffer.
+		c.storeUncheckedBacklog(res.msg)
+		return
+	}
+	if res.err == errFutureHeightMessage {
+		c.verifyMsg(ctx, res.msg.Payload())
+		return
+	}
+	if res.err != nil {
+		c.logger.Error("Failed to validate message", "err", res.err)
+		c.backend.ReportInvalidMessage(res.payload, res.err)
+		return
+	}
+	// the proposals are gossiped while the parts of their block are received
+	if err := c.handleCheckedMsg(ctx, res.msg); err != nil && err != errIncompleteProposal {
+		c.logger.Debug("MessageEvent payload failed", "err", err)
+		return
+	}
+	c.backend.Gossip(ctx, c.committeeSet().Committee(), res.msg.Payload())
+}
+
 func (c *core) handleFutureRoundMsg(ctx context.Context, msg *Message, sender common.Address) {
 	// Decoding functions can't fail here
 	msgRound, err := msg.Round()
@@ -319,6 +366,9 @@ func (c *core) handleCheckedMsg(ctx context.Context, msg *Message) error {
 	case msgPrecommit:
 		logger.Debug("tendermint.MessageEvent: PRECOMMIT")
 		return testBacklog(c.handlePrecommit(ctx, msg))
+	case msgProposalPart:
+		logger.Debug("tendermint.MessageEvent: PROPOSAL PART")
+		return testBacklog(c.handleProposalPart(ctx, msg))
 	default:
 		logger.Error("Invalid message", "msg", msg)
 	}
@@ -32,8 +32,13 @@ const (
 	msgProposal uint64 = iota
 	msgPrevote
 	msgPrecommit
+	msgProposalPart
 )
 
+// MsgPrevote is the code of the prevote messages, for the engines sending
+// their own votes in tests.
+const MsgPrevote = msgPrevote
+
 var (
 	errMsgPayloadNotDecoded = errors.New("message not decoded")
 	ErrUnauthorizedAddress  = errors.New("unauthorized address")
@@ -104,6 +109,9 @@ func (m *Message) FromPayload(b []byte) error {
 	case msgPrevote, msgPrecommit:
 		var vote Vote
 		return m.Decode(&vote)
+	case msgProposalPart:
+		var part ProposalPart
+		return m.Decode(&part)
 	default:
 		return errMsgPayloadNotDecoded
 	}
@@ -222,6 +230,15 @@ func (m *Message) String() string {
 		}
 		msg = vote.String()
 	}
+
+	if m.Code == msgProposalPart {
+		var part ProposalPart
+		err := m.Decode(&part)
+		if err != nil {
+			return ""
+		}
+		msg = part.String()
+	}
 	return fmt.Sprintf("{sender: %v, power: %v, msgCode: %v, msg: %v}", m.Address.String(), m.power, m.Code, msg)
 }
 
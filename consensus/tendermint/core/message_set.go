@@ -17,8 +17,10 @@
 package core
 
 import (
-	"github.com/clearmatics/autonity/common"
+	"bytes"
 	"sync"
+
+	"github.com/clearmatics/autonity/common"
 )
 
 func newMessageSet() messageSet {
@@ -37,14 +39,19 @@ type messageSet struct {
 	messagesMu *sync.RWMutex
 }
 
-func (ms *messageSet) AddVote(blockHash common.Hash, msg Message) {
+// AddVote stores the vote of a committee member. If a different vote from the
+// same member is already stored it is returned and msg is dropped.
+func (ms *messageSet) AddVote(blockHash common.Hash, msg Message) *Message {
 	ms.messagesMu.Lock()
 	defer ms.messagesMu.Unlock()
 
 	// Check first if we already received a message from this pal.
-	if _, ok := ms.messages[msg.Address]; ok {
-		// TODO : double signing fault ! Accountability
-		return
+	if prev, ok := ms.messages[msg.Address]; ok {
+		if !bytes.Equal(prev.Msg, msg.Msg) {
+			// double signing fault
+			return prev
+		}
+		return nil
 	}
 
 	var addressesMap map[common.Address]Message
@@ -56,6 +63,7 @@ func (ms *messageSet) AddVote(blockHash common.Hash, msg Message) {
 	addressesMap = ms.votes[blockHash]
 	addressesMap[msg.Address] = msg
 	ms.messages[msg.Address] = &msg
+	return nil
 }
 
 func (ms *messageSet) GetMessages() []*Message {
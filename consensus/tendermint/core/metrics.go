@@ -26,4 +26,5 @@ var (
 	tendermintProposeTimer      = metrics.NewRegisteredTimer("tendermint/timer/propose", nil)
 	tendermintPrevoteTimer      = metrics.NewRegisteredTimer("tendermint/timer/prevote", nil)
 	tendermintPrecommitTimer    = metrics.NewRegisteredTimer("tendermint/timer/precommit", nil)
+	tendermintEquivocationMeter = metrics.NewRegisteredMeter("tendermint/equivocation", nil)
 )
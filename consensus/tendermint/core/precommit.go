@@ -22,12 +22,19 @@ import (
 	"math/big"
 
 	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/consensus/tendermint/crypto"
 	"github.com/clearmatics/autonity/core/types"
 )
 
 func (c *core) sendPrecommit(ctx context.Context, isNil bool) {
 	logger := c.logger.New("step", c.step)
 
+	// checked before signing the committed seal which also commits us to a value
+	if c.resendSignedMessage(ctx, msgPrecommit) {
+		c.sentPrecommit = true
+		return
+	}
+
 	var precommit = Vote{
 		Round:  c.Round(),
 		Height: c.Height(),
@@ -60,7 +67,7 @@ func (c *core) sendPrecommit(ctx context.Context, isNil bool) {
 
 	// Create committed seal
 	seal := PrepareCommittedSeal(precommit.ProposedBlockHash, c.Round(), c.Height())
-	msg.CommittedSeal, err = c.backend.Sign(seal)
+	msg.CommittedSeal, err = c.backend.SignCommittedSeal(seal, c.sealsAggregated())
 	if err != nil {
 		c.logger.Error("core.sendPrecommit error while signing committed seal", "err", err)
 	}
@@ -137,6 +144,18 @@ func (c *core) handlePrecommit(ctx context.Context, msg *Message) error {
 func (c *core) verifyCommittedSeal(addressMsg common.Address, committedSealMsg []byte, proposedBlockHash common.Hash, round int64, height *big.Int) error {
 	committedSeal := PrepareCommittedSeal(proposedBlockHash, round, height)
 
+	if c.sealsAggregated() {
+		_, member, err := c.committeeSet().GetByAddress(addressMsg)
+		if err != nil {
+			return err
+		}
+		if err := crypto.VerifySeal(member.ConsensusKey, committedSeal, committedSealMsg); err != nil {
+			c.logger.Error("verify precommit bls seal error", "from", addressMsg.String(), "err", err)
+			return errInvalidSenderOfCommittedSeal
+		}
+		return nil
+	}
+
 	sealerAddress, err := types.GetSignatureAddress(committedSeal, committedSealMsg)
 	if err != nil {
 		c.logger.Error("Failed to get signer address", "err", err)
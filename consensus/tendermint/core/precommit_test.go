@@ -13,6 +13,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/clearmatics/autonity/common"
+	tendermintCrypto "github.com/clearmatics/autonity/consensus/tendermint/crypto"
 	"github.com/clearmatics/autonity/core/types"
 	"github.com/clearmatics/autonity/log"
 	"github.com/golang/mock/gomock"
@@ -82,7 +83,7 @@ func TestSendPrecommit(t *testing.T) {
 		}
 
 		backendMock := NewMockBackend(ctrl)
-		backendMock.EXPECT().Sign(gomock.Any()).Return([]byte{0x1}, nil)
+		backendMock.EXPECT().SignCommittedSeal(gomock.Any(), false).Return([]byte{0x1}, nil)
 		backendMock.EXPECT().Sign(gomock.Eq(payloadNoSig)).Return([]byte{0x1}, nil)
 
 		payload := expectedMsg.Payload()
@@ -146,7 +147,7 @@ func TestSendPrecommit(t *testing.T) {
 		}
 
 		backendMock := NewMockBackend(ctrl)
-		backendMock.EXPECT().Sign(gomock.Any()).Return([]byte{0x1}, errors.New("seal sign error"))
+		backendMock.EXPECT().SignCommittedSeal(gomock.Any(), false).Return([]byte{0x1}, errors.New("seal sign error"))
 		backendMock.EXPECT().Sign(payloadNoSig).Return([]byte{0x1}, nil)
 
 		payload := expectedMsg.Payload()
@@ -486,6 +487,7 @@ func TestHandleCommit(t *testing.T) {
 
 	backendMock := NewMockBackend(ctrl)
 	backendMock.EXPECT().LastCommittedProposal().MinTimes(1).Return(block, addr)
+	backendMock.EXPECT().Address().Return(addr)
 
 	c := &core{
 		address:          addr,
@@ -539,3 +541,86 @@ func preparePrecommitMsg(proposalHash common.Hash, round int64, height int64, ke
 	}
 	return expectedMsg, err
 }
+
+func TestAggregatedCommittedSeals(t *testing.T) {
+	members, keys := generateCommittee(4)
+	blsKeys := make(map[common.Address]*tendermintCrypto.BLSKey)
+	for i := range members {
+		key, err := tendermintCrypto.DeriveBLSKey(keys[members[i].Address])
+		if err != nil {
+			t.Fatal(err)
+		}
+		members[i].ConsensusKey = key.ConsensusKey()
+		blsKeys[members[i].Address] = key
+	}
+	committeeSet, _ := newRoundRobinSet(members, members[0].Address)
+	height := big.NewInt(3)
+	hash := common.HexToHash("0x1")
+	seal := PrepareCommittedSeal(hash, 1, height)
+
+	c := &core{
+		logger:    log.New("backend", "test", "id", 0),
+		committee: committeeSet,
+		height:    height,
+	}
+	if !c.sealsAggregated() {
+		t.Fatalf("Expected aggregated seals")
+	}
+
+	sender := members[1].Address
+	if err := c.verifyCommittedSeal(sender, blsKeys[sender].Sign(seal), hash, 1, height); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+
+	t.Run("seal of another member", func(t *testing.T) {
+		err := c.verifyCommittedSeal(sender, blsKeys[members[2].Address].Sign(seal), hash, 1, height)
+		if err != errInvalidSenderOfCommittedSeal {
+			t.Fatalf("Expected %v, got %v", errInvalidSenderOfCommittedSeal, err)
+		}
+	})
+
+	t.Run("secp256k1 seal", func(t *testing.T) {
+		ecdsaSeal, err := crypto.Sign(crypto.Keccak256(seal), keys[sender])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := c.verifyCommittedSeal(sender, ecdsaSeal, hash, 1, height); err != errInvalidSenderOfCommittedSeal {
+			t.Fatalf("Expected %v, got %v", errInvalidSenderOfCommittedSeal, err)
+		}
+	})
+
+	t.Run("commit aggregates the seals", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		block := types.NewBlockWithHeader(&types.Header{Number: height})
+		messages := newMessagesMap()
+		roundMessages := messages.getOrCreate(1)
+		roundMessages.SetProposal(&Proposal{Round: 1, Height: height, ProposalBlock: block}, nil, true)
+		blockSeal := PrepareCommittedSeal(block.Hash(), 1, height)
+		for _, member := range members[:3] {
+			roundMessages.AddPrecommit(block.Hash(), Message{
+				Code:          msgPrecommit,
+				Address:       member.Address,
+				CommittedSeal: blsKeys[member.Address].Sign(blockSeal),
+				power:         1,
+			})
+		}
+
+		backendMock := NewMockBackend(ctrl)
+		backendMock.EXPECT().CommitAggregated(block, int64(1), gomock.Any()).DoAndReturn(
+			func(_ *types.Block, _ int64, aggregated types.AggregatedSeal) error {
+				signers, err := tendermintCrypto.VerifyAggregatedSeal(members, blockSeal, aggregated)
+				if err != nil {
+					t.Fatalf("Expected nil, got %v", err)
+				}
+				if len(signers) != 3 {
+					t.Fatalf("Expected 3 signers, got %v", signers)
+				}
+				return nil
+			})
+		c.backend = backendMock
+		c.messages = messages
+		c.commit(1, roundMessages)
+	})
+}
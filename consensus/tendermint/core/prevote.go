@@ -25,6 +25,11 @@ import (
 func (c *core) sendPrevote(ctx context.Context, isNil bool) {
 	logger := c.logger.New("step", c.step)
 
+	if c.resendSignedMessage(ctx, msgPrevote) {
+		c.sentPrevote = true
+		return
+	}
+
 	var prevote = Vote{
 		Round:  c.Round(),
 		Height: c.Height(),
@@ -120,6 +125,9 @@ func (c *core) handlePrevote(ctx context.Context, msg *Message) error {
 			c.validValue = c.curRoundMessages.Proposal().ProposalBlock
 			c.validRound = c.Round()
 			c.setValidRoundAndValue = true
+			if err := c.persistState(); err != nil {
+				c.logger.Error("Failed to write valid value to WAL", "err", err)
+			}
 			// Line 44 in Algorithm 1 of The latest gossip on BFT consensus
 		} else if c.step == prevote && c.curRoundMessages.PrevotesPower(common.Hash{}) >= c.committeeSet().Quorum() {
 			if err := c.prevoteTimeout.stopTimer(); err != nil {
@@ -192,6 +192,7 @@ func TestHandlePrevote(t *testing.T) {
 		expectedMsg := createPrevote(t, curRoundMessage.GetProposalHash(), 2, big.NewInt(3), member)
 		backendMock := NewMockBackend(ctrl)
 		backendMock.EXPECT().Sign(gomock.Any()).Return([]byte{0x1}, nil).AnyTimes()
+		backendMock.EXPECT().SignCommittedSeal(gomock.Any(), false).Return([]byte{0x1}, nil).AnyTimes()
 
 		var precommit = Vote{
 			Round:             2,
@@ -255,6 +256,7 @@ func TestHandlePrevote(t *testing.T) {
 		expectedMsg := createPrevote(t, common.Hash{}, 2, big.NewInt(3), member)
 		backendMock := NewMockBackend(ctrl)
 		backendMock.EXPECT().Sign(gomock.Any()).Return([]byte{0x1}, nil).AnyTimes()
+		backendMock.EXPECT().SignCommittedSeal(gomock.Any(), false).Return([]byte{0x1}, nil).AnyTimes()
 
 		var precommit = Vote{
 			Round:             2,
@@ -343,7 +345,7 @@ func TestHandlePrevote(t *testing.T) {
 		backendMock := NewMockBackend(ctrl)
 		backendMock.EXPECT().Address().AnyTimes().Return(addr)
 
-		c := New(backendMock, config.DefaultConfig())
+		c := New(backendMock, config.DefaultConfig(), nil)
 		c.curRoundMessages = curRoundMessages
 		c.height = big.NewInt(2)
 		c.round = 1
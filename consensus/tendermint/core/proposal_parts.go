@@ -0,0 +1,166 @@
+package core
+
+import (
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/crypto"
+	"github.com/clearmatics/autonity/rlp"
+)
+
+const (
+	// proposalPartSize is the size of the parts the rlp encoded block of a
+	// proposal is split into, the last part being shorter.
+	proposalPartSize = 64 * 1024
+	// maxProposalParts bounds the size of a proposed block to 16MB, above
+	// the size of the messages the peers accept.
+	maxProposalParts = 256
+	// maxProposalPartProof is the depth of a merkle tree of maxProposalParts
+	// leaves, it bounds the proof of a part.
+	maxProposalPartProof = 8
+)
+
+type blockPart struct {
+	bytes []byte
+	proof []common.Hash
+}
+
+// splitBlock splits the rlp encoded block, or its compact encoding, into parts
+// of proposalPartSize and returns them along with the header of the part set.
+func splitBlock(block *types.Block, compact bool) (PartSetHeader, []blockPart) {
+	var (
+		enc []byte
+		err error
+	)
+	if compact {
+		enc, err = rlp.EncodeToBytes(newCompactBlock(block))
+	} else {
+		enc, err = rlp.EncodeToBytes(block)
+	}
+	if err != nil {
+		// the blocks we propose can always be encoded.
+		panic("could not encode block: " + err.Error())
+	}
+	parts := make([]blockPart, 0, (len(enc)+proposalPartSize-1)/proposalPartSize)
+	leaves := make([]common.Hash, 0, cap(parts))
+	for start := 0; start < len(enc); start += proposalPartSize {
+		end := start + proposalPartSize
+		if end > len(enc) {
+			end = len(enc)
+		}
+		parts = append(parts, blockPart{bytes: enc[start:end]})
+		leaves = append(leaves, leafHash(enc[start:end]))
+	}
+	root, proofs := merkleTree(leaves)
+	for i := range parts {
+		parts[i].proof = proofs[i]
+	}
+	return PartSetHeader{Total: uint64(len(parts)), Root: root, Compact: compact}, parts
+}
+
+// belongsTo reports whether the part is one of the parts of the proposal.
+func (p *ProposalPart) belongsTo(proposal *Proposal) bool {
+	if p.BlockHash != proposal.BlockHash || p.Total != proposal.PartSet.Total {
+		return false
+	}
+	root, ok := proofRoot(p.Index, p.Total, leafHash(p.Bytes), p.Proof)
+	return ok && root == proposal.PartSet.Root
+}
+
+// assembleParts concatenates the parts of the proposal, which must belong to
+// it. It returns errIncompleteProposal if any part is missing.
+func assembleParts(proposal *Proposal, parts map[uint64]*ProposalPart) ([]byte, error) {
+	if uint64(len(parts)) < proposal.PartSet.Total {
+		return nil, errIncompleteProposal
+	}
+	var enc []byte
+	for i := uint64(0); i < proposal.PartSet.Total; i++ {
+		part, ok := parts[i]
+		if !ok {
+			return nil, errIncompleteProposal
+		}
+		enc = append(enc, part.Bytes...)
+	}
+	return enc, nil
+}
+
+// assembleBlock reassembles the block of the proposal from its parts, see
+// assembleParts.
+func assembleBlock(proposal *Proposal, parts map[uint64]*ProposalPart) (*types.Block, error) {
+	enc, err := assembleParts(proposal, parts)
+	if err != nil {
+		return nil, err
+	}
+	block := new(types.Block)
+	if err := rlp.DecodeBytes(enc, block); err != nil {
+		return nil, errInvalidProposalParts
+	}
+	if block.Hash() != proposal.BlockHash {
+		return nil, errInvalidProposalParts
+	}
+	return block, nil
+}
+
+// The parts are the leaves of a binary merkle tree, the left subtree of a node
+// of n leaves holds the largest power of 2 less than n leaves. The leaves and
+// the inner nodes are hashed with different prefixes so that one can't be
+// taken for the other.
+
+func leafHash(data []byte) common.Hash {
+	return crypto.Keccak256Hash([]byte{0}, data)
+}
+
+func innerHash(left, right common.Hash) common.Hash {
+	return crypto.Keccak256Hash([]byte{1}, left.Bytes(), right.Bytes())
+}
+
+// splitPoint returns the largest power of 2 less than n, n > 1.
+func splitPoint(n uint64) uint64 {
+	k := uint64(1)
+	for k<<1 < n {
+		k <<= 1
+	}
+	return k
+}
+
+// merkleTree returns the root of the tree of the leaves and the proof of each
+// leaf, the hashes of the siblings of its path from the leaf up to the root.
+func merkleTree(leaves []common.Hash) (common.Hash, [][]common.Hash) {
+	switch len(leaves) {
+	case 0:
+		return common.Hash{}, nil
+	case 1:
+		return leaves[0], [][]common.Hash{nil}
+	}
+	k := splitPoint(uint64(len(leaves)))
+	left, leftProofs := merkleTree(leaves[:k])
+	right, rightProofs := merkleTree(leaves[k:])
+	for i := range leftProofs {
+		leftProofs[i] = append(leftProofs[i], right)
+	}
+	for i := range rightProofs {
+		rightProofs[i] = append(rightProofs[i], left)
+	}
+	return innerHash(left, right), append(leftProofs, rightProofs...)
+}
+
+// proofRoot returns the root of the tree of total leaves given the leaf at
+// index and its proof, false if the proof doesn't match the shape of the tree.
+func proofRoot(index, total uint64, leaf common.Hash, proof []common.Hash) (common.Hash, bool) {
+	if index >= total {
+		return common.Hash{}, false
+	}
+	if total == 1 {
+		return leaf, len(proof) == 0
+	}
+	if len(proof) == 0 {
+		return common.Hash{}, false
+	}
+	sibling, proof := proof[len(proof)-1], proof[:len(proof)-1]
+	k := splitPoint(total)
+	if index < k {
+		left, ok := proofRoot(index, k, leaf, proof)
+		return innerHash(left, sibling), ok
+	}
+	right, ok := proofRoot(index-k, total-k, leaf, proof)
+	return innerHash(sibling, right), ok
+}
@@ -0,0 +1,252 @@
+package core
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/crypto"
+	"github.com/clearmatics/autonity/log"
+	"github.com/golang/mock/gomock"
+)
+
+func TestMerkleTree(t *testing.T) {
+	for n := 1; n <= 17; n++ {
+		leaves := make([]common.Hash, n)
+		for i := range leaves {
+			leaves[i] = crypto.Keccak256Hash([]byte{byte(i)})
+		}
+		root, proofs := merkleTree(leaves)
+		for i, leaf := range leaves {
+			if r, ok := proofRoot(uint64(i), uint64(n), leaf, proofs[i]); !ok || r != root {
+				t.Fatalf("Expected leaf %d of %d to be proven", i, n)
+			}
+			if r, ok := proofRoot(uint64(i), uint64(n), crypto.Keccak256Hash(leaf.Bytes()), proofs[i]); ok && r == root {
+				t.Fatalf("Expected another leaf %d of %d not to be proven", i, n)
+			}
+			if n == 1 {
+				continue
+			}
+			if r, ok := proofRoot(uint64((i+1)%n), uint64(n), leaf, proofs[i]); ok && r == root {
+				t.Fatalf("Expected leaf %d of %d not to be proven at another index", i, n)
+			}
+			if _, ok := proofRoot(uint64(i), uint64(n), leaf, proofs[i][1:]); ok {
+				t.Fatalf("Expected a truncated proof of leaf %d of %d to be refused", i, n)
+			}
+		}
+	}
+}
+
+func TestSplitBlock(t *testing.T) {
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1), Extra: make([]byte, 3*proposalPartSize)})
+	proposal := NewProposal(0, big.NewInt(1), -1, block)
+	other := NewProposal(0, big.NewInt(1), -1, types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1), Extra: make([]byte, 3*proposalPartSize+1)}))
+
+	if proposal.PartSet.Total != 4 || len(proposal.Parts()) != 4 {
+		t.Fatalf("Expected 4 parts, got %d", len(proposal.Parts()))
+	}
+	parts := make(map[uint64]*ProposalPart)
+	for _, part := range proposal.Parts() {
+		if !part.belongsTo(proposal) {
+			t.Fatalf("Expected part %d to belong to the proposal", part.Index)
+		}
+		if part.belongsTo(other) {
+			t.Fatalf("Expected part %d not to belong to another proposal", part.Index)
+		}
+		parts[part.Index] = part
+	}
+
+	t.Run("block is reassembled", func(t *testing.T) {
+		assembled, err := assembleBlock(proposal, parts)
+		if err != nil {
+			t.Fatalf("Expected <nil>, got %v", err)
+		}
+		if assembled.Hash() != block.Hash() {
+			t.Fatalf("Expected %v, got %v", block.Hash(), assembled.Hash())
+		}
+	})
+
+	t.Run("missing part", func(t *testing.T) {
+		incomplete := map[uint64]*ProposalPart{0: parts[0], 1: parts[1], 3: parts[3]}
+		if _, err := assembleBlock(proposal, incomplete); err != errIncompleteProposal {
+			t.Fatalf("Expected %v, got %v", errIncompleteProposal, err)
+		}
+	})
+
+	t.Run("modified part", func(t *testing.T) {
+		modified := *parts[2]
+		modified.Bytes = append([]byte{1}, modified.Bytes[1:]...)
+		if modified.belongsTo(proposal) {
+			t.Fatalf("Expected the modified part not to belong to the proposal")
+		}
+	})
+}
+
+// newPartsTestCore returns a core at round 2 of height 1 for which addr is the
+// proposer.
+func newPartsTestCore(t *testing.T, backend Backend, addr common.Address) *core {
+	committee, err := newRoundRobinSet(types.Committee{{Address: addr, VotingPower: big.NewInt(1)}}, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages := newMessagesMap()
+	logger := log.New("backend", "test", "id", 0)
+	return &core{
+		address:          addr,
+		backend:          backend,
+		messages:         messages,
+		curRoundMessages: messages.getOrCreate(2),
+		logger:           logger,
+		round:            2,
+		height:           big.NewInt(1),
+		lockedRound:      -1,
+		validRound:       -1,
+		proposeTimeout:   newTimeout(propose, logger),
+		committee:        committee,
+	}
+}
+
+func TestHandleProposalPart(t *testing.T) {
+	addr := common.HexToAddress("0x0123456789")
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1), Extra: make([]byte, 2*proposalPartSize)})
+	proposal := NewProposal(2, big.NewInt(1), -1, block)
+	encoded, err := Encode(proposal)
+	if err != nil {
+		t.Fatalf("Expected <nil>, got %v", err)
+	}
+	// the messages are decoded as they would be once received
+	proposalMsg := func() *Message {
+		return &Message{Code: msgProposal, Msg: encoded, Address: addr, CommittedSeal: []byte{}, power: 1}
+	}
+	partMsg := func(part *ProposalPart, from common.Address) *Message {
+		enc, err := Encode(part)
+		if err != nil {
+			t.Fatalf("Expected <nil>, got %v", err)
+		}
+		return &Message{Code: msgProposalPart, Msg: enc, Address: from, CommittedSeal: []byte{}, power: 1}
+	}
+	// the block is verified and prevoted for once reassembled
+	expectPrevote := func(backendMock *MockBackend) {
+		backendMock.EXPECT().VerifyProposal(gomock.Any()).Do(func(b types.Block) {
+			if b.Hash() != block.Hash() {
+				t.Fatalf("Expected %v, got %v", block.Hash(), b.Hash())
+			}
+		})
+		backendMock.EXPECT().Sign(gomock.Any())
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any(), gomock.Any())
+	}
+
+	t.Run("parts received before the proposal", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		backendMock := NewMockBackend(ctrl)
+		c := newPartsTestCore(t, backendMock, addr)
+
+		for _, part := range proposal.Parts() {
+			if err := c.handleProposalPart(context.Background(), partMsg(part, addr)); err != nil {
+				t.Fatalf("Expected <nil>, got %v", err)
+			}
+		}
+		expectPrevote(backendMock)
+		if err := c.handleProposal(context.Background(), proposalMsg()); err != nil {
+			t.Fatalf("Expected <nil>, got %v", err)
+		}
+		if hash := c.curRoundMessages.GetProposalHash(); hash != block.Hash() {
+			t.Fatalf("Expected %v, got %v", block.Hash(), hash)
+		}
+		if c.step != prevote {
+			t.Fatalf("Expected step %v, got %v", prevote, c.step)
+		}
+	})
+
+	t.Run("proposal received before its parts", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		backendMock := NewMockBackend(ctrl)
+		c := newPartsTestCore(t, backendMock, addr)
+
+		msg := proposalMsg()
+		if err := c.handleProposal(context.Background(), msg); err != errIncompleteProposal {
+			t.Fatalf("Expected %v, got %v", errIncompleteProposal, err)
+		}
+		if c.curRoundMessages.ProposalMsg() != msg || c.curRoundMessages.GetProposalHash() != (common.Hash{}) {
+			t.Fatalf("Expected the proposal to be held without its block")
+		}
+		parts := proposal.Parts()
+		for _, part := range parts[:len(parts)-1] {
+			if err := c.handleProposalPart(context.Background(), partMsg(part, addr)); err != nil {
+				t.Fatalf("Expected <nil>, got %v", err)
+			}
+		}
+		if c.step != propose {
+			t.Fatalf("Expected step %v, got %v", propose, c.step)
+		}
+		expectPrevote(backendMock)
+		if err := c.handleProposalPart(context.Background(), partMsg(parts[len(parts)-1], addr)); err != nil {
+			t.Fatalf("Expected <nil>, got %v", err)
+		}
+		if hash := c.curRoundMessages.GetProposalHash(); hash != block.Hash() {
+			t.Fatalf("Expected %v, got %v", block.Hash(), hash)
+		}
+		if c.step != prevote {
+			t.Fatalf("Expected step %v, got %v", prevote, c.step)
+		}
+	})
+
+	t.Run("parts of another block are dropped", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		c := newPartsTestCore(t, NewMockBackend(ctrl), addr)
+
+		other := NewProposal(2, big.NewInt(1), -1, types.NewBlockWithHeader(&types.Header{Number: big.NewInt(2)}))
+		if err := c.handleProposalPart(context.Background(), partMsg(other.Parts()[0], addr)); err != nil {
+			t.Fatalf("Expected <nil>, got %v", err)
+		}
+		if err := c.handleProposal(context.Background(), proposalMsg()); err != errIncompleteProposal {
+			t.Fatalf("Expected %v, got %v", errIncompleteProposal, err)
+		}
+		if n := c.curRoundMessages.ProposalPartsCount(); n != 0 {
+			t.Fatalf("Expected the part received ahead to be dropped, got %d parts", n)
+		}
+		if err := c.handleProposalPart(context.Background(), partMsg(other.Parts()[0], addr)); err != errInvalidProposalPart {
+			t.Fatalf("Expected %v, got %v", errInvalidProposalPart, err)
+		}
+	})
+
+	t.Run("duplicate part", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		c := newPartsTestCore(t, NewMockBackend(ctrl), addr)
+
+		part := proposal.Parts()[0]
+		if err := c.handleProposalPart(context.Background(), partMsg(part, addr)); err != nil {
+			t.Fatalf("Expected <nil>, got %v", err)
+		}
+		if err := c.handleProposalPart(context.Background(), partMsg(part, addr)); err != errDuplicateProposalPart {
+			t.Fatalf("Expected %v, got %v", errDuplicateProposalPart, err)
+		}
+	})
+
+	t.Run("part from non-proposer", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		c := newPartsTestCore(t, NewMockBackend(ctrl), addr)
+
+		if err := c.handleProposalPart(context.Background(), partMsg(proposal.Parts()[0], common.HexToAddress("0x1"))); err != errNotFromProposer {
+			t.Fatalf("Expected %v, got %v", errNotFromProposer, err)
+		}
+	})
+
+	t.Run("future round part", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		c := newPartsTestCore(t, NewMockBackend(ctrl), addr)
+
+		future := NewProposal(3, big.NewInt(1), -1, block)
+		if err := c.handleProposalPart(context.Background(), partMsg(future.Parts()[0], addr)); err != errFutureRoundMessage {
+			t.Fatalf("Expected %v, got %v", errFutureRoundMessage, err)
+		}
+	})
+}
@@ -17,6 +17,7 @@
 package core
 
 import (
+	"bytes"
 	"context"
 	"time"
 
@@ -30,25 +31,89 @@ func (c *core) sendProposal(ctx context.Context, p *types.Block) {
 
 	// If I'm the proposer and I have the same height with the proposal
 	if c.Height().Cmp(p.Number()) == 0 && c.isProposer() && !c.sentProposal {
-		proposalBlock := NewProposal(c.Round(), c.Height(), c.validRound, p)
+		if c.resendSignedMessage(ctx, msgProposal) {
+			c.sentProposal = true
+			return
+		}
+
+		var proposalBlock *Proposal
+		if c.compactProposals {
+			proposalBlock = NewCompactProposal(c.Round(), c.Height(), c.validRound, p)
+			c.backend.ServeTransactions(p)
+		} else {
+			proposalBlock = NewProposal(c.Round(), c.Height(), c.validRound, p)
+		}
 		proposal, err := Encode(proposalBlock)
 		if err != nil {
 			logger.Error("Failed to encode", "Round", proposalBlock.Round, "Height", proposalBlock.Height, "ValidRound", c.validRound)
 			return
 		}
+		parts := make([]*Message, len(proposalBlock.Parts()))
+		for i, part := range proposalBlock.Parts() {
+			enc, err := Encode(part)
+			if err != nil {
+				logger.Error("Failed to encode", "part", part)
+				return
+			}
+			parts[i] = &Message{
+				Code:          msgProposalPart,
+				Msg:           enc,
+				Address:       c.address,
+				CommittedSeal: []byte{},
+			}
+		}
 
 		c.sentProposal = true
 		c.backend.SetProposedBlockHash(p.Hash())
 
 		c.logProposalMessageEvent("MessageEvent(Proposal): Sent", *proposalBlock, c.address.String(), "broadcast")
 
-		c.broadcast(ctx, &Message{
+		c.broadcastProposal(ctx, &Message{
 			Code:          msgProposal,
 			Msg:           proposal,
 			Address:       c.address,
 			CommittedSeal: []byte{},
-		})
+		}, parts)
+	}
+}
+
+// broadcastProposal broadcasts the proposal, the parts of its block are spread
+// among the committee which gossips them.
+func (c *core) broadcastProposal(ctx context.Context, msg *Message, parts []*Message) {
+	logger := c.logger.New("step", c.step)
+
+	payload, err := c.finalizeMessage(msg)
+	if err != nil {
+		logger.Error("Failed to finalize message", "msg", msg, "err", err)
+		return
+	}
+	partPayloads := make([][]byte, len(parts))
+	for i, part := range parts {
+		if partPayloads[i], err = c.finalizeMessage(part); err != nil {
+			logger.Error("Failed to finalize message", "msg", part, "err", err)
+			return
+		}
 	}
+
+	// The messages must be persisted before leaving the node, otherwise we could sign a conflicting one after a restart.
+	if err = c.persistState(append([]*Message{msg}, parts...)...); err != nil {
+		logger.Error("Failed to write message to WAL", "msg", msg, "err", err)
+		return
+	}
+
+	// Our own parts are held before the proposal comes back to us.
+	for _, part := range parts {
+		if err := c.handleProposalPart(ctx, part); err != nil {
+			logger.Error("Failed to handle own proposal part", "msg", part, "err", err)
+		}
+	}
+
+	logger.Debug("broadcasting", "msg", msg.String())
+	if err = c.backend.Broadcast(ctx, c.committeeSet().Committee(), payload); err != nil {
+		logger.Error("Failed to broadcast message", "msg", msg, "err", err)
+		return
+	}
+	c.backend.GossipParts(ctx, c.committeeSet().Committee(), partPayloads)
 }
 
 func (c *core) handleProposal(ctx context.Context, msg *Message) error {
@@ -68,16 +133,23 @@ func (c *core) handleProposal(ctx context.Context, msg *Message) error {
 
 			roundMsgs := c.messages.getOrCreate(proposal.Round)
 
+			if c.isConflictingProposal(roundMsgs, msg) {
+				return errConflictingProposal
+			}
+
 			// if we already have a proposal then it must be different than the current one
 			// it can't happen unless someone's byzantine.
 			if roundMsgs.proposal != nil {
-				return err // do not gossip, TODO: accountability
+				return err // do not gossip
 			}
 
 			if !c.isProposerMsg(proposal.Round, msg.Address) {
 				c.logger.Warn("Ignore proposal messages from non-proposer")
 				return errNotFromProposer
 			}
+			if err := c.completeProposal(roundMsgs, &proposal, msg); err != nil {
+				return err
+			}
 			// We do not verify the proposal in this case.
 			roundMsgs.SetProposal(&proposal, msg, false)
 
@@ -99,6 +171,15 @@ func (c *core) handleProposal(ctx context.Context, msg *Message) error {
 		return errNotFromProposer
 	}
 
+	// A second proposal from the proposer for the current round is never accepted.
+	if c.isConflictingProposal(c.curRoundMessages, msg) {
+		return errConflictingProposal
+	}
+
+	if err := c.completeProposal(c.curRoundMessages, &proposal, msg); err != nil {
+		return err
+	}
+
 	// Verify the proposal we received
 	if duration, err := c.backend.VerifyProposal(*proposal.ProposalBlock); err != nil {
 
@@ -186,7 +267,96 @@ func (c *core) logProposalMessageEvent(message string, proposal Proposal, from,
 		"currentStep", c.step,
 		"isProposer", c.isProposer(),
 		"currentProposer", c.committeeSet().GetProposer(c.Round()),
-		"isNilMsg", proposal.ProposalBlock.Hash() == common.Hash{},
-		"hash", proposal.ProposalBlock.Hash(),
+		"isNilMsg", proposal.BlockHash == common.Hash{},
+		"hash", proposal.BlockHash,
+	)
+}
+
+// isConflictingProposal reports whether msg is a proposal different from the one
+// we have already received from the same proposer for the round, in which case
+// the evidence of the equivocation is recorded.
+func (c *core) isConflictingProposal(roundMsgs *roundMessages, msg *Message) bool {
+	prev := roundMsgs.ProposalMsg()
+	if prev == nil || prev.Address != msg.Address || bytes.Equal(prev.Msg, msg.Msg) {
+		return false
+	}
+	c.recordEvidence(prev, msg)
+	return true
+}
+
+// completeProposal reassembles the block of the proposal from the parts held
+// for its round. If some parts, or some transactions of a compact proposal, are
+// missing, the proposal is held until they are received, see handleProposalPart
+// and handleProposalTransactions.
+func (c *core) completeProposal(roundMsgs *roundMessages, proposal *Proposal, msg *Message) error {
+	if proposal.ProposalBlock != nil {
+		return nil
+	}
+	parts := make(map[uint64]*ProposalPart)
+	for i, partMsg := range roundMsgs.ProposalParts() {
+		part := new(ProposalPart)
+		if err := partMsg.Decode(part); err != nil || !part.belongsTo(proposal) {
+			// the part received ahead of the proposal is dropped, so that
+			// the right one is asked for on sync.
+			roundMsgs.RemoveProposalPart(i)
+			continue
+		}
+		parts[i] = part
+	}
+	var (
+		block *types.Block
+		err   error
 	)
+	if proposal.PartSet.Compact {
+		block, err = c.rebuildBlock(proposal, parts, msg.Address)
+	} else {
+		block, err = assembleBlock(proposal, parts)
+	}
+	if err == errIncompleteProposal {
+		roundMsgs.SetProposal(proposal, msg, false)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	proposal.ProposalBlock = block
+	// the message holds the reassembled block from now on
+	msg.decodedMsg = proposal
+	return nil
+}
+
+func (c *core) handleProposalPart(ctx context.Context, msg *Message) error {
+	var part ProposalPart
+	if err := msg.Decode(&part); err != nil {
+		return errFailedDecodeProposalPart
+	}
+
+	// The parts of the old rounds are kept as well for the proposals which
+	// can still be committed, the future rounds parts are pushed on to the
+	// backlog.
+	if err := c.checkMessage(part.Round, part.Height, propose); err != nil && err != errOldRoundMessage {
+		return err
+	}
+
+	if !c.isProposerMsg(part.Round, msg.Address) {
+		c.logger.Warn("Ignore proposal parts from non-proposer")
+		return errNotFromProposer
+	}
+
+	roundMsgs := c.messages.getOrCreate(part.Round)
+	proposalMsg, proposal := roundMsgs.ProposalMsg(), roundMsgs.Proposal()
+	if proposalMsg != nil && !part.belongsTo(proposal) {
+		return errInvalidProposalPart
+	}
+	if !roundMsgs.AddProposalPart(part.Index, msg) {
+		return errDuplicateProposalPart
+	}
+
+	// The proposal held is handled again once its block is complete.
+	if proposalMsg != nil && proposal.ProposalBlock == nil && uint64(roundMsgs.ProposalPartsCount()) == proposal.PartSet.Total {
+		if err := c.handleProposal(ctx, proposalMsg); err != nil {
+			c.logger.Debug("Failed to handle completed proposal", "err", err)
+		}
+	}
+	return nil
 }
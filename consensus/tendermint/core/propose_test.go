@@ -52,6 +52,22 @@ func TestSendPropose(t *testing.T) {
 
 		payload := expectedMsg.Payload()
 
+		part, err := Encode(proposalBlock.Parts()[0])
+		if err != nil {
+			t.Fatalf("Expected <nil>, got %v", err)
+		}
+		partMsg := &Message{
+			Code:          msgProposalPart,
+			Msg:           part,
+			Address:       addr,
+			CommittedSeal: []byte{},
+			Signature:     []byte{0x1},
+		}
+		partNoSig, err := partMsg.PayloadNoSig()
+		if err != nil {
+			t.Fatalf("Expected nil, got %v", err)
+		}
+
 		testCommittee := types.Committee{
 			types.CommitteeMember{
 				Address:     addr,
@@ -66,7 +82,9 @@ func TestSendPropose(t *testing.T) {
 		backendMock := NewMockBackend(ctrl)
 		backendMock.EXPECT().SetProposedBlockHash(block.Hash())
 		backendMock.EXPECT().Sign(payloadNoSig).Return([]byte{0x1}, nil)
+		backendMock.EXPECT().Sign(partNoSig).Return([]byte{0x1}, nil)
 		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any(), payload)
+		backendMock.EXPECT().GossipParts(gomock.Any(), gomock.Any(), [][]byte{partMsg.Payload()})
 
 		c := &core{
 			address:          addr,
@@ -81,6 +99,10 @@ func TestSendPropose(t *testing.T) {
 		}
 
 		c.sendProposal(context.Background(), block)
+
+		if parts := curRoundMessages.ProposalPartsCount(); parts != 1 {
+			t.Fatalf("Expected 1 part held, got %d", parts)
+		}
 	})
 }
 
@@ -105,6 +127,7 @@ func TestHandleProposal(t *testing.T) {
 		msg := &Message{
 			Code:          msgProposal,
 			Msg:           proposal,
+			decodedMsg:    proposalBlock,
 			Address:       addr,
 			CommittedSeal: []byte{},
 			Signature:     []byte{0x1},
@@ -147,6 +170,7 @@ func TestHandleProposal(t *testing.T) {
 		msg := &Message{
 			Code:          msgProposal,
 			Msg:           proposal,
+			decodedMsg:    proposalBlock,
 			Address:       addr,
 			CommittedSeal: []byte{},
 			Signature:     []byte{0x1},
@@ -199,6 +223,7 @@ func TestHandleProposal(t *testing.T) {
 		msg := &Message{
 			Code:          msgProposal,
 			Msg:           proposal,
+			decodedMsg:    proposalBlock,
 			Address:       addr,
 			CommittedSeal: []byte{},
 			Signature:     []byte{0x1},
@@ -290,6 +315,7 @@ func TestHandleProposal(t *testing.T) {
 		msg := &Message{
 			Code:          msgProposal,
 			Msg:           proposal,
+			decodedMsg:    proposalBlock,
 			Address:       addr,
 			CommittedSeal: []byte{},
 			Signature:     []byte{0x1},
@@ -352,6 +378,7 @@ func TestHandleProposal(t *testing.T) {
 		msg := &Message{
 			Code:          msgProposal,
 			Msg:           proposal,
+			decodedMsg:    proposalBlock,
 			Address:       addr,
 			CommittedSeal: []byte{},
 			Signature:     []byte{0x1},
@@ -445,6 +472,7 @@ func TestHandleProposal(t *testing.T) {
 		msg := &Message{
 			Code:          msgProposal,
 			Msg:           proposal,
+			decodedMsg:    proposalMsg,
 			Address:       proposer.Address,
 			CommittedSeal: []byte{},
 			Signature:     []byte{0x1},
@@ -484,6 +512,7 @@ func TestHandleProposal(t *testing.T) {
 		msg := &Message{
 			Code:          msgProposal,
 			Msg:           proposal,
+			decodedMsg:    proposalBlock,
 			Address:       addr,
 			CommittedSeal: []byte{},
 			Signature:     []byte{0x1},
@@ -579,6 +608,7 @@ func TestHandleProposal(t *testing.T) {
 		msg := &Message{
 			Code:          msgProposal,
 			Msg:           proposal,
+			decodedMsg:    proposalBlock,
 			Address:       addr,
 			CommittedSeal: []byte{},
 			Signature:     []byte{0x1},
@@ -93,6 +93,7 @@ type roundMessages struct {
 	proposal         *Proposal
 	verifiedProposal bool
 	proposalMsg      *Message
+	parts            map[uint64]*Message // the parts of the proposed block by index
 	prevotes         messageSet
 	precommits       messageSet
 	mu               sync.RWMutex
@@ -103,6 +104,7 @@ type roundMessages struct {
 func NewRoundMessages() *roundMessages {
 	return &roundMessages{
 		proposal:         new(Proposal),
+		parts:            make(map[uint64]*Message),
 		prevotes:         newMessageSet(),
 		precommits:       newMessageSet(),
 		verifiedProposal: false,
@@ -130,12 +132,12 @@ func (s *roundMessages) PrecommitsTotalPower() uint64 {
 	return s.precommits.TotalVotePower()
 }
 
-func (s *roundMessages) AddPrevote(hash common.Hash, msg Message) {
-	s.prevotes.AddVote(hash, msg)
+func (s *roundMessages) AddPrevote(hash common.Hash, msg Message) *Message {
+	return s.prevotes.AddVote(hash, msg)
 }
 
-func (s *roundMessages) AddPrecommit(hash common.Hash, msg Message) {
-	s.precommits.AddVote(hash, msg)
+func (s *roundMessages) AddPrecommit(hash common.Hash, msg Message) *Message {
+	return s.precommits.AddVote(hash, msg)
 }
 
 func (s *roundMessages) CommitedSeals(hash common.Hash) []Message {
@@ -153,6 +155,55 @@ func (s *roundMessages) Proposal() *Proposal {
 	return nil
 }
 
+// ProposalMsg returns the signed message of the proposal we have received, if any.
+func (s *roundMessages) ProposalMsg() *Message {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.proposalMsg
+}
+
+// AddProposalPart stores a part of the proposed block, it returns false if a
+// part of the same index is held already.
+func (s *roundMessages) AddProposalPart(index uint64, msg *Message) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.parts[index]; ok {
+		return false
+	}
+	s.parts[index] = msg
+	return true
+}
+
+// RemoveProposalPart drops a part which doesn't belong to the proposal.
+func (s *roundMessages) RemoveProposalPart(index uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.parts, index)
+}
+
+// ProposalPartsCount returns the number of parts of the proposed block held.
+func (s *roundMessages) ProposalPartsCount() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.parts)
+}
+
+// ProposalParts returns the parts of the proposed block held by index.
+func (s *roundMessages) ProposalParts() map[uint64]*Message {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	parts := make(map[uint64]*Message, len(s.parts))
+	for i, msg := range s.parts {
+		parts[i] = msg
+	}
+	return parts
+}
+
 func (s *roundMessages) isProposalVerified() bool {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -178,10 +229,13 @@ func (s *roundMessages) GetMessages() []*Message {
 	prevoteMsgs := s.prevotes.GetMessages()
 	precommitMsgs := s.precommits.GetMessages()
 
-	result := make([]*Message, 0, len(prevoteMsgs)+len(precommitMsgs)+1)
+	result := make([]*Message, 0, len(prevoteMsgs)+len(precommitMsgs)+len(s.parts)+1)
 	if s.proposalMsg != nil {
 		result = append(result, s.proposalMsg)
 	}
+	for _, part := range s.parts {
+		result = append(result, part)
+	}
 
 	result = append(result, prevoteMsgs...)
 	result = append(result, precommitMsgs...)
@@ -4,6 +4,7 @@ import (
 	"github.com/clearmatics/autonity/common"
 	"github.com/clearmatics/autonity/core/types"
 	"math/big"
+	"time"
 )
 
 type coreStateRequestEvent struct {
@@ -69,6 +70,16 @@ type TendermintState struct {
 	PrevoteTimerStarted   bool
 	PrecommitTimerStarted bool
 
+	// timeouts of the current round and their increase at each round, in milliseconds.
+	ProposeTimeout        uint64
+	PrevoteTimeout        uint64
+	PrecommitTimeout      uint64
+	ProposeTimeoutDelta   uint64
+	PrevoteTimeoutDelta   uint64
+	PrecommitTimeoutDelta uint64
+	ExponentialTimeouts   bool
+	AdaptiveTimeouts      bool
+
 	// current height messages.
 	CurHeightMessages []*MsgForDump
 	// backlog msgs
@@ -122,6 +133,15 @@ func (c *core) handleStateDump(e coreStateRequestEvent) {
 		ProposeTimerStarted:   c.proposeTimeout.timerStarted(),
 		PrevoteTimerStarted:   c.prevoteTimeout.timerStarted(),
 		PrecommitTimerStarted: c.precommitTimeout.timerStarted(),
+		// timeouts
+		ProposeTimeout:        uint64(c.timeoutPropose(c.Round()) / time.Millisecond),
+		PrevoteTimeout:        uint64(c.timeoutPrevote(c.Round()) / time.Millisecond),
+		PrecommitTimeout:      uint64(c.timeoutPrecommit(c.Round()) / time.Millisecond),
+		ProposeTimeoutDelta:   uint64(c.timeoutConfig().proposeDelta / time.Millisecond),
+		PrevoteTimeoutDelta:   uint64(c.timeoutConfig().prevoteDelta / time.Millisecond),
+		PrecommitTimeoutDelta: uint64(c.timeoutConfig().precommitDelta / time.Millisecond),
+		ExponentialTimeouts:   c.timeoutConfig().exponential,
+		AdaptiveTimeouts:      c.timeoutConfig().adaptive,
 		// known msgs in case of gossiping.
 		KnownMsgHash: c.backend.KnownMsgHash(),
 	}
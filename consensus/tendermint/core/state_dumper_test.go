@@ -30,7 +30,7 @@ func TestGetProposal(t *testing.T) {
 	nodeAddr := common.BytesToAddress([]byte("node"))
 	backendMock := NewMockBackend(ctrl)
 	backendMock.EXPECT().Address().Return(nodeAddr)
-	core := New(backendMock, config.RoundRobinConfig())
+	core := New(backendMock, config.RoundRobinConfig(), nil)
 
 	proposalMsg, proposal := randomProposal(t)
 	core.messages.getOrCreate(proposal.Round).SetProposal(&proposal, proposalMsg, true)
@@ -47,7 +47,7 @@ func TestGetRoundState(t *testing.T) {
 	backendMock := NewMockBackend(ctrl)
 	backendMock.EXPECT().Address().Return(sender)
 
-	c := New(backendMock, config.DefaultConfig())
+	c := New(backendMock, config.DefaultConfig(), nil)
 
 	var rounds []int64 = []int64{0, 1}
 	height := big.NewInt(int64(100) + 1)
@@ -82,7 +82,7 @@ func TestGetCoreState(t *testing.T) {
 	backendMock.EXPECT().Address().Return(sender)
 	backendMock.EXPECT().KnownMsgHash().Return(knownMsgHash)
 
-	c := New(backendMock, config.DefaultConfig())
+	c := New(backendMock, config.DefaultConfig(), nil)
 
 	var rounds []int64 = []int64{0, 1}
 
@@ -0,0 +1,120 @@
+package core
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/core/types"
+)
+
+// syncPeriod is the period of the sync requests, a request is answered with
+// the messages missing only hence it is cheap to repeat.
+const syncPeriod = 2 * time.Second
+
+// VoteBitmap tells which messages of a round and step are held, bit i stands
+// for the message of the i-th member of the committee, or for the i-th part of
+// the proposed block.
+type VoteBitmap struct {
+	Round uint64
+	Code  uint64
+	Bits  []byte
+}
+
+// SyncRequest advertises the consensus messages held by a node at a height,
+// the peers reply with the messages missing only.
+type SyncRequest struct {
+	Height  *big.Int
+	Bitmaps []VoteBitmap
+}
+
+type bitmapKey struct {
+	round uint64
+	code  uint64
+}
+
+func committeeIndexes(committee types.Committee) map[common.Address]int {
+	indexes := make(map[common.Address]int, len(committee))
+	for i, member := range committee {
+		indexes[member.Address] = i
+	}
+	return indexes
+}
+
+// messageBit returns the bitmap and the bit of the message, false if the
+// message isn't from the committee or can't be decoded.
+func messageBit(msg *Message, indexes map[common.Address]int) (bitmapKey, int, bool) {
+	i, ok := indexes[msg.Address]
+	if !ok {
+		return bitmapKey{}, 0, false
+	}
+	round, err := msg.Round()
+	if err != nil || round < 0 {
+		return bitmapKey{}, 0, false
+	}
+	if msg.Code == msgProposalPart {
+		var part ProposalPart
+		if err := msg.Decode(&part); err != nil {
+			return bitmapKey{}, 0, false
+		}
+		i = int(part.Index)
+	}
+	return bitmapKey{round: uint64(round), code: msg.Code}, i, true
+}
+
+// NewSyncRequest returns the sync request advertising the messages held at the
+// height following the given header, whose committee indexes the bitmaps.
+func NewSyncRequest(header *types.Header, messages []*Message) *SyncRequest {
+	request := &SyncRequest{Height: new(big.Int).Add(header.Number, common.Big1)}
+	indexes := committeeIndexes(header.Committee)
+	bitmaps := make(map[bitmapKey]int)
+	for _, msg := range messages {
+		if height, err := msg.Height(); err != nil || height.Cmp(request.Height) != 0 {
+			continue
+		}
+		key, i, ok := messageBit(msg, indexes)
+		if !ok {
+			continue
+		}
+		pos, ok := bitmaps[key]
+		if !ok {
+			pos = len(request.Bitmaps)
+			bitmaps[key] = pos
+			request.Bitmaps = append(request.Bitmaps, VoteBitmap{
+				Round: key.round,
+				Code:  key.code,
+				Bits:  make([]byte, (len(header.Committee)+7)/8),
+			})
+		}
+		// the parts of the proposed block can outnumber the committee
+		for len(request.Bitmaps[pos].Bits) <= i/8 {
+			request.Bitmaps[pos].Bits = append(request.Bitmaps[pos].Bits, 0)
+		}
+		request.Bitmaps[pos].Bits[i/8] |= 1 << uint(i%8)
+	}
+	return request
+}
+
+// Missing returns the messages the requester doesn't hold among the messages
+// given, the committee of the height following header indexes the bitmaps.
+// All the messages are missing if the requester is at another height.
+func (r *SyncRequest) Missing(header *types.Header, messages []*Message) []*Message {
+	if r.Height == nil || r.Height.Cmp(new(big.Int).Add(header.Number, common.Big1)) != 0 {
+		return messages
+	}
+	indexes := committeeIndexes(header.Committee)
+	bitmaps := make(map[bitmapKey][]byte, len(r.Bitmaps))
+	for _, bitmap := range r.Bitmaps {
+		bitmaps[bitmapKey{round: bitmap.Round, code: bitmap.Code}] = bitmap.Bits
+	}
+	missing := make([]*Message, 0, len(messages))
+	for _, msg := range messages {
+		if key, i, ok := messageBit(msg, indexes); ok {
+			if bits := bitmaps[key]; i/8 < len(bits) && bits[i/8]&(1<<uint(i%8)) != 0 {
+				continue
+			}
+		}
+		missing = append(missing, msg)
+	}
+	return missing
+}
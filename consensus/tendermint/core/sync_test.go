@@ -0,0 +1,98 @@
+package core
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/rlp"
+)
+
+func TestSyncRequest(t *testing.T) {
+	members, keys := generateCommittee(10)
+	header := &types.Header{Number: big.NewInt(4), Committee: members}
+	decode := func(payload []byte) *Message {
+		msg := new(Message)
+		if err := msg.FromPayload(payload); err != nil {
+			t.Fatal(err)
+		}
+		return msg
+	}
+	var prevotes, precommits []*Message
+	for _, member := range members {
+		prevotes = append(prevotes, decode(signedVote(t, msgPrevote, big.NewInt(5), keys[member.Address], member.Address)))
+		precommits = append(precommits, decode(signedVote(t, msgPrecommit, big.NewInt(5), keys[member.Address], member.Address)))
+	}
+	held := append([]*Message{prevotes[0], prevotes[9]}, precommits[3:]...)
+	request := NewSyncRequest(header, held)
+
+	t.Run("bitmaps", func(t *testing.T) {
+		expected := []VoteBitmap{
+			{Round: 0, Code: msgPrevote, Bits: []byte{0x01, 0x02}},
+			{Round: 0, Code: msgPrecommit, Bits: []byte{0xf8, 0x03}},
+		}
+		if request.Height.Uint64() != 5 || !reflect.DeepEqual(request.Bitmaps, expected) {
+			t.Fatalf("Expected %v, got %v", expected, request.Bitmaps)
+		}
+	})
+
+	t.Run("encoding", func(t *testing.T) {
+		encoded, err := rlp.EncodeToBytes(request)
+		assertNilError(t, err)
+		decoded := new(SyncRequest)
+		assertNilError(t, rlp.DecodeBytes(encoded, decoded))
+		if decoded.Height.Cmp(request.Height) != 0 || !reflect.DeepEqual(decoded.Bitmaps, request.Bitmaps) {
+			t.Fatalf("Expected %v, got %v", request, decoded)
+		}
+	})
+
+	t.Run("missing messages", func(t *testing.T) {
+		all := append(append([]*Message{}, prevotes...), precommits...)
+		missing := request.Missing(header, all)
+		expected := append(append([]*Message{}, prevotes[1:9]...), precommits[:3]...)
+		if !reflect.DeepEqual(missing, expected) {
+			t.Fatalf("Expected %d messages, got %d", len(expected), len(missing))
+		}
+	})
+
+	t.Run("requester at another height", func(t *testing.T) {
+		next := &types.Header{Number: big.NewInt(5), Committee: members}
+		if missing := request.Missing(next, held); len(missing) != len(held) {
+			t.Fatalf("Expected %d messages, got %d", len(held), len(missing))
+		}
+	})
+
+	t.Run("parts of the proposed block", func(t *testing.T) {
+		// the parts outnumber the committee
+		block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(5), Extra: make([]byte, 11*proposalPartSize)})
+		proposal := NewProposal(0, big.NewInt(5), -1, block)
+		var parts []*Message
+		for _, part := range proposal.Parts() {
+			enc, err := Encode(part)
+			assertNilError(t, err)
+			msg := &Message{Code: msgProposalPart, Msg: enc, Address: members[0].Address, CommittedSeal: []byte{}}
+			parts = append(parts, decode(msg.Payload()))
+		}
+		if len(parts) != 12 {
+			t.Fatalf("Expected 12 parts, got %d", len(parts))
+		}
+
+		partsRequest := NewSyncRequest(header, append(append([]*Message{}, parts[:3]...), parts[4:11]...))
+		expected := []VoteBitmap{{Round: 0, Code: msgProposalPart, Bits: []byte{0xf7, 0x07}}}
+		if !reflect.DeepEqual(partsRequest.Bitmaps, expected) {
+			t.Fatalf("Expected %v, got %v", expected, partsRequest.Bitmaps)
+		}
+		missing := partsRequest.Missing(header, parts)
+		if !reflect.DeepEqual(missing, []*Message{parts[3], parts[11]}) {
+			t.Fatalf("Expected parts 3 and 11, got %d messages", len(missing))
+		}
+	})
+
+	t.Run("messages of other heights are not advertised", func(t *testing.T) {
+		old := decode(signedVote(t, msgPrevote, big.NewInt(4), keys[members[1].Address], members[1].Address))
+		if r := NewSyncRequest(header, []*Message{old}); len(r.Bitmaps) != 0 {
+			t.Fatalf("Expected no bitmap, got %v", r.Bitmaps)
+		}
+	})
+}
@@ -2,21 +2,93 @@ package core
 
 import (
 	"context"
-	"github.com/clearmatics/autonity/log"
 	"math/big"
 	"sync"
 	"time"
-)
 
-const (
-	initialProposeTimeout   = 2000 * time.Millisecond
-	proposeTimeoutDelta     = 500 * time.Millisecond
-	initialPrevoteTimeout   = 1000 * time.Millisecond
-	prevoteTimeoutDelta     = 500 * time.Millisecond
-	initialPrecommitTimeout = 1000 * time.Millisecond
-	precommitTimeoutDelta   = 500 * time.Millisecond
+	"github.com/clearmatics/autonity/consensus/tendermint/config"
+	"github.com/clearmatics/autonity/log"
 )
 
+// maxExponentialTimeout caps the step timeouts when their deltas double at each round.
+const maxExponentialTimeout = 10 * time.Minute
+
+// timeoutConfig holds the step timeouts at round 0 and their increase at each
+// round. When adaptive, the timeouts at round 0 are adjusted at each height
+// within the given bounds.
+type timeoutConfig struct {
+	propose        time.Duration
+	proposeDelta   time.Duration
+	prevote        time.Duration
+	prevoteDelta   time.Duration
+	precommit      time.Duration
+	precommitDelta time.Duration
+	exponential    bool
+	adaptive       bool
+	minAdaptive    time.Duration
+	maxAdaptive    time.Duration
+}
+
+var defaultTimeoutConfig = newTimeoutConfig(config.DefaultConfig())
+
+func newTimeoutConfig(cfg *config.Config) *timeoutConfig {
+	return &timeoutConfig{
+		propose:        time.Duration(*cfg.ProposeTimeout) * time.Millisecond,
+		proposeDelta:   time.Duration(*cfg.ProposeTimeoutDelta) * time.Millisecond,
+		prevote:        time.Duration(*cfg.PrevoteTimeout) * time.Millisecond,
+		prevoteDelta:   time.Duration(*cfg.PrevoteTimeoutDelta) * time.Millisecond,
+		precommit:      time.Duration(*cfg.PrecommitTimeout) * time.Millisecond,
+		precommitDelta: time.Duration(*cfg.PrecommitTimeoutDelta) * time.Millisecond,
+		exponential:    *cfg.ExponentialTimeouts,
+		adaptive:       *cfg.AdaptiveTimeouts,
+		minAdaptive:    time.Duration(*cfg.MinAdaptiveTimeout) * time.Millisecond,
+		maxAdaptive:    time.Duration(*cfg.MaxAdaptiveTimeout) * time.Millisecond,
+	}
+}
+
+// atRound returns the timeout of a step at the given round, the delta is either
+// added at each round or doubled at each round when the growth is exponential.
+func (t *timeoutConfig) atRound(initial, delta time.Duration, round int64) time.Duration {
+	if !t.exponential {
+		return initial + time.Duration(round)*delta
+	}
+	timeout := initial
+	for r := int64(0); r < round && timeout < maxExponentialTimeout; r++ {
+		timeout += delta
+		delta *= 2
+	}
+	if timeout > maxExponentialTimeout {
+		return maxExponentialTimeout
+	}
+	return timeout
+}
+
+// adapt returns the timeout at round 0 of a step for the next height given its
+// current value and what has been observed during the last height. The timeout
+// grows by half when it has expired, otherwise it moves half way towards twice
+// the longest duration observed.
+func (t *timeoutConfig) adapt(current time.Duration, samples []time.Duration, expired bool) time.Duration {
+	next := current
+	if expired {
+		next = current + current/2
+	} else if len(samples) > 0 {
+		var longest time.Duration
+		for _, s := range samples {
+			if s > longest {
+				longest = s
+			}
+		}
+		next = (current + 2*longest) / 2
+	}
+	if next < t.minAdaptive {
+		return t.minAdaptive
+	}
+	if next > t.maxAdaptive {
+		return t.maxAdaptive
+	}
+	return next
+}
+
 type TimeoutEvent struct {
 	roundWhenCalled  int64
 	heightWhenCalled *big.Int
@@ -24,13 +96,28 @@ type TimeoutEvent struct {
 	step uint64
 }
 
+// clock provides the time used to measure the duration of the steps, tests
+// use a fake one to control it.
+type clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
 type timeout struct {
 	timer   *time.Timer
 	started bool
 	step    Step
 	// start will be refreshed on each new schedule, it is used for metric collection of tendermint timeout.
 	start  time.Time
+	clock  clock
 	logger log.Logger
+	// samples are the durations of the steps which ended before the timeout
+	// expired, expired is set otherwise. Both are used to adapt the timeouts.
+	samples []time.Duration
+	expired bool
 	sync.Mutex
 }
 
@@ -39,16 +126,24 @@ func newTimeout(s Step, logger log.Logger) *timeout {
 		started: false,
 		step:    s,
 		start:   time.Now(),
+		clock:   systemClock{},
 		logger:  logger,
 	}
 }
 
+func (t *timeout) now() time.Time {
+	if t.clock == nil {
+		return time.Now()
+	}
+	return t.clock.Now()
+}
+
 // runAfterTimeout() will be run in a separate go routine, so values used inside the function needs to be managed separately
 func (t *timeout) scheduleTimeout(stepTimeout time.Duration, round int64, height *big.Int, runAfterTimeout func(r int64, h *big.Int)) {
 	t.Lock()
 	defer t.Unlock()
 	t.started = true
-	t.start = time.Now()
+	t.start = t.now()
 	t.timer = time.AfterFunc(stepTimeout, func() {
 		runAfterTimeout(round, height)
 	})
@@ -65,6 +160,7 @@ func (t *timeout) stopTimer() error {
 	defer t.Unlock()
 	if t.started {
 		if t.started = !t.timer.Stop(); t.started {
+			t.expired = true
 			switch t.step {
 			case propose:
 				return errNilPrevoteSent
@@ -80,16 +176,28 @@ func (t *timeout) stopTimer() error {
 }
 
 func (t *timeout) measureMetricsOnStopTimer() {
+	elapsed := t.now().Sub(t.start)
+	t.samples = append(t.samples, elapsed)
 	switch t.step {
 	case propose:
-		tendermintProposeTimer.UpdateSince(t.start)
+		tendermintProposeTimer.Update(elapsed)
 	case prevote:
-		tendermintPrevoteTimer.UpdateSince(t.start)
+		tendermintPrevoteTimer.Update(elapsed)
 	case precommit:
-		tendermintPrecommitTimer.UpdateSince(t.start)
+		tendermintPrecommitTimer.Update(elapsed)
 	}
 }
 
+// observations returns the step durations and whether the timeout has expired
+// since the last call.
+func (t *timeout) observations() ([]time.Duration, bool) {
+	t.Lock()
+	defer t.Unlock()
+	samples, expired := t.samples, t.expired
+	t.samples, t.expired = nil, false
+	return samples, expired
+}
+
 func (t *timeout) reset(s Step) {
 	err := t.stopTimer()
 	if err != nil {
@@ -184,15 +292,72 @@ func (c *core) handleTimeoutPrecommit(ctx context.Context, msg TimeoutEvent) {
 /////////////// Calculate Timeout Duration Functions ///////////////
 // The timeout may need to be changed depending on the Step
 func (c *core) timeoutPropose(round int64) time.Duration {
-	return initialProposeTimeout + time.Duration(c.blockPeriod)*time.Second + time.Duration(round)*proposeTimeoutDelta
+	t := c.timeoutConfig()
+	return t.atRound(t.propose, t.proposeDelta, round) + time.Duration(c.blockPeriod)*time.Second
+}
+
+// emptyBlockDelay returns how long the proposer may still wait for
+// transactions before proposing an empty block, the propose timeout is
+// extended by it so that it doesn't expire while the proposer is waiting.
+func (c *core) emptyBlockDelay() time.Duration {
+	if c.maxEmptyBlockInterval == 0 || c.lastHeader == nil {
+		return 0
+	}
+	deadline := time.Unix(int64(c.lastHeader.Time+c.maxEmptyBlockInterval), 0)
+	if delay := deadline.Sub(c.proposeTimeout.now()); delay > 0 {
+		return delay
+	}
+	return 0
 }
 
 func (c *core) timeoutPrevote(round int64) time.Duration {
-	return initialPrevoteTimeout + time.Duration(round)*prevoteTimeoutDelta
+	t := c.timeoutConfig()
+	return t.atRound(t.prevote, t.prevoteDelta, round)
 }
 
 func (c *core) timeoutPrecommit(round int64) time.Duration {
-	return initialPrecommitTimeout + time.Duration(round)*precommitTimeoutDelta
+	t := c.timeoutConfig()
+	return t.atRound(t.precommit, t.precommitDelta, round)
+}
+
+// adaptTimeouts adjusts the timeouts at round 0 to the step durations observed
+// during the last height, it is called when a new height starts.
+func (c *core) adaptTimeouts() {
+	proposeSamples, proposeExpired := c.proposeTimeout.observations()
+	prevoteSamples, prevoteExpired := c.prevoteTimeout.observations()
+	precommitSamples, precommitExpired := c.precommitTimeout.observations()
+	emptyBlockDelayed := c.emptyBlockDelayed
+	c.emptyBlockDelayed = false
+	if c.timeouts == nil || !c.timeouts.adaptive {
+		return
+	}
+	// the proposal was possibly held back for lack of transactions.
+	if emptyBlockDelayed {
+		proposeSamples = nil
+	}
+	// the propose step includes the block period that the proposer waits for.
+	blockPeriod := time.Duration(c.blockPeriod) * time.Second
+	for i, s := range proposeSamples {
+		if s -= blockPeriod; s < 0 {
+			s = 0
+		}
+		proposeSamples[i] = s
+	}
+
+	t := c.timeouts
+	t.propose = t.adapt(t.propose, proposeSamples, proposeExpired)
+	t.prevote = t.adapt(t.prevote, prevoteSamples, prevoteExpired)
+	t.precommit = t.adapt(t.precommit, precommitSamples, precommitExpired)
+	c.logger.Debug("Adapted timeouts", "propose", t.propose, "prevote", t.prevote, "precommit", t.precommit)
+}
+
+// timeoutConfig returns the step timeouts of the core, the default ones are
+// used if it wasn't created with New.
+func (c *core) timeoutConfig() *timeoutConfig {
+	if c.timeouts == nil {
+		return defaultTimeoutConfig
+	}
+	return c.timeouts
 }
 
 func (c *core) logTimeoutEvent(message string, msgType string, timeout TimeoutEvent) {
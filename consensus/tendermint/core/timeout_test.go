@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/consensus/tendermint/config"
 	"github.com/clearmatics/autonity/core/types"
 	"github.com/clearmatics/autonity/log"
 	"github.com/clearmatics/autonity/metrics"
@@ -91,7 +92,8 @@ func TestHandleTimeoutPrevote(t *testing.T) {
 			step:             msgPrevote,
 		}
 		// should send precommit nil
-		mockBackend.EXPECT().Sign(gomock.Any()).Times(2)
+		mockBackend.EXPECT().SignCommittedSeal(gomock.Any(), false)
+		mockBackend.EXPECT().Sign(gomock.Any())
 		mockBackend.EXPECT().Broadcast(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Do(
 			func(ctx context.Context, c types.Committee, payload []byte) {
 				message := new(Message)
@@ -224,3 +226,185 @@ func TestOnTimeoutPrecommit(t *testing.T) {
 	})
 	engine.onTimeoutPrecommit(2, big.NewInt(4))
 }
+
+func TestStepTimeouts(t *testing.T) {
+	ms := func(v uint64) *uint64 { return &v }
+	cfg := &config.Config{
+		ProposeTimeout:        ms(300),
+		ProposeTimeoutDelta:   ms(100),
+		PrevoteTimeout:        ms(200),
+		PrevoteTimeoutDelta:   ms(50),
+		PrecommitTimeout:      ms(200),
+		PrecommitTimeoutDelta: ms(50),
+	}
+	cfg.ApplyChainConfig(nil)
+
+	t.Run("linear timeouts", func(t *testing.T) {
+		c := &core{blockPeriod: 1, timeouts: newTimeoutConfig(cfg)}
+		if d := c.timeoutPropose(3); d != time.Second+600*time.Millisecond {
+			t.Fatalf("Expected %v, got %v", time.Second+600*time.Millisecond, d)
+		}
+		if d := c.timeoutPrevote(2); d != 300*time.Millisecond {
+			t.Fatalf("Expected %v, got %v", 300*time.Millisecond, d)
+		}
+		if d := c.timeoutPrecommit(0); d != 200*time.Millisecond {
+			t.Fatalf("Expected %v, got %v", 200*time.Millisecond, d)
+		}
+	})
+
+	t.Run("exponential timeouts", func(t *testing.T) {
+		exponential := *cfg
+		exponential.ExponentialTimeouts = new(bool)
+		*exponential.ExponentialTimeouts = true
+		c := &core{timeouts: newTimeoutConfig(&exponential)}
+		// 200 + 50 + 100 + 200
+		if d := c.timeoutPrevote(3); d != 550*time.Millisecond {
+			t.Fatalf("Expected %v, got %v", 550*time.Millisecond, d)
+		}
+		if d := c.timeoutPrecommit(MaxRound); d != maxExponentialTimeout {
+			t.Fatalf("Expected %v, got %v", maxExponentialTimeout, d)
+		}
+	})
+
+	t.Run("core not created with New", func(t *testing.T) {
+		c := &core{}
+		if d := c.timeoutPrevote(0); d != config.DefaultPrevoteTimeout*time.Millisecond {
+			t.Fatalf("Expected %v, got %v", config.DefaultPrevoteTimeout*time.Millisecond, d)
+		}
+	})
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func TestAdaptiveTimeouts(t *testing.T) {
+	logger := log.New("core", "test", "id", 0)
+	newCore := func(adaptive bool) (*core, *fakeClock) {
+		cfg := config.DefaultConfig()
+		cfg.AdaptiveTimeouts = &adaptive
+		clk := &fakeClock{now: time.Unix(1000, 0)}
+		return &core{
+			logger:           logger,
+			blockPeriod:      1,
+			timeouts:         newTimeoutConfig(cfg),
+			proposeTimeout:   &timeout{step: propose, clock: clk, logger: logger},
+			prevoteTimeout:   &timeout{step: prevote, clock: clk, logger: logger},
+			precommitTimeout: &timeout{step: precommit, clock: clk, logger: logger},
+		}, clk
+	}
+	// completeStep schedules a timeout which never expires during the test and
+	// stops it once the step has lasted for the given duration.
+	completeStep := func(t *testing.T, tm *timeout, clk *fakeClock, d time.Duration) {
+		tm.scheduleTimeout(time.Hour, 0, big.NewInt(1), func(int64, *big.Int) {})
+		clk.advance(d)
+		if err := tm.stopTimer(); err != nil {
+			t.Fatalf("Expected nil, got %v", err)
+		}
+	}
+	// expireStep waits for the timeout to expire before stopping it.
+	expireStep := func(t *testing.T, tm *timeout) {
+		fired := make(chan struct{})
+		tm.scheduleTimeout(time.Nanosecond, 0, big.NewInt(1), func(int64, *big.Int) { close(fired) })
+		<-fired
+		if err := tm.stopTimer(); err == nil {
+			t.Fatal("Expected error, got nil")
+		}
+	}
+
+	t.Run("steps shorter than the timeouts", func(t *testing.T) {
+		c, clk := newCore(true)
+		// the propose step includes the block period.
+		completeStep(t, c.proposeTimeout, clk, time.Second+150*time.Millisecond)
+		completeStep(t, c.prevoteTimeout, clk, 50*time.Millisecond)
+		completeStep(t, c.prevoteTimeout, clk, 100*time.Millisecond)
+		c.adaptTimeouts()
+
+		if c.timeouts.propose != 1150*time.Millisecond {
+			t.Fatalf("Expected %v, got %v", 1150*time.Millisecond, c.timeouts.propose)
+		}
+		if c.timeouts.prevote != 600*time.Millisecond {
+			t.Fatalf("Expected %v, got %v", 600*time.Millisecond, c.timeouts.prevote)
+		}
+		// nothing was observed for the precommit step.
+		if c.timeouts.precommit != config.DefaultPrecommitTimeout*time.Millisecond {
+			t.Fatalf("Expected %v, got %v", config.DefaultPrecommitTimeout*time.Millisecond, c.timeouts.precommit)
+		}
+	})
+
+	t.Run("expired timeout", func(t *testing.T) {
+		c, clk := newCore(true)
+		completeStep(t, c.precommitTimeout, clk, 10*time.Millisecond)
+		expireStep(t, c.precommitTimeout)
+		c.adaptTimeouts()
+		if c.timeouts.precommit != 1500*time.Millisecond {
+			t.Fatalf("Expected %v, got %v", 1500*time.Millisecond, c.timeouts.precommit)
+		}
+	})
+
+	t.Run("timeouts stay within the bounds", func(t *testing.T) {
+		c, clk := newCore(true)
+		for i := 0; i < 20; i++ {
+			completeStep(t, c.prevoteTimeout, clk, time.Millisecond)
+			expireStep(t, c.precommitTimeout)
+			c.adaptTimeouts()
+		}
+		if c.timeouts.prevote != config.DefaultMinAdaptiveTimeout*time.Millisecond {
+			t.Fatalf("Expected %v, got %v", config.DefaultMinAdaptiveTimeout*time.Millisecond, c.timeouts.prevote)
+		}
+		if c.timeouts.precommit != config.DefaultMaxAdaptiveTimeout*time.Millisecond {
+			t.Fatalf("Expected %v, got %v", config.DefaultMaxAdaptiveTimeout*time.Millisecond, c.timeouts.precommit)
+		}
+	})
+
+	t.Run("propose step delayed for an empty block", func(t *testing.T) {
+		c, clk := newCore(true)
+		c.emptyBlockDelayed = true
+		completeStep(t, c.proposeTimeout, clk, 30*time.Second)
+		c.adaptTimeouts()
+		if c.timeouts.propose != config.DefaultProposeTimeout*time.Millisecond {
+			t.Fatalf("Expected %v, got %v", config.DefaultProposeTimeout*time.Millisecond, c.timeouts.propose)
+		}
+		if c.emptyBlockDelayed {
+			t.Fatal("Expected the delay to be cleared at the next height")
+		}
+	})
+
+	t.Run("adaptation disabled", func(t *testing.T) {
+		c, clk := newCore(false)
+		completeStep(t, c.prevoteTimeout, clk, 50*time.Millisecond)
+		c.adaptTimeouts()
+		if c.timeouts.prevote != config.DefaultPrevoteTimeout*time.Millisecond {
+			t.Fatalf("Expected %v, got %v", config.DefaultPrevoteTimeout*time.Millisecond, c.timeouts.prevote)
+		}
+		if samples, _ := c.prevoteTimeout.observations(); len(samples) != 0 {
+			t.Fatalf("Expected observations to be cleared, got %v", samples)
+		}
+	})
+}
+
+func TestEmptyBlockDelay(t *testing.T) {
+	clk := &fakeClock{now: time.Unix(1000, 0)}
+	c := &core{
+		maxEmptyBlockInterval: 60,
+		lastHeader:            &types.Header{Time: 990},
+		proposeTimeout:        &timeout{step: propose, clock: clk},
+	}
+
+	if delay := c.emptyBlockDelay(); delay != 50*time.Second {
+		t.Fatalf("Expected %v, got %v", 50*time.Second, delay)
+	}
+	clk.advance(time.Minute)
+	if delay := c.emptyBlockDelay(); delay != 0 {
+		t.Fatalf("Expected 0, got %v", delay)
+	}
+	c.maxEmptyBlockInterval = 0
+	clk.now = time.Unix(1000, 0)
+	if delay := c.emptyBlockDelay(); delay != 0 {
+		t.Fatalf("Expected 0, got %v", delay)
+	}
+}
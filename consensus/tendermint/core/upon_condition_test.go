@@ -76,10 +76,10 @@ func TestStartRoundVariables(t *testing.T) {
 		defer ctrl.Finish()
 
 		backendMock := NewMockBackend(ctrl)
-		backendMock.EXPECT().Address().Return(clientAddress)
+		backendMock.EXPECT().Address().Return(clientAddress).Times(2)
 		backendMock.EXPECT().LastCommittedProposal().Return(prevBlock, clientAddress)
 
-		core := New(backendMock, config.RoundRobinConfig())
+		core := New(backendMock, config.RoundRobinConfig(), nil)
 
 		overrideDefaultCoreValues(core)
 		core.startRound(context.Background(), currentRound)
@@ -99,10 +99,10 @@ func TestStartRoundVariables(t *testing.T) {
 		// have an impact on the actions performed in the following round (in case of round change) are persisted
 		// through to the subsequent round.
 		backendMock := NewMockBackend(ctrl)
-		backendMock.EXPECT().Address().Return(clientAddress)
+		backendMock.EXPECT().Address().Return(clientAddress).Times(2)
 		backendMock.EXPECT().LastCommittedProposal().Return(prevBlock, clientAddress).MaxTimes(2)
 
-		core := New(backendMock, config.RoundRobinConfig())
+		core := New(backendMock, config.RoundRobinConfig(), nil)
 		overrideDefaultCoreValues(core)
 		core.startRound(context.Background(), currentRound)
 
@@ -166,7 +166,7 @@ func TestStartRound(t *testing.T) {
 		backendMock := NewMockBackend(ctrl)
 		backendMock.EXPECT().Address().Return(clientAddr)
 
-		core := New(backendMock, config.RoundRobinConfig())
+		core := New(backendMock, config.RoundRobinConfig(), nil)
 		// We assume that round 0 can only happen when we move to a new height, therefore, height is
 		// incremented by 1 in start round when round = 0, and the committee set is updated. However, in test case where
 		// round is more than 0, then we need to explicitly update the committee set and height.
@@ -179,10 +179,14 @@ func TestStartRound(t *testing.T) {
 		if currentRound == 0 {
 			// We expect the following extra calls when round = 0
 			backendMock.EXPECT().LastCommittedProposal().Return(prevBlock, lastBlockProposer)
+			backendMock.EXPECT().Address().Return(clientAddr)
 		}
 		backendMock.EXPECT().SetProposedBlockHash(proposalBlock.Hash())
 		backendMock.EXPECT().Sign(proposalMsgRLPNoSig).Return(proposalMsg.Signature, nil)
+		// the block fits in a single part
+		backendMock.EXPECT().Sign(gomock.Any()).Return(proposalMsg.Signature, nil)
 		backendMock.EXPECT().Broadcast(context.Background(), committeeSet.Committee(), proposalMsgRLPWithSig).Return(nil)
+		backendMock.EXPECT().GossipParts(context.Background(), committeeSet.Committee(), gomock.Len(1))
 
 		core.startRound(context.Background(), currentRound)
 	})
@@ -205,7 +209,7 @@ func TestStartRound(t *testing.T) {
 		backendMock := NewMockBackend(ctrl)
 		backendMock.EXPECT().Address().Return(clientAddr)
 
-		core := New(backendMock, config.DefaultConfig())
+		core := New(backendMock, config.DefaultConfig(), nil)
 		core.committee = committeeSet
 		core.height = proposalHeight
 		core.validRound = validR
@@ -213,7 +217,10 @@ func TestStartRound(t *testing.T) {
 
 		backendMock.EXPECT().SetProposedBlockHash(proposalBlock.Hash())
 		backendMock.EXPECT().Sign(proposalMsgRLPNoSig).Return(proposalMsg.Signature, nil)
+		// the block fits in a single part
+		backendMock.EXPECT().Sign(gomock.Any()).Return(proposalMsg.Signature, nil)
 		backendMock.EXPECT().Broadcast(context.Background(), committeeSet.Committee(), proposalMsgRLPWithSig).Return(nil)
+		backendMock.EXPECT().GossipParts(context.Background(), committeeSet.Committee(), gomock.Len(1))
 
 		core.startRound(context.Background(), currentRound)
 	})
@@ -235,7 +242,7 @@ func TestStartRound(t *testing.T) {
 		backendMock := NewMockBackend(ctrl)
 		backendMock.EXPECT().Address().Return(clientAddr)
 
-		core := New(backendMock, config.DefaultConfig())
+		core := New(backendMock, config.DefaultConfig(), nil)
 
 		if currentRound > 0 {
 			core.committee = committeeSet
@@ -243,6 +250,7 @@ func TestStartRound(t *testing.T) {
 
 		if currentRound == 0 {
 			backendMock.EXPECT().LastCommittedProposal().Return(prevBlock, clientAddr)
+			backendMock.EXPECT().Address().Return(clientAddr)
 		}
 
 		core.startRound(context.Background(), currentRound)
@@ -263,7 +271,7 @@ func TestStartRound(t *testing.T) {
 
 		backendMock := NewMockBackend(ctrl)
 		backendMock.EXPECT().Address().Return(clientAddr)
-		c := New(backendMock, config.DefaultConfig())
+		c := New(backendMock, config.DefaultConfig(), nil)
 		c.setCommitteeSet(committeeSet)
 		c.setHeight(currentHeight)
 		c.setRound(currentRound)
@@ -285,7 +293,7 @@ func TestStartRound(t *testing.T) {
 
 		backendMock := NewMockBackend(ctrl)
 		backendMock.EXPECT().Address().Return(clientAddr)
-		c := New(backendMock, config.DefaultConfig())
+		c := New(backendMock, config.DefaultConfig(), nil)
 		c.setCommitteeSet(committeeSet)
 		c.setHeight(currentHeight)
 		c.setRound(currentRound)
@@ -316,7 +324,7 @@ func TestNewProposal(t *testing.T) {
 		backendMock := NewMockBackend(ctrl)
 		backendMock.EXPECT().Address().Return(clientAddr)
 
-		c := New(backendMock, config.DefaultConfig())
+		c := New(backendMock, config.DefaultConfig(), nil)
 		c.setHeight(currentHeight)
 		c.setRound(currentRound)
 		c.setStep(propose)
@@ -353,7 +361,7 @@ func TestNewProposal(t *testing.T) {
 		backendMock := NewMockBackend(ctrl)
 		backendMock.EXPECT().Address().Return(clientAddr)
 
-		c := New(backendMock, config.DefaultConfig())
+		c := New(backendMock, config.DefaultConfig(), nil)
 		// if lockedRround = - 1 then lockedValue = nil
 		c.setHeight(currentHeight)
 		c.setRound(currentRound)
@@ -387,7 +395,7 @@ func TestNewProposal(t *testing.T) {
 		backendMock := NewMockBackend(ctrl)
 		backendMock.EXPECT().Address().Return(clientAddr)
 
-		c := New(backendMock, config.DefaultConfig())
+		c := New(backendMock, config.DefaultConfig(), nil)
 		c.setHeight(currentHeight)
 		c.setRound(currentRound)
 		c.setStep(propose)
@@ -425,7 +433,7 @@ func TestNewProposal(t *testing.T) {
 		backendMock := NewMockBackend(ctrl)
 		backendMock.EXPECT().Address().Return(clientAddr)
 
-		c := New(backendMock, config.DefaultConfig())
+		c := New(backendMock, config.DefaultConfig(), nil)
 		c.setHeight(currentHeight)
 		c.setRound(currentRound)
 		c.setStep(propose)
@@ -476,7 +484,7 @@ func TestOldProposal(t *testing.T) {
 		backendMock := NewMockBackend(ctrl)
 		backendMock.EXPECT().Address().Return(clientAddr)
 
-		c := New(backendMock, config.DefaultConfig())
+		c := New(backendMock, config.DefaultConfig(), nil)
 		c.setHeight(currentHeight)
 		c.setRound(currentRound)
 		c.setStep(propose)
@@ -517,7 +525,7 @@ func TestOldProposal(t *testing.T) {
 		backendMock := NewMockBackend(ctrl)
 		backendMock.EXPECT().Address().Return(clientAddr)
 
-		c := New(backendMock, config.DefaultConfig())
+		c := New(backendMock, config.DefaultConfig(), nil)
 		c.setHeight(currentHeight)
 		c.setRound(currentRound)
 		c.setStep(propose)
@@ -559,7 +567,7 @@ func TestOldProposal(t *testing.T) {
 		backendMock := NewMockBackend(ctrl)
 		backendMock.EXPECT().Address().Return(clientAddr)
 
-		c := New(backendMock, config.DefaultConfig())
+		c := New(backendMock, config.DefaultConfig(), nil)
 		c.setHeight(currentHeight)
 		c.setRound(currentRound)
 		c.setStep(propose)
@@ -670,7 +678,7 @@ func TestOldProposal(t *testing.T) {
 		backendMock := NewMockBackend(ctrl)
 		backendMock.EXPECT().Address().Return(clientAddr)
 
-		c := New(backendMock, config.DefaultConfig())
+		c := New(backendMock, config.DefaultConfig(), nil)
 		c.setCommitteeSet(committeeSet)
 		// construct round state with: old round's quorum-1 prevote for v on valid round.
 		c.messages.getOrCreate(proposalValidRound).AddPrevote(proposal.ProposalBlock.Hash(), Message{Code: msgPrevote, power: c.committeeSet().Quorum() - 1})
@@ -734,7 +742,7 @@ func TestPrevoteTimeout(t *testing.T) {
 		backendMock := NewMockBackend(ctrl)
 		backendMock.EXPECT().Address().Return(clientAddr)
 
-		c := New(backendMock, config.DefaultConfig())
+		c := New(backendMock, config.DefaultConfig(), nil)
 		c.setHeight(currentHeight)
 		c.setRound(currentRound)
 		c.setStep(prevote)
@@ -770,7 +778,7 @@ func TestPrevoteTimeout(t *testing.T) {
 		backendMock := NewMockBackend(ctrl)
 		backendMock.EXPECT().Address().Return(clientAddr)
 
-		c := New(backendMock, config.DefaultConfig())
+		c := New(backendMock, config.DefaultConfig(), nil)
 		c.setHeight(currentHeight)
 		c.setRound(currentRound)
 		c.setStep(prevote)
@@ -810,7 +818,7 @@ func TestPrevoteTimeout(t *testing.T) {
 		backendMock := NewMockBackend(ctrl)
 		backendMock.EXPECT().Address().Return(clientAddr)
 
-		c := New(backendMock, config.DefaultConfig())
+		c := New(backendMock, config.DefaultConfig(), nil)
 		c.setHeight(currentHeight)
 		c.setRound(currentRound)
 		c.setStep(prevote)
@@ -838,13 +846,13 @@ func TestPrevoteTimeout(t *testing.T) {
 		backendMock := NewMockBackend(ctrl)
 		backendMock.EXPECT().Address().Return(clientAddr)
 
-		c := New(backendMock, config.DefaultConfig())
+		c := New(backendMock, config.DefaultConfig(), nil)
 		c.setHeight(currentHeight)
 		c.setRound(currentRound)
 		c.setStep(prevote)
 		c.setCommitteeSet(committeeSet)
 
-		backendMock.EXPECT().Sign(committedSeal).Return(precommitMsg.CommittedSeal, nil)
+		backendMock.EXPECT().SignCommittedSeal(committedSeal, false).Return(precommitMsg.CommittedSeal, nil)
 		backendMock.EXPECT().Sign(precommitMsgRLPNoSig).Return(precommitMsg.Signature, nil)
 		backendMock.EXPECT().Broadcast(context.Background(), committeeSet.Committee(), precommitMsgRLPWithSig).Return(nil)
 
@@ -879,7 +887,7 @@ func TestQuorumPrevote(t *testing.T) {
 		backendMock := NewMockBackend(ctrl)
 		backendMock.EXPECT().Address().Return(clientAddr)
 
-		c := New(backendMock, config.DefaultConfig())
+		c := New(backendMock, config.DefaultConfig(), nil)
 		c.setHeight(currentHeight)
 		c.setRound(currentRound)
 		c.setStep(currentStep)
@@ -890,7 +898,7 @@ func TestQuorumPrevote(t *testing.T) {
 		if currentStep == prevote {
 			committedSeal := PrepareCommittedSeal(proposal.ProposalBlock.Hash(), currentRound, currentHeight)
 
-			backendMock.EXPECT().Sign(committedSeal).Return(precommitMsg.CommittedSeal, nil)
+			backendMock.EXPECT().SignCommittedSeal(committedSeal, false).Return(precommitMsg.CommittedSeal, nil)
 			backendMock.EXPECT().Sign(precommitMsgRLPNoSig).Return(precommitMsg.Signature, nil)
 			backendMock.EXPECT().Broadcast(context.Background(), committeeSet.Committee(), precommitMsgRLPWithSig).Return(nil)
 
@@ -930,7 +938,7 @@ func TestQuorumPrevote(t *testing.T) {
 		backendMock := NewMockBackend(ctrl)
 		backendMock.EXPECT().Address().Return(clientAddr)
 
-		c := New(backendMock, config.DefaultConfig())
+		c := New(backendMock, config.DefaultConfig(), nil)
 		c.setHeight(currentHeight)
 		c.setRound(currentRound)
 		c.setStep(currentStep)
@@ -942,7 +950,7 @@ func TestQuorumPrevote(t *testing.T) {
 		if currentStep == prevote {
 			committedSeal := PrepareCommittedSeal(proposal.ProposalBlock.Hash(), currentRound, currentHeight)
 
-			backendMock.EXPECT().Sign(committedSeal).Return(precommitMsg.CommittedSeal, nil)
+			backendMock.EXPECT().SignCommittedSeal(committedSeal, false).Return(precommitMsg.CommittedSeal, nil)
 			backendMock.EXPECT().Sign(precommitMsgRLPNoSig).Return(precommitMsg.Signature, nil)
 			backendMock.EXPECT().Broadcast(context.Background(), committeeSet.Committee(), precommitMsgRLPWithSig).Return(nil)
 
@@ -1000,14 +1008,14 @@ func TestQuorumPrevoteNil(t *testing.T) {
 	backendMock := NewMockBackend(ctrl)
 	backendMock.EXPECT().Address().Return(clientAddr)
 
-	c := New(backendMock, config.DefaultConfig())
+	c := New(backendMock, config.DefaultConfig(), nil)
 	c.setHeight(currentHeight)
 	c.setRound(currentRound)
 	c.setStep(prevote)
 	c.setCommitteeSet(committeeSet)
 	c.curRoundMessages.AddPrevote(common.Hash{}, Message{Address: members[2].Address, Code: msgPrevote, power: c.committeeSet().Quorum() - 1})
 
-	backendMock.EXPECT().Sign(committedSeal).Return(precommitMsg.CommittedSeal, nil)
+	backendMock.EXPECT().SignCommittedSeal(committedSeal, false).Return(precommitMsg.CommittedSeal, nil)
 	backendMock.EXPECT().Sign(precommitMsgRLPNoSig).Return(precommitMsg.Signature, nil)
 	backendMock.EXPECT().Broadcast(context.Background(), committeeSet.Committee(), precommitMsgRLPWithSig).Return(nil)
 
@@ -1039,7 +1047,7 @@ func TestPrecommitTimeout(t *testing.T) {
 		backendMock := NewMockBackend(ctrl)
 		backendMock.EXPECT().Address().Return(clientAddr)
 
-		c := New(backendMock, config.DefaultConfig())
+		c := New(backendMock, config.DefaultConfig(), nil)
 		c.setHeight(currentHeight)
 		c.setRound(currentRound)
 		//TODO: this should be changed to Step(rand.Intn(3)) to make sure precommit timeout can be started from any step
@@ -1076,7 +1084,7 @@ func TestPrecommitTimeout(t *testing.T) {
 		backendMock := NewMockBackend(ctrl)
 		backendMock.EXPECT().Address().Return(clientAddr)
 
-		c := New(backendMock, config.DefaultConfig())
+		c := New(backendMock, config.DefaultConfig(), nil)
 		c.setHeight(currentHeight)
 		c.setRound(currentRound)
 		//TODO: this should be changed to Step(rand.Intn(3)) to make sure precommit timeout can be started from any step
@@ -1117,7 +1125,7 @@ func TestPrecommitTimeout(t *testing.T) {
 		backendMock := NewMockBackend(ctrl)
 		backendMock.EXPECT().Address().Return(clientAddr)
 
-		c := New(backendMock, config.DefaultConfig())
+		c := New(backendMock, config.DefaultConfig(), nil)
 		c.setHeight(currentHeight)
 		c.setRound(currentRound)
 		//TODO: this should be changed to Step(rand.Intn(3)) to make sure precommit timeout can be started from any step
@@ -1148,7 +1156,7 @@ func TestPrecommitTimeout(t *testing.T) {
 		backendMock := NewMockBackend(ctrl)
 		backendMock.EXPECT().Address().Return(clientAddr)
 
-		c := New(backendMock, config.DefaultConfig())
+		c := New(backendMock, config.DefaultConfig(), nil)
 		c.setHeight(currentHeight)
 		c.setRound(currentRound)
 		//TODO: this should be changed to Step(rand.Intn(3)) to make sure precommit timeout can be started from any step
@@ -1190,7 +1198,7 @@ func TestQuorumPrecommit(t *testing.T) {
 	backendMock := NewMockBackend(ctrl)
 	backendMock.EXPECT().Address().Return(clientAddr)
 
-	c := New(backendMock, config.RoundRobinConfig())
+	c := New(backendMock, config.RoundRobinConfig(), nil)
 	c.setHeight(currentHeight)
 	c.setRound(currentRound)
 	c.setStep(precommit)
@@ -1209,6 +1217,7 @@ func TestQuorumPrecommit(t *testing.T) {
 	newCommitteeSet, err := newRoundRobinSet(committeeSet.Committee(), members[currentRound].Address)
 	assert.NoError(t, err)
 	backendMock.EXPECT().LastCommittedProposal().Return(proposal.ProposalBlock, members[currentRound].Address).MaxTimes(2)
+	backendMock.EXPECT().Address().Return(clientAddr)
 
 	// if the client is the next proposer
 	if newCommitteeSet.GetProposer(0).Address == clientAddr {
@@ -1261,7 +1270,7 @@ func TestFutureRoundChange(t *testing.T) {
 		backendMock := NewMockBackend(ctrl)
 		backendMock.EXPECT().Address().Return(clientAddr)
 
-		c := New(backendMock, config.DefaultConfig())
+		c := New(backendMock, config.DefaultConfig(), nil)
 		c.setHeight(currentHeight)
 		c.setRound(currentRound)
 		c.setStep(currentStep)
@@ -1295,7 +1304,7 @@ func TestFutureRoundChange(t *testing.T) {
 		backendMock := NewMockBackend(ctrl)
 		backendMock.EXPECT().Address().Return(clientAddr)
 
-		c := New(backendMock, config.DefaultConfig())
+		c := New(backendMock, config.DefaultConfig(), nil)
 		c.setHeight(currentHeight)
 		c.setRound(currentRound)
 		c.setStep(currentStep)
@@ -1349,7 +1358,7 @@ func TestHandleMessage(t *testing.T) {
 		backendMock := NewMockBackend(ctrl)
 		backendMock.EXPECT().Address().Return(key1PubAddr)
 
-		core := New(backendMock, config.DefaultConfig())
+		core := New(backendMock, config.DefaultConfig(), nil)
 		core.setCommitteeSet(committeeSet)
 		core.lastHeader = prevBlock.Header()
 		err = core.handleMsg(context.Background(), msg)
@@ -1374,7 +1383,7 @@ func TestHandleMessage(t *testing.T) {
 		backendMock := NewMockBackend(ctrl)
 		backendMock.EXPECT().Address().Return(key1PubAddr)
 
-		core := New(backendMock, config.DefaultConfig())
+		core := New(backendMock, config.DefaultConfig(), nil)
 		core.setCommitteeSet(committeeSet)
 		core.lastHeader = prevBlock.Header()
 		err = core.handleMsg(context.Background(), msg)
@@ -1396,7 +1405,7 @@ func TestHandleMessage(t *testing.T) {
 		backendMock := NewMockBackend(ctrl)
 		backendMock.EXPECT().Address().Return(key1PubAddr)
 
-		core := New(backendMock, config.DefaultConfig())
+		core := New(backendMock, config.DefaultConfig(), nil)
 		core.setCommitteeSet(committeeSet)
 		core.lastHeader = prevBlock.Header()
 		err = core.handleMsg(context.Background(), msg)
@@ -1466,6 +1475,14 @@ func generateBlockProposal(t *testing.T, r int64, h *big.Int, vr int64, src comm
 	// we have to do this because encoding and decoding changes some default values and thus same blocks are no longer equal
 	err = msg.Decode(&p)
 	assert.NoError(t, err)
+	// the block is sent apart, it is reassembled from its parts as it would be once received
+	parts := make(map[uint64]*ProposalPart)
+	for _, part := range proposal.Parts() {
+		parts[part.Index] = part
+	}
+	p.ProposalBlock, err = assembleBlock(&p, parts)
+	assert.NoError(t, err)
+	msg.decodedMsg = &p
 
 	return &msg, p
 }
@@ -0,0 +1,152 @@
+package core
+
+import (
+	"context"
+	"math/big"
+	"sync"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/consensus/tendermint/crypto"
+	"github.com/clearmatics/autonity/core/types"
+	ethcrypto "github.com/clearmatics/autonity/crypto"
+)
+
+// maxPendingVerifications bounds the number of messages waiting for the
+// verifier, the main event loop verifies the messages itself beyond it.
+const maxPendingVerifications = 1024
+
+// verifiedMsg is the outcome of the verification of a consensus message, msg
+// is nil if the payload can't be decoded.
+type verifiedMsg struct {
+	payload []byte
+	msg     *Message
+	err     error
+}
+
+type verifyTask struct {
+	payload []byte
+	hash    common.Hash
+	header  *types.Header
+}
+
+// msgVerifier checks the signatures of the consensus messages received on a
+// bounded pool of workers, so that the main event loop is only handed the
+// messages whose sender has been resolved to a committee member. A message
+// relayed by several peers is verified once while it is in flight.
+type msgVerifier struct {
+	validateFn func(*types.Header, []byte, []byte) (common.Address, error)
+	workers    int
+	tasks      chan verifyTask
+	results    chan verifiedMsg
+
+	pending map[common.Hash]struct{}
+	mu      sync.Mutex
+	wg      sync.WaitGroup
+}
+
+func newMsgVerifier(validateFn func(*types.Header, []byte, []byte) (common.Address, error), workers int) *msgVerifier {
+	if workers < 1 {
+		workers = 1
+	}
+	return &msgVerifier{
+		validateFn: validateFn,
+		workers:    workers,
+		tasks:      make(chan verifyTask, maxPendingVerifications),
+		results:    make(chan verifiedMsg, maxPendingVerifications),
+		pending:    make(map[common.Hash]struct{}),
+	}
+}
+
+// start runs the workers until the context is cancelled.
+func (v *msgVerifier) start(ctx context.Context) {
+	for i := 0; i < v.workers; i++ {
+		v.wg.Add(1)
+		go v.loop(ctx)
+	}
+}
+
+// wait blocks until the workers are stopped.
+func (v *msgVerifier) wait() {
+	v.wg.Wait()
+}
+
+func (v *msgVerifier) loop(ctx context.Context) {
+	defer v.wg.Done()
+	for {
+		select {
+		case task := <-v.tasks:
+			res := v.verify(task.payload, task.header)
+			v.mu.Lock()
+			delete(v.pending, task.hash)
+			v.mu.Unlock()
+
+			select {
+			case v.results <- res:
+			case <-ctx.Done():
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// submit queues the payload for verification against the committee stored in
+// header, the committee of the height following it. Duplicates of a message
+// in flight are dropped. It returns false if the queue is full, it never
+// blocks.
+func (v *msgVerifier) submit(payload []byte, header *types.Header) bool {
+	hash := ethcrypto.Keccak256Hash(payload)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if _, ok := v.pending[hash]; ok {
+		return true
+	}
+	select {
+	case v.tasks <- verifyTask{payload: payload, hash: hash, header: header}:
+		v.pending[hash] = struct{}{}
+		return true
+	default:
+		return false
+	}
+}
+
+// verify decodes the payload and checks the signature of the message against
+// the committee stored in header. The messages of another height are not
+// verified, errFutureHeightMessage is returned for the messages whose committee
+// isn't known yet.
+func (v *msgVerifier) verify(payload []byte, header *types.Header) verifiedMsg {
+	msg := new(Message)
+	if err := msg.FromPayload(payload); err != nil {
+		return verifiedMsg{payload: payload, err: err}
+	}
+	msgHeight, err := msg.Height()
+	if err != nil {
+		return verifiedMsg{payload: payload, msg: msg, err: err}
+	}
+	switch msgHeight.Cmp(new(big.Int).Add(header.Number, common.Big1)) {
+	case -1:
+		return verifiedMsg{payload: payload, msg: msg, err: errOldHeightMessage}
+	case 1:
+		return verifiedMsg{payload: payload, msg: msg, err: errFutureHeightMessage}
+	}
+	_, err = msg.Validate(v.validateFn, header)
+	return verifiedMsg{payload: payload, msg: msg, err: err}
+}
+
+// VerifyMessage checks the signature of a consensus message of the height
+// following header against its committee, for the nodes relaying the messages
+// without running the core. It returns false with a nil error for the messages
+// of another height, which aren't verified.
+func VerifyMessage(payload []byte, header *types.Header) (bool, error) {
+	v := &msgVerifier{validateFn: crypto.CheckValidatorSignature}
+	switch res := v.verify(payload, header); res.err {
+	case errOldHeightMessage, errFutureHeightMessage:
+		return false, nil
+	case nil:
+		return true, nil
+	default:
+		return false, res.err
+	}
+}
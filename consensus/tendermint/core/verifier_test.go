@@ -0,0 +1,252 @@
+package core
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"runtime"
+	"sync/atomic"
+	"testing"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/consensus/tendermint/crypto"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/log"
+	"github.com/golang/mock/gomock"
+)
+
+func signedVote(tb testing.TB, code uint64, height *big.Int, key *ecdsa.PrivateKey, address common.Address) []byte {
+	tb.Helper()
+	vote, err := Encode(&Vote{Round: 0, Height: height, ProposedBlockHash: common.HexToHash("0x1")})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	msg := &Message{Code: code, Msg: vote, Address: address}
+	data, err := msg.PayloadNoSig()
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if msg.Signature, err = sign(data, key); err != nil {
+		tb.Fatal(err)
+	}
+	return msg.Payload()
+}
+
+func TestMsgVerifier(t *testing.T) {
+	members, keys := generateCommittee(4)
+	header := &types.Header{Number: big.NewInt(4), Committee: members}
+	member := members[0]
+	v := newMsgVerifier(crypto.CheckValidatorSignature, 1)
+
+	t.Run("the sender is resolved", func(t *testing.T) {
+		res := v.verify(signedVote(t, msgPrevote, big.NewInt(5), keys[member.Address], member.Address), header)
+		assertNilError(t, res.err)
+		if res.msg.Address != member.Address {
+			t.Fatalf("Expected %v, got %v", member.Address, res.msg.Address)
+		}
+		if res.msg.GetPower() != member.VotingPower.Uint64() {
+			t.Fatalf("Expected %v, got %v", member.VotingPower.Uint64(), res.msg.GetPower())
+		}
+	})
+
+	t.Run("forged sender", func(t *testing.T) {
+		res := v.verify(signedVote(t, msgPrevote, big.NewInt(5), keys[member.Address], members[1].Address), header)
+		assertError(t, ErrUnauthorizedAddress, res.err)
+	})
+
+	t.Run("not a committee member", func(t *testing.T) {
+		key, _ := generatePrivateKey()
+		res := v.verify(signedVote(t, msgPrevote, big.NewInt(5), key, getAddress()), header)
+		assertError(t, crypto.ErrUnauthorizedAddress, res.err)
+	})
+
+	t.Run("invalid payload", func(t *testing.T) {
+		res := v.verify([]byte{0x1}, header)
+		if res.msg != nil || res.err == nil {
+			t.Fatalf("Expected an error, got %v", res.err)
+		}
+	})
+
+	t.Run("other heights are not verified", func(t *testing.T) {
+		validateFn := func(*types.Header, []byte, []byte) (common.Address, error) {
+			t.Fatalf("Expected no verification")
+			return common.Address{}, nil
+		}
+		v := newMsgVerifier(validateFn, 1)
+		res := v.verify(signedVote(t, msgPrevote, big.NewInt(6), keys[member.Address], member.Address), header)
+		assertError(t, errFutureHeightMessage, res.err)
+		res = v.verify(signedVote(t, msgPrevote, big.NewInt(4), keys[member.Address], member.Address), header)
+		assertError(t, errOldHeightMessage, res.err)
+	})
+
+	t.Run("messages are verified for the relays", func(t *testing.T) {
+		ok, err := VerifyMessage(signedVote(t, msgPrevote, big.NewInt(5), keys[member.Address], member.Address), header)
+		if !ok || err != nil {
+			t.Fatalf("Expected the message to be verified, got %v", err)
+		}
+		ok, err = VerifyMessage(signedVote(t, msgPrevote, big.NewInt(6), keys[member.Address], member.Address), header)
+		if ok || err != nil {
+			t.Fatalf("Expected the message not to be verified, got %v", err)
+		}
+		_, err = VerifyMessage(signedVote(t, msgPrevote, big.NewInt(5), keys[member.Address], members[1].Address), header)
+		assertError(t, ErrUnauthorizedAddress, err)
+	})
+
+	t.Run("duplicates in flight are verified once", func(t *testing.T) {
+		var calls int32
+		validateFn := func(header *types.Header, data []byte, sig []byte) (common.Address, error) {
+			atomic.AddInt32(&calls, 1)
+			return crypto.CheckValidatorSignature(header, data, sig)
+		}
+		v := newMsgVerifier(validateFn, 2)
+		payload := signedVote(t, msgPrecommit, big.NewInt(5), keys[member.Address], member.Address)
+		for i := 0; i < 3; i++ {
+			if !v.submit(payload, header) {
+				t.Fatalf("Expected the message to be queued")
+			}
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		v.start(ctx)
+		res := <-v.results
+		cancel()
+		v.wait()
+
+		assertNilError(t, res.err)
+		if len(v.results) != 0 || atomic.LoadInt32(&calls) != 1 {
+			t.Fatalf("Expected 1 verification, got %v", atomic.LoadInt32(&calls))
+		}
+	})
+
+	t.Run("a full queue is reported", func(t *testing.T) {
+		v := newMsgVerifier(crypto.CheckValidatorSignature, 1)
+		for i := 0; i < maxPendingVerifications; i++ {
+			if !v.submit(signedVote(t, msgPrevote, big.NewInt(int64(i)+5), keys[member.Address], member.Address), header) {
+				t.Fatalf("Expected the message to be queued")
+			}
+		}
+		if v.submit(signedVote(t, msgPrevote, big.NewInt(5), keys[members[1].Address], members[1].Address), header) {
+			t.Fatalf("Expected a full queue")
+		}
+	})
+}
+
+func TestHandleVerifiedMsg(t *testing.T) {
+	members, keys := generateCommittee(4)
+	member := members[0]
+	newCore := func() *core {
+		return &core{
+			logger:           log.New("backend", "test", "id", 0),
+			height:           big.NewInt(5),
+			lastHeader:       &types.Header{Number: big.NewInt(4), Committee: members},
+			backlogUnchecked: make(map[uint64][]*Message),
+			verifier:         newMsgVerifier(crypto.CheckValidatorSignature, 1),
+		}
+	}
+	decode := func(payload []byte) *Message {
+		msg := new(Message)
+		if err := msg.FromPayload(payload); err != nil {
+			t.Fatal(err)
+		}
+		return msg
+	}
+
+	t.Run("messages ahead of the committee are stored", func(t *testing.T) {
+		c := newCore()
+		msg := decode(signedVote(t, msgPrevote, big.NewInt(6), keys[member.Address], member.Address))
+		c.handleVerifiedMsg(context.Background(), verifiedMsg{msg: msg, err: errFutureHeightMessage})
+		if len(c.backlogUnchecked[6]) != 1 {
+			t.Fatalf("Expected the message in the unchecked backlog")
+		}
+	})
+
+	t.Run("messages of the current height are verified against its committee", func(t *testing.T) {
+		c := newCore()
+		payload := signedVote(t, msgPrevote, big.NewInt(5), keys[member.Address], member.Address)
+		// the message was submitted before the committee of height 5 was known
+		c.handleVerifiedMsg(context.Background(), verifiedMsg{msg: decode(payload), err: errFutureHeightMessage})
+		if len(c.verifier.tasks) != 1 {
+			t.Fatalf("Expected the message to be verified again")
+		}
+		task := <-c.verifier.tasks
+		if task.header != c.lastHeader {
+			t.Fatalf("Expected %v, got %v", c.lastHeader.Number, task.header.Number)
+		}
+	})
+
+	t.Run("invalid and stale messages are reported", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		backend := NewMockBackend(ctrl)
+		c := newCore()
+		c.backend = backend
+
+		forged := signedVote(t, msgPrevote, big.NewInt(5), keys[member.Address], members[1].Address)
+		backend.EXPECT().ReportInvalidMessage(forged, ErrUnauthorizedAddress)
+		c.handleVerifiedMsg(context.Background(), c.verifier.verify(forged, c.lastHeader))
+
+		backend.EXPECT().ReportInvalidMessage([]byte{0x1}, gomock.Any())
+		c.handleVerifiedMsg(context.Background(), c.verifier.verify([]byte{0x1}, c.lastHeader))
+
+		stale := signedVote(t, msgPrevote, big.NewInt(4), keys[member.Address], member.Address)
+		backend.EXPECT().ReportStaleMessage(stale)
+		c.handleVerifiedMsg(context.Background(), c.verifier.verify(stale, c.lastHeader))
+	})
+
+	t.Run("messages of old heights are dropped", func(t *testing.T) {
+		c := newCore()
+		msg := decode(signedVote(t, msgPrevote, big.NewInt(4), keys[member.Address], member.Address))
+		c.handleVerifiedMsg(context.Background(), verifiedMsg{msg: msg})
+		if len(c.backlogUnchecked) != 0 || len(c.verifier.tasks) != 0 {
+			t.Fatalf("Expected the message to be dropped")
+		}
+	})
+}
+
+func benchmarkVotes(b *testing.B, n int) (*types.Header, [][]byte) {
+	members, keys := generateCommittee(n)
+	header := &types.Header{Number: big.NewInt(1), Committee: members}
+	payloads := make([][]byte, n)
+	for i, member := range members {
+		payloads[i] = signedVote(b, msgPrevote, big.NewInt(2), keys[member.Address], member.Address)
+	}
+	return header, payloads
+}
+
+// BenchmarkVerifySequential verifies a round of prevotes on the main event
+// loop, as done without the verifier.
+func BenchmarkVerifySequential(b *testing.B) {
+	header, payloads := benchmarkVotes(b, 100)
+	v := newMsgVerifier(crypto.CheckValidatorSignature, 1)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, payload := range payloads {
+			if res := v.verify(payload, header); res.err != nil {
+				b.Fatal(res.err)
+			}
+		}
+	}
+}
+
+// BenchmarkVerifyParallel verifies a round of prevotes on the workers of the
+// verifier.
+func BenchmarkVerifyParallel(b *testing.B) {
+	header, payloads := benchmarkVotes(b, 100)
+	v := newMsgVerifier(crypto.CheckValidatorSignature, runtime.NumCPU())
+	ctx, cancel := context.WithCancel(context.Background())
+	v.start(ctx)
+	defer func() {
+		cancel()
+		v.wait()
+	}()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, payload := range payloads {
+			v.submit(payload, header)
+		}
+		for range payloads {
+			if res := <-v.results; res.err != nil {
+				b.Fatal(res.err)
+			}
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/clearmatics/autonity/common"
+)
+
+// errInvalidSignedData is returned if the data to sign can't be decoded.
+var errInvalidSignedData = errors.New("invalid signed data")
+
+// View is the position in the consensus at which a committee member signs a
+// value. External signers keep the last signed view so that they never sign
+// conflicting values, even if the node asking for signatures is compromised.
+type View struct {
+	Height *big.Int    `json:"height"`
+	Round  int64       `json:"round"`
+	Step   Step        `json:"step"`
+	Value  common.Hash `json:"value"`
+}
+
+// Cmp compares the height, round and step of the views.
+func (v View) Cmp(y View) int {
+	if c := v.Height.Cmp(y.Height); c != 0 {
+		return c
+	}
+	if v.Round != y.Round {
+		if v.Round < y.Round {
+			return -1
+		}
+		return 1
+	}
+	return v.Step.Cmp(y.Step)
+}
+
+// MessageView returns the sender and the view of a consensus message, given
+// its payload without signature.
+func MessageView(payload []byte) (common.Address, View, error) {
+	msg := new(Message)
+	if err := msg.FromPayload(payload); err != nil {
+		return common.Address{}, View{}, err
+	}
+	switch m := msg.decodedMsg.(type) {
+	case *Proposal:
+		return msg.Address, View{Height: m.Height, Round: m.Round, Step: propose, Value: m.BlockHash}, nil
+	case *ProposalPart:
+		// the parts are signed along with the proposal of their block
+		return msg.Address, View{Height: m.Height, Round: m.Round, Step: propose, Value: m.BlockHash}, nil
+	case *Vote:
+		step := prevote
+		if msg.Code == msgPrecommit {
+			step = precommit
+		}
+		return msg.Address, View{Height: m.Height, Round: m.Round, Step: step, Value: m.ProposedBlockHash}, nil
+	default:
+		return common.Address{}, View{}, errInvalidSignedData
+	}
+}
+
+// CommittedSealView returns the view of a committed seal, see
+// PrepareCommittedSeal for its encoding.
+func CommittedSealView(seal []byte) (View, error) {
+	if len(seal) < 8+common.HashLength {
+		return View{}, errInvalidSignedData
+	}
+	return View{
+		Height: new(big.Int).SetBytes(seal[8 : len(seal)-common.HashLength]),
+		Round:  int64(binary.LittleEndian.Uint64(seal[:8])),
+		Step:   precommit,
+		Value:  common.BytesToHash(seal[len(seal)-common.HashLength:]),
+	}, nil
+}
+
+// BeaconHeight returns the height of a beacon, given the data signed by its
+// proposer, see PrepareBeacon for its encoding.
+func BeaconHeight(data []byte) (*big.Int, error) {
+	if len(data) != len(beaconPrefix)+2*common.HashLength || !bytes.HasPrefix(data, beaconPrefix) {
+		return nil, errInvalidSignedData
+	}
+	return new(big.Int).SetBytes(data[len(beaconPrefix)+common.HashLength:]), nil
+}
@@ -0,0 +1,293 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"sync"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/consensus/tendermint/events"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/ethdb"
+	"github.com/clearmatics/autonity/rlp"
+)
+
+// walKey is the database key under which the consensus write-ahead log is stored.
+var walKey = []byte("tendermint-wal")
+
+// walState is the consensus state which needs to survive a restart of the node
+// in order to keep it from equivocating: the lock and valid values of the
+// current height as well as every message we have signed for it.
+type walState struct {
+	Height      *big.Int
+	Round       int64
+	LockedRound int64
+	LockedValue *types.Block
+	ValidRound  int64
+	ValidValue  *types.Block
+	Messages    [][]byte // rlp encoded signed messages
+}
+
+// RLP encoding doesn't support negative numbers, we rely on the same trick used for the proposal's validRound.
+func (s *walState) EncodeRLP(w io.Writer) error {
+	return rlp.Encode(w, []interface{}{
+		s.Height,
+		uint64(s.Round),
+		encodeWALRound(s.LockedRound),
+		s.LockedRound == -1,
+		encodeWALBlock(s.LockedValue),
+		encodeWALRound(s.ValidRound),
+		s.ValidRound == -1,
+		encodeWALBlock(s.ValidValue),
+		s.Messages,
+	})
+}
+
+// DecodeRLP implements rlp.Decoder, and load the wal fields from a RLP stream.
+func (s *walState) DecodeRLP(stream *rlp.Stream) error {
+	var state struct {
+		Height           *big.Int
+		Round            uint64
+		LockedRound      uint64
+		IsLockedRoundNil bool
+		LockedValue      []byte
+		ValidRound       uint64
+		IsValidRoundNil  bool
+		ValidValue       []byte
+		Messages         [][]byte
+	}
+	if err := stream.Decode(&state); err != nil {
+		return err
+	}
+	if state.Round > MaxRound || state.LockedRound > MaxRound || state.ValidRound > MaxRound {
+		return errors.New("bad wal state with invalid rounds")
+	}
+
+	lockedValue, err := decodeWALBlock(state.LockedValue)
+	if err != nil {
+		return err
+	}
+	validValue, err := decodeWALBlock(state.ValidValue)
+	if err != nil {
+		return err
+	}
+
+	s.Height = state.Height
+	s.Round = int64(state.Round)
+	s.LockedRound = decodeWALRound(state.LockedRound, state.IsLockedRoundNil)
+	s.LockedValue = lockedValue
+	s.ValidRound = decodeWALRound(state.ValidRound, state.IsValidRoundNil)
+	s.ValidValue = validValue
+	s.Messages = state.Messages
+	return nil
+}
+
+func encodeWALRound(round int64) uint64 {
+	if round == -1 {
+		return 0
+	}
+	return uint64(round)
+}
+
+func decodeWALRound(round uint64, isNil bool) int64 {
+	if isNil {
+		return -1
+	}
+	return int64(round)
+}
+
+func encodeWALBlock(b *types.Block) []byte {
+	if b == nil {
+		return []byte{}
+	}
+	// A block we have accepted as lock or valid value can always be encoded.
+	enc, _ := rlp.EncodeToBytes(b)
+	return enc
+}
+
+func decodeWALBlock(enc []byte) (*types.Block, error) {
+	if len(enc) == 0 {
+		return nil, nil
+	}
+	b := new(types.Block)
+	if err := rlp.DecodeBytes(enc, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// wal is a write-ahead log persisting the consensus state of the current height.
+// Every message is recorded before being broadcast so that a restarted node
+// resumes where it stopped and never signs a message conflicting with one it
+// has already sent.
+type wal struct {
+	db    ethdb.KeyValueStore
+	state *walState
+	mu    sync.Mutex
+}
+
+// newWAL opens the write-ahead log stored in db. A nil database disables the log.
+func newWAL(db ethdb.KeyValueStore) *wal {
+	if db == nil {
+		return nil
+	}
+	w := &wal{db: db}
+	if enc, err := db.Get(walKey); err == nil && len(enc) > 0 {
+		state := new(walState)
+		if err := rlp.DecodeBytes(enc, state); err == nil {
+			w.state = state
+		}
+	}
+	return w
+}
+
+// load returns the persisted state for the given height, or nil if the log
+// doesn't hold anything for it.
+func (w *wal) load(height *big.Int) *walState {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.state == nil || w.state.Height == nil || w.state.Height.Cmp(height) != 0 {
+		return nil
+	}
+	return w.state
+}
+
+// signedMessage returns the message with the given code that we have already
+// signed for the height and round, or nil if there is none.
+func (w *wal) signedMessage(height *big.Int, round int64, code uint64) *Message {
+	if msgs := w.signedMessages(height, round, code); len(msgs) > 0 {
+		return msgs[0]
+	}
+	return nil
+}
+
+// signedMessages returns the messages with the given code that we have already
+// signed for the height and round, there are several parts of a proposal.
+func (w *wal) signedMessages(height *big.Int, round int64, code uint64) []*Message {
+	state := w.load(height)
+	if state == nil {
+		return nil
+	}
+	var msgs []*Message
+	for _, payload := range state.Messages {
+		msg := new(Message)
+		if err := msg.FromPayload(payload); err != nil {
+			continue
+		}
+		msgRound, _ := msg.Round()
+		if msg.Code == code && msgRound == round {
+			msgs = append(msgs, msg)
+		}
+	}
+	return msgs
+}
+
+// write persists the lock state of the given height, and the given messages
+// which are not nil. Messages recorded for previous heights are discarded.
+func (w *wal) write(height *big.Int, round int64, lockedRound int64, lockedValue *types.Block,
+	validRound int64, validValue *types.Block, msgs ...*Message) error {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	state := &walState{
+		Height:      height,
+		Round:       round,
+		LockedRound: lockedRound,
+		LockedValue: lockedValue,
+		ValidRound:  validRound,
+		ValidValue:  validValue,
+	}
+	if w.state != nil && w.state.Height != nil && w.state.Height.Cmp(height) == 0 {
+		state.Messages = w.state.Messages
+		if w.state.Round > state.Round {
+			state.Round = w.state.Round
+		}
+	}
+	state.Messages = state.Messages[:len(state.Messages):len(state.Messages)]
+	for _, msg := range msgs {
+		if msg != nil {
+			state.Messages = append(state.Messages, msg.Payload())
+		}
+	}
+
+	enc, err := rlp.EncodeToBytes(state)
+	if err != nil {
+		return err
+	}
+	if err := w.db.Put(walKey, enc); err != nil {
+		return err
+	}
+	w.state = state
+	return nil
+}
+
+// persistState records the lock state of the current height in the WAL, along with msgs if not nil.
+func (c *core) persistState(msgs ...*Message) error {
+	return c.wal.write(c.Height(), c.Round(), c.lockedRound, c.lockedValue, c.validRound, c.validValue, msgs...)
+}
+
+// resendSignedMessage broadcasts again the message of the given code we have
+// already signed for the current round, if any, and reports whether it did so.
+// It is used in place of signing a new message which could conflict with the first one.
+func (c *core) resendSignedMessage(ctx context.Context, code uint64) bool {
+	msg := c.wal.signedMessage(c.Height(), c.Round(), code)
+	if msg == nil {
+		return false
+	}
+	c.logger.Warn("Message already signed in this round, sending it again", "msg", msg)
+	if err := c.backend.Broadcast(ctx, c.committeeSet().Committee(), msg.Payload()); err != nil {
+		c.logger.Error("Failed to broadcast message", "msg", msg, "err", err)
+	}
+	if code == msgProposal {
+		// the parts of the block were persisted along with the proposal
+		parts := c.wal.signedMessages(c.Height(), c.Round(), msgProposalPart)
+		payloads := make([][]byte, len(parts))
+		for i, part := range parts {
+			payloads[i] = part.Payload()
+		}
+		c.backend.GossipParts(ctx, c.committeeSet().Committee(), payloads)
+	}
+	return true
+}
+
+// resume starts the height following the last committed block. If the WAL holds
+// the state of that height, we were restarted mid-height: the round and step
+// we were at are restored and our own messages are handled again so that they
+// are accounted for and re-gossiped.
+func (c *core) resume(ctx context.Context) {
+	lastBlockMined, _ := c.backend.LastCommittedProposal()
+	state := c.wal.load(new(big.Int).Add(lastBlockMined.Number(), common.Big1))
+	if state == nil {
+		c.startRound(ctx, 0)
+		return
+	}
+
+	c.logger.Info("Resuming consensus from WAL", "height", state.Height, "round", state.Round, "lockedRound", state.LockedRound)
+	if state.Round > 0 {
+		// the height state needs to be set before jumping to a later round
+		c.setInitialState(0)
+	}
+	c.startRound(ctx, state.Round)
+
+	if c.wal.signedMessage(c.Height(), c.Round(), msgPrecommit) != nil {
+		c.sentPrevote, c.sentPrecommit = true, true
+		c.setStep(precommit)
+	} else if c.wal.signedMessage(c.Height(), c.Round(), msgPrevote) != nil {
+		c.sentPrevote = true
+		c.setStep(prevote)
+	}
+
+	for _, payload := range state.Messages {
+		go c.sendEvent(events.MessageEvent{
+			Payload: payload,
+		})
+	}
+}
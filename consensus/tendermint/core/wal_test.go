@@ -0,0 +1,199 @@
+package core
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/ethdb/memorydb"
+	"github.com/clearmatics/autonity/log"
+	"github.com/clearmatics/autonity/rlp"
+	"github.com/golang/mock/gomock"
+)
+
+func TestWALStateRLP(t *testing.T) {
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(3)})
+
+	t.Run("nil lock state", func(t *testing.T) {
+		state := &walState{
+			Height:      big.NewInt(3),
+			Round:       2,
+			LockedRound: -1,
+			ValidRound:  -1,
+			Messages:    [][]byte{{0x1}, {0x2}},
+		}
+		enc, err := rlp.EncodeToBytes(state)
+		if err != nil {
+			t.Fatalf("Expected nil, got %v", err)
+		}
+		decoded := new(walState)
+		if err := rlp.DecodeBytes(enc, decoded); err != nil {
+			t.Fatalf("Expected nil, got %v", err)
+		}
+		if !reflect.DeepEqual(state, decoded) {
+			t.Fatalf("Expected %v, got %v", state, decoded)
+		}
+	})
+
+	t.Run("locked and valid values set", func(t *testing.T) {
+		state := &walState{
+			Height:      big.NewInt(3),
+			Round:       1,
+			LockedRound: 0,
+			LockedValue: block,
+			ValidRound:  1,
+			ValidValue:  block,
+		}
+		enc, err := rlp.EncodeToBytes(state)
+		if err != nil {
+			t.Fatalf("Expected nil, got %v", err)
+		}
+		decoded := new(walState)
+		if err := rlp.DecodeBytes(enc, decoded); err != nil {
+			t.Fatalf("Expected nil, got %v", err)
+		}
+		if decoded.LockedRound != 0 || decoded.ValidRound != 1 || decoded.Round != 1 {
+			t.Fatalf("bad rounds decoded %v", decoded)
+		}
+		if decoded.LockedValue.Hash() != block.Hash() || decoded.ValidValue.Hash() != block.Hash() {
+			t.Fatalf("bad values decoded %v", decoded)
+		}
+	})
+}
+
+func TestWALWrite(t *testing.T) {
+	db := memorydb.New()
+	w := newWAL(db)
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(3)})
+	committeeSet := newTestCommitteeSet(4)
+	member := committeeSet.Committee()[0]
+
+	prevote := createPrevote(t, block.Hash(), 1, big.NewInt(3), member)
+	if err := w.write(big.NewInt(3), 1, 1, block, 1, block, prevote); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+
+	// reopening the log simulates a restart of the node
+	reopened := newWAL(db)
+	state := reopened.load(big.NewInt(3))
+	if state == nil {
+		t.Fatalf("wal state should have been persisted")
+	}
+	if state.LockedRound != 1 || state.LockedValue.Hash() != block.Hash() {
+		t.Fatalf("lock state not persisted")
+	}
+	if msg := reopened.signedMessage(big.NewInt(3), 1, msgPrevote); msg == nil || !reflect.DeepEqual(msg.Payload(), prevote.Payload()) {
+		t.Fatalf("signed prevote not persisted")
+	}
+	if msg := reopened.signedMessage(big.NewInt(3), 1, msgPrecommit); msg != nil {
+		t.Fatalf("no precommit was signed")
+	}
+	if reopened.load(big.NewInt(4)) != nil {
+		t.Fatalf("nothing persisted for next height")
+	}
+
+	// moving to the next height discards previous messages
+	if err := reopened.write(big.NewInt(4), 0, -1, nil, -1, nil, nil); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if reopened.load(big.NewInt(3)) != nil || len(reopened.load(big.NewInt(4)).Messages) != 0 {
+		t.Fatalf("previous height should have been discarded")
+	}
+}
+
+// A node killed after sending its prevote and before sending its precommit must
+// never sign a different prevote for the same round once it is restarted.
+func TestWALNoConflictingVoteAfterRestart(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := memorydb.New()
+	committeeSet, keys := newTestCommitteeSetWithKeys(4)
+	member := committeeSet.Committee()[0]
+	logger := log.New("backend", "test", "id", 0)
+	height := big.NewInt(2)
+
+	proposal := NewProposal(1, height, -1, types.NewBlockWithHeader(&types.Header{Number: height}))
+	messages := newMessagesMap()
+	curRoundMessages := messages.getOrCreate(1)
+	curRoundMessages.SetProposal(proposal, nil, true)
+
+	signFn := func(data []byte) ([]byte, error) {
+		return sign(data, keys[member.Address])
+	}
+
+	var sentPayload []byte
+	backendMock := NewMockBackend(ctrl)
+	backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(signFn).Times(1)
+	backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Do(
+		func(ctx context.Context, c types.Committee, payload []byte) {
+			sentPayload = payload
+		})
+
+	c := &core{
+		backend:          backendMock,
+		address:          member.Address,
+		logger:           logger,
+		height:           height,
+		committee:        committeeSet,
+		messages:         messages,
+		round:            1,
+		step:             propose,
+		curRoundMessages: curRoundMessages,
+		lockedRound:      -1,
+		validRound:       -1,
+		proposeTimeout:   newTimeout(propose, logger),
+		wal:              newWAL(db),
+	}
+	c.sendPrevote(context.Background(), false)
+
+	// the node is killed here and restarted without any knowledge of the proposal
+	restartedBackend := NewMockBackend(ctrl)
+	restartedBackend.EXPECT().Sign(gomock.Any()).Times(0)
+	restartedBackend.EXPECT().Broadcast(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Do(
+		func(ctx context.Context, c types.Committee, payload []byte) {
+			if !reflect.DeepEqual(payload, sentPayload) {
+				t.Fatalf("a conflicting prevote has been sent")
+			}
+		})
+
+	restartedMessages := newMessagesMap()
+	restarted := &core{
+		backend:          restartedBackend,
+		address:          member.Address,
+		logger:           logger,
+		height:           height,
+		committee:        committeeSet,
+		messages:         restartedMessages,
+		round:            1,
+		step:             propose,
+		curRoundMessages: restartedMessages.getOrCreate(1),
+		lockedRound:      -1,
+		validRound:       -1,
+		proposeTimeout:   newTimeout(propose, logger),
+		wal:              newWAL(db),
+	}
+
+	// the propose timeout expiring would make the node prevote nil
+	restarted.handleTimeoutPropose(context.Background(), TimeoutEvent{
+		roundWhenCalled:  1,
+		heightWhenCalled: height,
+		step:             msgProposal,
+	})
+
+	if restarted.step != prevote || !restarted.sentPrevote {
+		t.Fatalf("restarted node should have moved to prevote step")
+	}
+
+	msg := restarted.wal.signedMessage(height, 1, msgPrevote)
+	var vote Vote
+	if err := msg.Decode(&vote); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if vote.ProposedBlockHash == (common.Hash{}) {
+		t.Fatalf("original prevote has been overwritten")
+	}
+}
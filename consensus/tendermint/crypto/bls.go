@@ -0,0 +1,276 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/crypto"
+	"github.com/clearmatics/autonity/crypto/bls12381"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+const (
+	// BLSPublicKeyLength is the length of an uncompressed G1 public key.
+	BLSPublicKeyLength = 96
+	// BLSSignatureLength is the length of an uncompressed G2 signature.
+	BLSSignatureLength = 192
+	// ConsensusKeyLength is the length of the consensus key registered by
+	// validators: the BLS public key followed by its proof of possession.
+	ConsensusKeyLength = BLSPublicKeyLength + BLSSignatureLength
+)
+
+var (
+	// ErrInvalidConsensusKey is returned if a consensus key is malformed or its
+	// proof of possession doesn't verify.
+	ErrInvalidConsensusKey = errors.New("invalid consensus key")
+	// ErrInvalidBLSSignature is returned if a BLS signature is malformed.
+	ErrInvalidBLSSignature = errors.New("invalid bls signature")
+	// ErrInvalidAggregatedSeal is returned if an aggregated seal is malformed or
+	// doesn't verify against the committee.
+	ErrInvalidAggregatedSeal = errors.New("invalid aggregated seal")
+
+	// domain separation tags of the hash to curve function, so that a
+	// proof of possession can't be used as a committed seal.
+	sealDST = []byte("AUTONITY-BLS-SEAL-")
+	popDST  = []byte("AUTONITY-BLS-POP-")
+	keyDST  = []byte("AUTONITY-BLS-KEYGEN-")
+
+	// fieldModulus is the modulus of the base field of BLS12-381.
+	fieldModulus, _ = new(big.Int).SetString("1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaaab", 16)
+
+	// consensus keys are checked every time a committee is loaded, the proof
+	// of possession verification is cached as it requires a pairing.
+	verifiedKeys, _ = lru.NewARC(1024)
+)
+
+// BLSKey is the secret key used to sign committed seals which can be aggregated.
+type BLSKey struct {
+	secret *big.Int
+	public []byte
+}
+
+// DeriveBLSKey derives the BLS key of a validator from its node key, so that
+// no other secret has to be managed by the operator.
+func DeriveBLSKey(key *ecdsa.PrivateKey) (*BLSKey, error) {
+	d := common.LeftPadBytes(key.D.Bytes(), 32)
+	// 64 bytes are reduced so that the bias of the modular reduction is negligible.
+	wide := append(crypto.Keccak256(keyDST, []byte{0}, d), crypto.Keccak256(keyDST, []byte{1}, d)...)
+	g1 := bls12381.NewG1()
+	secret := new(big.Int).Mod(new(big.Int).SetBytes(wide), g1.Q())
+	if secret.Sign() == 0 {
+		return nil, ErrInvalidConsensusKey
+	}
+	public := g1.ToBytes(g1.MulScalar(g1.New(), g1.One(), secret))
+	return &BLSKey{secret: secret, public: public}, nil
+}
+
+// PublicKey returns the uncompressed public key.
+func (k *BLSKey) PublicKey() []byte {
+	return common.CopyBytes(k.public)
+}
+
+// ConsensusKey returns the public key followed by its proof of possession, as
+// registered in the autonity contract.
+func (k *BLSKey) ConsensusKey() []byte {
+	return append(k.PublicKey(), k.sign(popDST, k.public)...)
+}
+
+// Sign returns the BLS signature of a committed seal.
+func (k *BLSKey) Sign(seal []byte) []byte {
+	return k.sign(sealDST, seal)
+}
+
+func (k *BLSKey) sign(dst, msg []byte) []byte {
+	g2 := bls12381.NewG2()
+	h, err := hashToG2(dst, msg)
+	if err != nil {
+		// the hash is always reduced to a valid field element.
+		panic(err)
+	}
+	return g2.ToBytes(g2.MulScalar(g2.New(), h, k.secret))
+}
+
+// VerifyConsensusKey checks that the consensus key holds a valid public key
+// and the proof that it has been generated by its owner, which prevents
+// rogue key attacks against aggregated signatures.
+func VerifyConsensusKey(key []byte) error {
+	if len(key) != ConsensusKeyLength {
+		return ErrInvalidConsensusKey
+	}
+	hash := crypto.Keccak256Hash(key)
+	if valid, ok := verifiedKeys.Get(hash); ok {
+		if !valid.(bool) {
+			return ErrInvalidConsensusKey
+		}
+		return nil
+	}
+	public := key[:BLSPublicKeyLength]
+	err := verify(popDST, public, public, key[BLSPublicKeyLength:])
+	verifiedKeys.Add(hash, err == nil)
+	if err != nil {
+		return ErrInvalidConsensusKey
+	}
+	return nil
+}
+
+// VerifySeal checks the BLS signature of a committed seal against the
+// consensus key of the signer.
+func VerifySeal(consensusKey, seal, sig []byte) error {
+	if err := VerifyConsensusKey(consensusKey); err != nil {
+		return err
+	}
+	return verify(sealDST, consensusKey[:BLSPublicKeyLength], seal, sig)
+}
+
+// SealsAggregated reports whether committed seals for the heights decided by
+// the committee are aggregated, which requires that every member has
+// registered a valid consensus key. Otherwise every member provides its own
+// secp256k1 seal.
+func SealsAggregated(committee types.Committee) bool {
+	if len(committee) == 0 {
+		return false
+	}
+	for _, member := range committee {
+		if VerifyConsensusKey(member.ConsensusKey) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// AggregateSeals aggregates the BLS committed seals of committee members into
+// a single signature and the bitmap of the members' indexes in the committee.
+func AggregateSeals(committee types.Committee, seals map[common.Address][]byte) (types.AggregatedSeal, error) {
+	g2 := bls12381.NewG2()
+	aggregated := g2.Zero()
+	signers := make([]byte, bitmapLength(len(committee)))
+	for i, member := range committee {
+		sig, ok := seals[member.Address]
+		if !ok {
+			continue
+		}
+		p, err := decodeSignature(sig)
+		if err != nil {
+			return types.AggregatedSeal{}, err
+		}
+		g2.Add(aggregated, aggregated, p)
+		signers[i/8] |= 1 << uint(i%8)
+	}
+	if len(seals) == 0 || g2.IsZero(aggregated) {
+		return types.AggregatedSeal{}, ErrInvalidAggregatedSeal
+	}
+	return types.AggregatedSeal{Signature: g2.ToBytes(aggregated), Signers: signers}, nil
+}
+
+// VerifyAggregatedSeal checks the aggregated seal against the aggregated public
+// keys of the committee members in its bitmap with a single pairing check, and
+// returns those members.
+func VerifyAggregatedSeal(committee types.Committee, seal []byte, aggregated types.AggregatedSeal) (types.Committee, error) {
+	if len(aggregated.Signers) != bitmapLength(len(committee)) {
+		return nil, ErrInvalidAggregatedSeal
+	}
+	g1 := bls12381.NewG1()
+	public := g1.Zero()
+	var signers types.Committee
+	for i := 0; i < len(aggregated.Signers)*8; i++ {
+		if aggregated.Signers[i/8]&(1<<uint(i%8)) == 0 {
+			continue
+		}
+		if i >= len(committee) {
+			return nil, ErrInvalidAggregatedSeal
+		}
+		member := committee[i]
+		if err := VerifyConsensusKey(member.ConsensusKey); err != nil {
+			return nil, ErrInvalidAggregatedSeal
+		}
+		p, err := g1.FromBytes(member.ConsensusKey[:BLSPublicKeyLength])
+		if err != nil {
+			return nil, ErrInvalidAggregatedSeal
+		}
+		g1.Add(public, public, p)
+		signers = append(signers, member)
+	}
+	if len(signers) == 0 {
+		return nil, ErrInvalidAggregatedSeal
+	}
+	if err := verifyPoint(sealDST, public, seal, aggregated.Signature); err != nil {
+		return nil, ErrInvalidAggregatedSeal
+	}
+	return signers, nil
+}
+
+func verify(dst, public, msg, sig []byte) error {
+	g1 := bls12381.NewG1()
+	p, err := g1.FromBytes(public)
+	if err != nil || g1.IsZero(p) || !g1.InCorrectSubgroup(p) {
+		return ErrInvalidConsensusKey
+	}
+	return verifyPoint(dst, p, msg, sig)
+}
+
+// verifyPoint checks e(public, H(msg)) == e(g1, sig).
+func verifyPoint(dst []byte, public *bls12381.PointG1, msg, sig []byte) error {
+	s, err := decodeSignature(sig)
+	if err != nil {
+		return err
+	}
+	h, err := hashToG2(dst, msg)
+	if err != nil {
+		return err
+	}
+	g1 := bls12381.NewG1()
+	engine := bls12381.NewPairingEngine()
+	engine.AddPair(public, h)
+	engine.AddPairInv(g1.One(), s)
+	if !engine.Check() {
+		return ErrInvalidBLSSignature
+	}
+	return nil
+}
+
+func decodeSignature(sig []byte) (*bls12381.PointG2, error) {
+	if len(sig) != BLSSignatureLength {
+		return nil, ErrInvalidBLSSignature
+	}
+	g2 := bls12381.NewG2()
+	p, err := g2.FromBytes(sig)
+	if err != nil || g2.IsZero(p) || !g2.InCorrectSubgroup(p) {
+		return nil, ErrInvalidBLSSignature
+	}
+	return p, nil
+}
+
+// hashToG2 maps the message to two points of G2 which are added together,
+// each point being derived from an element of the quadratic extension field
+// obtained by hashing the message.
+func hashToG2(dst, msg []byte) (*bls12381.PointG2, error) {
+	g2 := bls12381.NewG2()
+	h := g2.Zero()
+	for i := byte(0); i < 2; i++ {
+		var u []byte
+		for j := byte(0); j < 2; j++ {
+			u = append(u, hashToField(dst, msg, 2*i+j)...)
+		}
+		p, err := g2.MapToCurve(u)
+		if err != nil {
+			return nil, err
+		}
+		g2.Add(h, h, p)
+	}
+	return h, nil
+}
+
+// hashToField reduces 64 bytes derived from the message modulo the base field,
+// the result is encoded over 48 bytes.
+func hashToField(dst, msg []byte, counter byte) []byte {
+	wide := append(crypto.Keccak256(dst, []byte{counter, 0}, msg), crypto.Keccak256(dst, []byte{counter, 1}, msg)...)
+	e := new(big.Int).Mod(new(big.Int).SetBytes(wide), fieldModulus)
+	return common.LeftPadBytes(e.Bytes(), 48)
+}
+
+func bitmapLength(size int) int {
+	return (size + 7) / 8
+}
@@ -0,0 +1,163 @@
+package crypto
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/crypto"
+)
+
+func newTestBLSCommittee(t *testing.T, n int) (types.Committee, []*BLSKey) {
+	committee := make(types.Committee, n)
+	keys := make([]*BLSKey, n)
+	for i := 0; i < n; i++ {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if keys[i], err = DeriveBLSKey(key); err != nil {
+			t.Fatal(err)
+		}
+		committee[i] = types.CommitteeMember{
+			Address:      crypto.PubkeyToAddress(key.PublicKey),
+			VotingPower:  big.NewInt(1),
+			ConsensusKey: keys[i].ConsensusKey(),
+		}
+	}
+	return committee, keys
+}
+
+func TestDeriveBLSKey(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	first, err := DeriveBLSKey(key)
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	second, _ := DeriveBLSKey(key)
+	if !bytes.Equal(first.PublicKey(), second.PublicKey()) {
+		t.Fatalf("Expected %x, got %x", first.PublicKey(), second.PublicKey())
+	}
+	if len(first.ConsensusKey()) != ConsensusKeyLength {
+		t.Fatalf("Expected %v, got %v", ConsensusKeyLength, len(first.ConsensusKey()))
+	}
+}
+
+func TestVerifyConsensusKey(t *testing.T) {
+	committee, keys := newTestBLSCommittee(t, 2)
+
+	if err := VerifyConsensusKey(committee[0].ConsensusKey); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+
+	t.Run("proof of possession of another key", func(t *testing.T) {
+		forged := append(keys[0].PublicKey(), committee[1].ConsensusKey[BLSPublicKeyLength:]...)
+		if err := VerifyConsensusKey(forged); err != ErrInvalidConsensusKey {
+			t.Fatalf("Expected %v, got %v", ErrInvalidConsensusKey, err)
+		}
+	})
+
+	t.Run("wrong length", func(t *testing.T) {
+		if err := VerifyConsensusKey(keys[0].PublicKey()); err != ErrInvalidConsensusKey {
+			t.Fatalf("Expected %v, got %v", ErrInvalidConsensusKey, err)
+		}
+	})
+
+	t.Run("seal signature is not a proof of possession", func(t *testing.T) {
+		forged := append(keys[0].PublicKey(), keys[0].Sign(keys[0].PublicKey())...)
+		if err := VerifyConsensusKey(forged); err != ErrInvalidConsensusKey {
+			t.Fatalf("Expected %v, got %v", ErrInvalidConsensusKey, err)
+		}
+	})
+}
+
+func TestVerifySeal(t *testing.T) {
+	committee, keys := newTestBLSCommittee(t, 2)
+	seal := []byte("committed seal")
+	sig := keys[0].Sign(seal)
+
+	if err := VerifySeal(committee[0].ConsensusKey, seal, sig); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if err := VerifySeal(committee[1].ConsensusKey, seal, sig); err != ErrInvalidBLSSignature {
+		t.Fatalf("Expected %v, got %v", ErrInvalidBLSSignature, err)
+	}
+	if err := VerifySeal(committee[0].ConsensusKey, []byte("other seal"), sig); err != ErrInvalidBLSSignature {
+		t.Fatalf("Expected %v, got %v", ErrInvalidBLSSignature, err)
+	}
+}
+
+func TestSealsAggregated(t *testing.T) {
+	committee, _ := newTestBLSCommittee(t, 3)
+	if !SealsAggregated(committee) {
+		t.Fatalf("Expected true, got false")
+	}
+	committee[1].ConsensusKey = nil
+	if SealsAggregated(committee) {
+		t.Fatalf("Expected false, got true")
+	}
+	if SealsAggregated(nil) {
+		t.Fatalf("Expected false, got true")
+	}
+}
+
+func TestAggregatedSeal(t *testing.T) {
+	committee, keys := newTestBLSCommittee(t, 10)
+	seal := []byte("committed seal")
+	seals := make(map[common.Address][]byte)
+	for _, i := range []int{0, 3, 8, 9} {
+		seals[committee[i].Address] = keys[i].Sign(seal)
+	}
+
+	aggregated, err := AggregateSeals(committee, seals)
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if len(aggregated.Signature) != BLSSignatureLength || len(aggregated.Signers) != 2 {
+		t.Fatalf("Expected a %v bytes signature and 2 bytes bitmap, got %v", BLSSignatureLength, aggregated)
+	}
+
+	signers, err := VerifyAggregatedSeal(committee, seal, aggregated)
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if len(signers) != 4 || signers[1].Address != committee[3].Address {
+		t.Fatalf("Expected members 0, 3, 8 and 9, got %v", signers)
+	}
+
+	t.Run("wrong message", func(t *testing.T) {
+		if _, err := VerifyAggregatedSeal(committee, []byte("other seal"), aggregated); err != ErrInvalidAggregatedSeal {
+			t.Fatalf("Expected %v, got %v", ErrInvalidAggregatedSeal, err)
+		}
+	})
+
+	t.Run("signer added to the bitmap", func(t *testing.T) {
+		forged := aggregated.Copy()
+		forged.Signers[0] |= 1 << 1
+		if _, err := VerifyAggregatedSeal(committee, seal, forged); err != ErrInvalidAggregatedSeal {
+			t.Fatalf("Expected %v, got %v", ErrInvalidAggregatedSeal, err)
+		}
+	})
+
+	t.Run("bitmap out of the committee", func(t *testing.T) {
+		forged := aggregated.Copy()
+		forged.Signers[1] |= 1 << 7
+		if _, err := VerifyAggregatedSeal(committee, seal, forged); err != ErrInvalidAggregatedSeal {
+			t.Fatalf("Expected %v, got %v", ErrInvalidAggregatedSeal, err)
+		}
+	})
+
+	t.Run("bitmap of the wrong length", func(t *testing.T) {
+		if _, err := VerifyAggregatedSeal(committee[:8], seal, aggregated); err != ErrInvalidAggregatedSeal {
+			t.Fatalf("Expected %v, got %v", ErrInvalidAggregatedSeal, err)
+		}
+	})
+
+	t.Run("no seals", func(t *testing.T) {
+		if _, err := AggregateSeals(committee, nil); err != ErrInvalidAggregatedSeal {
+			t.Fatalf("Expected %v, got %v", ErrInvalidAggregatedSeal, err)
+		}
+	})
+}
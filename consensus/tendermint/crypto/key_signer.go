@@ -0,0 +1,71 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"mime"
+
+	"github.com/clearmatics/autonity/accounts"
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/crypto"
+	"github.com/clearmatics/autonity/rlp"
+)
+
+// ErrUnknownContentType is returned if the content type of the data to sign
+// is not one of the tendermint content types.
+var ErrUnknownContentType = errors.New("unknown content type")
+
+// KeySigner signs the consensus data with the node key and the BLS key derived
+// from it, both held in memory.
+type KeySigner struct {
+	key     *ecdsa.PrivateKey
+	blsKey  *BLSKey
+	address common.Address
+}
+
+// NewKeySigner returns a signer for the given node key.
+func NewKeySigner(key *ecdsa.PrivateKey) (*KeySigner, error) {
+	blsKey, err := DeriveBLSKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return &KeySigner{key: key, blsKey: blsKey, address: crypto.PubkeyToAddress(key.PublicKey)}, nil
+}
+
+// Address returns the address of the node key.
+func (s *KeySigner) Address() common.Address {
+	return s.address
+}
+
+// ConsensusKey returns the BLS public key followed by its proof of possession.
+func (s *KeySigner) ConsensusKey() []byte {
+	return s.blsKey.ConsensusKey()
+}
+
+// SignData signs the data according to its content type. Aggregated seals and
+// BLS beacons are signed with the BLS key, headers are decoded and their seal
+// returned, anything else is signed over its keccak256 hash with the node key.
+func (s *KeySigner) SignData(contentType string, data []byte) ([]byte, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, err
+	}
+	switch mediaType {
+	case accounts.MimetypeTendermintMessage, accounts.MimetypeTendermintSeal:
+		return crypto.Sign(crypto.Keccak256(data), s.key)
+	case accounts.MimetypeTendermintAggregatedSeal, accounts.MimetypeTendermintBLSBeacon:
+		return s.blsKey.Sign(data), nil
+	case accounts.MimetypeTendermintHeader:
+		header := new(types.Header)
+		if err := rlp.DecodeBytes(data, header); err != nil {
+			return nil, err
+		}
+		if err := SignHeader(header, s.key); err != nil {
+			return nil, err
+		}
+		return header.ProposerSeal, nil
+	default:
+		return nil, ErrUnknownContentType
+	}
+}
@@ -38,6 +38,15 @@ type Poster interface {
 // CommitEvent is posted when a proposal is committed
 type CommitEvent struct{}
 
+// SyncEvent is posted when a peer asks for the messages of the current height,
+// Payload is the encoded sync request, empty for the legacy requests.
 type SyncEvent struct {
-	Addr common.Address
+	Addr    common.Address
+	Payload []byte
+}
+
+// ProposalTransactionsEvent is posted when transactions of the block of a
+// compact proposal are received, the proposal can then be rebuilt.
+type ProposalTransactionsEvent struct {
+	BlockHash common.Hash
 }
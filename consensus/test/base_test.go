@@ -5,6 +5,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/core"
+	"github.com/clearmatics/autonity/core/types"
 	"github.com/zimmski/go-leak"
 	"gonum.org/v1/gonum/stat"
 )
@@ -31,6 +34,63 @@ func TestTendermintSuccess(t *testing.T) {
 	}
 }
 
+func TestTendermintEmptyBlockSuppression(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in short mode")
+	}
+
+	// checkEmptyBlocks asserts that the empty blocks are only proposed once
+	// the maximum interval has elapsed, and that the committee waited for them
+	// without moving to another round.
+	checkEmptyBlocks := func(interval uint64, sameRound bool) func(t *testing.T, validators map[string]*testNode) {
+		return func(t *testing.T, validators map[string]*testNode) {
+			chain := validators["VA"].service.BlockChain()
+			// the genesis block is too old for the first block to be delayed.
+			for n := uint64(2); n <= chain.CurrentBlock().NumberU64(); n++ {
+				block, parent := chain.GetBlockByNumber(n), chain.GetBlockByNumber(n-1)
+				if len(block.Transactions()) == 0 && block.Time()-parent.Time() < interval {
+					t.Fatalf("empty block %d proposed %d seconds after its parent", n, block.Time()-parent.Time())
+				}
+				if sameRound && block.Header().Round != 0 {
+					t.Fatalf("block %d committed at round %d", n, block.Header().Round)
+				}
+			}
+		}
+	}
+
+	cases := []*testCase{
+		{
+			name:          "no transactions",
+			numValidators: 4,
+			numBlocks:     3,
+			txPerPeer:     0,
+			genesisHook: func(g *core.Genesis) *core.Genesis {
+				g.Config.Tendermint.MaxEmptyBlockInterval = 3
+				return g
+			},
+			finalAssert: checkEmptyBlocks(3, true),
+		},
+		{
+			name:          "transactions are not delayed",
+			numValidators: 4,
+			numBlocks:     5,
+			txPerPeer:     1,
+			genesisHook: func(g *core.Genesis) *core.Genesis {
+				g.Config.Tendermint.MaxEmptyBlockInterval = 30
+				return g
+			},
+			finalAssert: checkEmptyBlocks(30, false),
+		},
+	}
+
+	for _, testCase := range cases {
+		testCase := testCase
+		t.Run(fmt.Sprintf("test case %s", testCase.name), func(t *testing.T) {
+			runTest(t, testCase)
+		})
+	}
+}
+
 func TestTendermintSlowConnections(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping test in short mode")
@@ -69,6 +129,115 @@ func TestTendermintSlowConnections(t *testing.T) {
 	}
 }
 
+func TestTendermintLargeProposals(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in short mode")
+	}
+
+	// the blocks are several parts large, the proposer uploads them to a few
+	// members which gossip them on.
+	sendLargeTx := func(validator *testNode, fromAddr common.Address, toAddr common.Address) (bool, *types.Transaction, error) {
+		tx, err := sendTx(validator.service, validator.privateKey, fromAddr, toAddr, generateLargeTx)
+		return false, tx, err
+	}
+	cases := []*testCase{
+		{
+			name:          "no malicious, large blocks",
+			numValidators: 5,
+			numBlocks:     5,
+			txPerPeer:     2,
+			sendTransactionHooks: map[string]sendTransactionHook{
+				"VA": sendLargeTx,
+				"VB": sendLargeTx,
+				"VC": sendLargeTx,
+				"VD": sendLargeTx,
+				"VE": sendLargeTx,
+			},
+		},
+		{
+			name:          "no malicious, large blocks, all nodes are slow",
+			numValidators: 5,
+			numBlocks:     5,
+			txPerPeer:     2,
+			sendTransactionHooks: map[string]sendTransactionHook{
+				"VA": sendLargeTx,
+				"VB": sendLargeTx,
+				"VC": sendLargeTx,
+				"VD": sendLargeTx,
+				"VE": sendLargeTx,
+			},
+			networkRates: map[string]networkRate{
+				"VA": {512 * 1024, 512 * 1024},
+				"VB": {512 * 1024, 512 * 1024},
+				"VC": {512 * 1024, 512 * 1024},
+				"VD": {512 * 1024, 512 * 1024},
+				"VE": {512 * 1024, 512 * 1024},
+			},
+		},
+	}
+
+	for _, testCase := range cases {
+		testCase := testCase
+		t.Run(fmt.Sprintf("test case %s", testCase.name), func(t *testing.T) {
+			runTest(t, testCase)
+		})
+	}
+}
+
+func TestTendermintCompactProposals(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in short mode")
+	}
+
+	// the validators rebuild the blocks from their pool, the transactions
+	// missing are fetched from the proposer.
+	compactProposals := func(g *core.Genesis) *core.Genesis {
+		g.Config.Tendermint.CompactProposals = true
+		return g
+	}
+	sendLargeTx := func(validator *testNode, fromAddr common.Address, toAddr common.Address) (bool, *types.Transaction, error) {
+		tx, err := sendTx(validator.service, validator.privateKey, fromAddr, toAddr, generateLargeTx)
+		return false, tx, err
+	}
+	cases := []*testCase{
+		{
+			name:          "no malicious",
+			numValidators: 5,
+			numBlocks:     10,
+			txPerPeer:     5,
+			genesisHook:   compactProposals,
+		},
+		{
+			name:          "no malicious, large transactions, all nodes are slow",
+			numValidators: 5,
+			numBlocks:     5,
+			txPerPeer:     2,
+			sendTransactionHooks: map[string]sendTransactionHook{
+				"VA": sendLargeTx,
+				"VB": sendLargeTx,
+				"VC": sendLargeTx,
+				"VD": sendLargeTx,
+				"VE": sendLargeTx,
+			},
+			networkRates: map[string]networkRate{
+				"VA": {512 * 1024, 512 * 1024},
+				"VB": {512 * 1024, 512 * 1024},
+				"VC": {512 * 1024, 512 * 1024},
+				"VD": {512 * 1024, 512 * 1024},
+				"VE": {512 * 1024, 512 * 1024},
+			},
+			genesisHook: compactProposals,
+		},
+	}
+
+	for _, testCase := range cases {
+		testCase := testCase
+		t.Run(fmt.Sprintf("test case %s", testCase.name), func(t *testing.T) {
+			runTest(t, testCase)
+		})
+	}
+}
+
 type stats struct {
 	mean   float64
 	std    float64
@@ -74,6 +74,24 @@ func TestDynamicRpcs(t *testing.T) {
 				assert.NotNil(t, responseMap["result"])
 				assert.Nil(t, responseMap["error"])
 			}
+
+			// The same calls can be made against the state of a past block.
+			historicalCalls := []*rpcCall{
+				{Method: "aut_getCommittee", Params: []string{"0x0"}},
+				{Method: "aut_getCommittee", Params: []string{"latest"}},
+				{Method: "aut_balanceOf", Params: []string{validatorAddress, "0x1"}},
+			}
+			for _, body := range historicalCalls {
+				body.Jsonrpc = "2.0"
+				body.Id = 1
+				payload, err := json.Marshal(body)
+				require.NoError(t, err)
+				responseMap := make(map[string]interface{})
+				err = json.Unmarshal(callRPC(t, ep, payload), &responseMap)
+				require.NoError(t, err)
+				assert.NotNil(t, responseMap["result"])
+				assert.Nil(t, responseMap["error"])
+			}
 		},
 	}
 	runTest(t, tc)
@@ -0,0 +1,131 @@
+package test
+
+import (
+	"crypto/ecdsa"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/clearmatics/autonity/accounts/abi"
+	"github.com/clearmatics/autonity/autonity"
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/common/acdefault"
+	"github.com/clearmatics/autonity/common/graph"
+	tendermintCrypto "github.com/clearmatics/autonity/consensus/tendermint/crypto"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/crypto"
+)
+
+func TestTendermintKeyRotationOnBusTopology(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in short mode")
+	}
+	contractABI, err := abi.JSON(strings.NewReader(acdefault.ABI()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := contractABI.Methods["rotateConsensusKey"]; !ok {
+		t.Fatal("the embedded autonity contract can't rotate the consensus keys, run make embed-autonity-contract")
+	}
+
+	// VA is only connected to VB, the messages to and from its new consensus
+	// address have to be routed to its node.
+	topologyStr := `graph TB
+    VA---VB
+    VC---VB
+    VD---VC
+    VE---VD
+`
+	topology, err := graph.Parse(strings.NewReader(topologyStr))
+	if err != nil {
+		t.Fatal("parse error")
+	}
+
+	rotatedKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotatedSigner, err := tendermintCrypto.NewKeySigner(rotatedKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCase := &testCase{
+		name:          "a validator rotating its consensus key keeps taking part in the consensus",
+		numValidators: 5,
+		numBlocks:     20,
+		txPerPeer:     1,
+		configHook: func(index string, validator *testNode) {
+			if index != "VA" {
+				return
+			}
+			// the node holds both keys until the rotation takes effect.
+			dir, err := ioutil.TempDir("", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			files := []string{filepath.Join(dir, "nodekey"), filepath.Join(dir, "rotatedkey")}
+			for i, key := range []*ecdsa.PrivateKey{validator.privateKey, rotatedKey} {
+				if err := crypto.SaveECDSA(files[i], key); err != nil {
+					t.Fatal(err)
+				}
+			}
+			validator.ethConfig.Tendermint.ConsensusKeyFiles = files
+		},
+		sendTransactionHooks: map[string]sendTransactionHook{
+			"VA": rotateConsensusKeyHook(&contractABI, rotatedSigner.Address(), rotatedSigner.ConsensusKey()),
+		},
+		finalAssert: func(t *testing.T, validators map[string]*testNode) {
+			head := validators["VB"].service.BlockChain().CurrentHeader()
+			if head.CommitteeMember(rotatedSigner.Address()) == nil {
+				t.Fatal("the rotated key is not a committee member")
+			}
+			if head.CommitteeMember(validators["VA"].EthAddress()) != nil {
+				t.Fatal("the key rotated out is still a committee member")
+			}
+		},
+		topology: &Topology{
+			graph: *topology,
+		},
+	}
+	runTest(t, testCase)
+}
+
+func rotateConsensusKeyHook(contractABI *abi.ABI, address common.Address, key []byte) sendTransactionHook {
+	once := sync.Once{}
+	return func(validator *testNode, fromAddr common.Address, _ common.Address) (bool, *types.Transaction, error) { //nolint
+		if validator.lastBlock <= 3 {
+			return true, nil, nil
+		}
+		skip := true
+		var tx *types.Transaction
+		var errOuter error
+		once.Do(func() {
+			skip = false
+			data, err := contractABI.Pack("rotateConsensusKey", address, key)
+			if err != nil {
+				errOuter = err
+				return
+			}
+			tx, err = types.SignTx(
+				types.NewTransaction(
+					validator.service.TxPool().Nonce(fromAddr),
+					autonity.ContractAddress,
+					new(big.Int),
+					10000000,
+					big.NewInt(DefaultTestGasPrice),
+					data,
+				),
+				types.HomesteadSigner{}, validator.privateKey)
+			if err != nil {
+				errOuter = err
+				return
+			}
+			errOuter = validator.service.TxPool().AddLocal(tx)
+		})
+		return skip, tx, errOuter
+	}
+}
@@ -2,9 +2,12 @@ package test
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/clearmatics/autonity/accounts/abi"
 	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/common/acdefault"
 	"github.com/clearmatics/autonity/consensus"
 	tendermintBackend "github.com/clearmatics/autonity/consensus/tendermint/backend"
 )
@@ -75,3 +78,54 @@ func TestTendermintOneMalicious(t *testing.T) {
 		})
 	}
 }
+
+func TestTendermintDoubleSigner(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in short mode")
+	}
+	contractABI, err := abi.JSON(strings.NewReader(acdefault.ABI()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := contractABI.Methods["slash"]; !ok {
+		t.Fatal("the embedded autonity contract can't slash, run make embed-autonity-contract")
+	}
+
+	testCase := &testCase{
+		name:          "a validator sending conflicting prevotes is slashed",
+		numValidators: 5,
+		numBlocks:     10,
+		txPerPeer:     1,
+		maliciousPeers: map[string]injectors{
+			"VE": {
+				cons: func(basic consensus.Engine) consensus.Engine {
+					return tendermintBackend.NewDoubleSigner(t, basic, 3)
+				},
+			},
+		},
+		finalAssert: func(t *testing.T, validators map[string]*testNode) {
+			offender := validators["VE"].EthAddress()
+			chain := validators["VA"].service.BlockChain()
+			head := chain.CurrentHeader()
+
+			var evidenceFound bool
+			for n := uint64(1); n <= head.Number.Uint64(); n++ {
+				if len(chain.GetHeaderByNumber(n).Evidence) > 0 {
+					evidenceFound = true
+					break
+				}
+			}
+			if !evidenceFound {
+				t.Fatal("no evidence included in the chain")
+			}
+
+			for _, member := range head.Committee {
+				if member.Address == offender {
+					t.Fatal("slashed validator is still a committee member")
+				}
+			}
+		},
+	}
+
+	runTest(t, testCase)
+}
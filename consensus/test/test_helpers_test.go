@@ -76,6 +76,21 @@ func generateRandomTx(nonce uint64, toAddr common.Address, key *ecdsa.PrivateKey
 		types.HomesteadSigner{}, key)
 }
 
+// generateLargeTx generates a transaction carrying 100KB of data, which takes
+// a couple of parts of a proposed block.
+func generateLargeTx(nonce uint64, toAddr common.Address, key *ecdsa.PrivateKey) (*types.Transaction, error) {
+	return types.SignTx(
+		types.NewTransaction(
+			nonce,
+			toAddr,
+			big.NewInt(1),
+			210000000,
+			big.NewInt(100000000000),
+			make([]byte, 100*1024),
+		),
+		types.HomesteadSigner{}, key)
+}
+
 func makeGenesis(t *testing.T, nodes map[string]*testNode, stakeholderName string) *core.Genesis {
 	// generate genesis block
 	genesis := core.DefaultGenesisBlock()
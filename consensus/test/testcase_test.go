@@ -58,6 +58,7 @@ type testCase struct {
 	finalAssert          func(t *testing.T, validators map[string]*testNode)
 	stopTime             map[string]time.Time
 	genesisHook          func(g *core.Genesis) *core.Genesis
+	configHook           func(index string, validator *testNode)
 	mu                   sync.RWMutex
 	noQuorumAfterBlock   uint64
 	noQuorumTimeout      time.Duration
@@ -211,6 +212,9 @@ func runTest(t *testing.T, test *testCase) {
 		peer.nodeConfig, peer.ethConfig = makeNodeConfig(t, genesis, peer.privateKey,
 			fmt.Sprintf("127.0.0.1:%d", peer.port),
 			peer.rpcPort, rates.in, rates.out)
+		if test.configHook != nil {
+			test.configHook(i, peer)
+		}
 
 		if err != nil {
 			t.Fatal("cant make a node", i, err)
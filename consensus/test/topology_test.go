@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/clearmatics/autonity/common/graph"
+	"github.com/clearmatics/autonity/core"
 )
 
 func TestTendermintStarSuccess(t *testing.T) {
@@ -42,6 +43,49 @@ func TestTendermintStarSuccess(t *testing.T) {
 	}
 }
 
+func TestTendermintRelayOverSentriesSuccess(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in short mode")
+	}
+
+	// the validators are only connected to the participants acting as their
+	// sentries
+	topologyStr := `graph TB
+    VA---PF
+    VB---PF
+    VC---PF
+    VD---PG
+    VE---PG
+    PF---PG`
+
+	topology, err := graph.Parse(strings.NewReader(topologyStr))
+	if err != nil {
+		t.Fatal("parse error")
+	}
+	cases := []*testCase{
+		{
+			name:          "no malicious",
+			numValidators: 5,
+			numBlocks:     5,
+			txPerPeer:     1,
+			topology: &Topology{
+				graph: *topology,
+			},
+			genesisHook: func(g *core.Genesis) *core.Genesis {
+				g.Config.Tendermint.Relay = true
+				return g
+			},
+		},
+	}
+
+	for _, testCase := range cases {
+		testCase := testCase
+		t.Run(fmt.Sprintf("test case %s", testCase.name), func(t *testing.T) {
+			runTest(t, testCase)
+		})
+	}
+}
+
 func TestTendermintStarOverParticipantSuccess(t *testing.T) {
 	t.Skip("test is flaky - https://github.com/clearmatics/autonity/issues/496")
 	if testing.Short() {
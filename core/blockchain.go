@@ -213,6 +213,8 @@ type BlockChain struct {
 	processor  Processor  // Block transaction processor interface
 	vmConfig   vm.Config
 
+	processedBlocks *processedBlocks // Blocks executed ahead of their insertion, see AddProcessedBlock
+
 	badBlocks       *lru.Cache                     // Bad block cache
 	shouldPreserve  func(*types.Block) bool        // Function used to determine whether should preserve the given block.
 	terminateInsert func(common.Hash, uint64) bool // Testing hook used to terminate ancient receipt chain insertion.
@@ -256,6 +258,8 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 		vmConfig:       vmConfig,
 		badBlocks:      badBlocks,
 		senderCacher:   senderCacher,
+
+		processedBlocks: newProcessedBlocks(),
 	}
 	bc.validator = NewBlockValidator(chainConfig, bc, engine)
 	bc.prefetcher = newStatePrefetcher(chainConfig, bc, engine)
@@ -306,6 +310,8 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 
 		acConfig := bc.Config().AutonityContractConfig
 
+		// the ABI history is kept by the contract, this is only used for
+		// the first block of a new chain or databases written before it.
 		var JSONString = acConfig.ABI
 		bytes, err := bc.GetKeyValue([]byte(autonity.ABISPEC))
 		if err == nil || bytes != nil {
@@ -601,6 +607,12 @@ func (bc *BlockChain) SetHead(head uint64) error {
 	bc.txLookupCache.Purge()
 	bc.futureBlocks.Purge()
 
+	// Drop the autonity contract ABIs of the blocks which have been rewound
+	newHead := bc.CurrentBlock().NumberU64()
+	rawdb.DeleteAutonityABIsFrom(bc.db, newHead+1)
+	if bc.autonityContract != nil {
+		bc.autonityContract.Rewind(newHead)
+	}
 	return bc.loadLastState()
 }
 
@@ -1609,10 +1621,14 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 	// Set new head.
 	if status == CanonStatTy {
 		bc.writeHeadBlock(block)
+		bc.processedBlocks.evict(block.NumberU64())
 	}
 	bc.futureBlocks.Remove(block.Hash())
 
 	if status == CanonStatTy {
+		if bc.chainConfig.Tendermint != nil {
+			bc.GetAutonityContract().MeasureRewardDistribution(block)
+		}
 		bc.chainFeed.Send(ChainEvent{Block: block, Hash: block.Hash(), Logs: logs})
 		if len(logs) > 0 {
 			bc.logsFeed.Send(logs)
@@ -1854,6 +1870,8 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool) (int, er
 		if err != nil {
 			return it.index, err
 		}
+		// The consensus engine may have executed the block already
+		processed := bc.processedBlocks.take(block.Hash())
 		// If we have a followup block, run that against the current state to pre-cache
 		// transactions and probabilistically some of the account/storage trie nodes.
 		var followupInterrupt uint32
@@ -1874,8 +1892,15 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool) (int, er
 		}
 		// Process block using the parent state as reference point
 		substart := time.Now()
-		receipts, logs, usedGas, err := bc.processor.Process(block, statedb, bc.vmConfig)
-		if err != nil {
+		var (
+			receipts types.Receipts
+			logs     []*types.Log
+			usedGas  uint64
+		)
+		if processed != nil {
+			statedb, receipts, logs, usedGas = processed.State, processed.Receipts, processed.Logs, processed.UsedGas
+			processedBlockReuseMeter.Mark(1)
+		} else if receipts, logs, usedGas, err = bc.processor.Process(block, statedb, bc.vmConfig); err != nil {
 			bc.reportBlock(block, receipts, err)
 			atomic.StoreUint32(&followupInterrupt, 1)
 			return it.index, err
@@ -2575,6 +2600,15 @@ func (bc *BlockChain) ReadEnodeWhitelist() *types.Nodes {
 	return rawdb.ReadEnodeWhitelist(bc.db)
 }
 
+func (bc *BlockChain) WriteContractABI(height uint64, abi string) {
+	rawdb.DeleteAutonityABIsFrom(bc.db, height)
+	rawdb.WriteAutonityABI(bc.db, height, abi)
+}
+
+func (bc *BlockChain) ReadContractABIs() ([]uint64, []string) {
+	return rawdb.ReadAutonityABIs(bc.db)
+}
+
 func (bc *BlockChain) PutKeyValue(key []byte, value []byte) error {
 	return rawdb.PutKeyValue(bc.db, key, value)
 }
@@ -196,8 +196,9 @@ func GenerateChain(config *params.ChainConfig, parent *types.Block, engine conse
 		committee = make(types.Committee, len(validators))
 		for i, val := range validators {
 			committee[i] = types.CommitteeMember{
-				Address:     *val.Address,
+				Address:     val.GetConsensusAddress(),
 				VotingPower: new(big.Int).SetUint64(val.Stake),
+				Treasury:    *val.Address,
 			}
 		}
 	}
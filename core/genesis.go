@@ -414,8 +414,9 @@ func extractCommittee(users []params.User) (types.Committee, error) {
 	for _, v := range users {
 		if v.Type == params.UserValidator {
 			member := types.CommitteeMember{
-				Address:     *v.Address,
+				Address:     v.GetConsensusAddress(),
 				VotingPower: new(big.Int).SetUint64(v.Stake),
+				Treasury:    *v.Address,
 			}
 			committee = append(committee, member)
 		}
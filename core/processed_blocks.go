@@ -0,0 +1,94 @@
+package core
+
+import (
+	"sync"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/core/state"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/metrics"
+)
+
+// maxProcessedBlocks bounds the number of blocks executed ahead of their
+// insertion, a proposal is only executed once per round.
+const maxProcessedBlocks = 16
+
+var processedBlockReuseMeter = metrics.NewRegisteredMeter("chain/processed/reuse", nil)
+
+// ProcessedBlock is the outcome of the execution of a block on top of its
+// parent, e.g. while the consensus engine verifies a proposal, so that the
+// block is written without being executed again once it is inserted.
+type ProcessedBlock struct {
+	State    *state.StateDB
+	Receipts types.Receipts
+	Logs     []*types.Log
+	UsedGas  uint64
+}
+
+type processedBlock struct {
+	number uint64
+	*ProcessedBlock
+}
+
+// processedBlocks holds the blocks executed ahead of their insertion by hash,
+// the blocks are dropped once the chain advances past their height.
+type processedBlocks struct {
+	blocks map[common.Hash]processedBlock
+	mu     sync.Mutex
+}
+
+func newProcessedBlocks() *processedBlocks {
+	return &processedBlocks{blocks: make(map[common.Hash]processedBlock)}
+}
+
+// add records the execution of the block, it is dropped if too many blocks
+// are held already.
+func (p *processedBlocks) add(block *types.Block, processed *ProcessedBlock) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.blocks[block.Hash()]; !ok && len(p.blocks) >= maxProcessedBlocks {
+		return
+	}
+	p.blocks[block.Hash()] = processedBlock{number: block.NumberU64(), ProcessedBlock: processed}
+}
+
+// take removes the execution of the block and returns it, nil if the block
+// wasn't executed ahead. The state can only be committed once, hence it is
+// handed out once.
+func (p *processedBlocks) take(hash common.Hash) *ProcessedBlock {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	processed, ok := p.blocks[hash]
+	if !ok {
+		return nil
+	}
+	delete(p.blocks, hash)
+	return processed.ProcessedBlock
+}
+
+// evict drops the blocks whose height is at most number.
+func (p *processedBlocks) evict(number uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for hash, processed := range p.blocks {
+		if processed.number <= number {
+			delete(p.blocks, hash)
+		}
+	}
+}
+
+func (p *processedBlocks) len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.blocks)
+}
+
+// AddProcessedBlock hands the outcome of the execution of a block which is
+// about to be inserted to the chain, the block is then written without being
+// executed again. The state must not be used by the caller afterwards.
+func (bc *BlockChain) AddProcessedBlock(block *types.Block, processed *ProcessedBlock) {
+	if block.NumberU64() <= bc.CurrentBlock().NumberU64() {
+		return
+	}
+	bc.processedBlocks.add(block, processed)
+}
@@ -0,0 +1,165 @@
+package core
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/clearmatics/autonity/autonity"
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/consensus/ethash"
+	"github.com/clearmatics/autonity/core/rawdb"
+	"github.com/clearmatics/autonity/core/state"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/core/vm"
+	"github.com/clearmatics/autonity/crypto"
+	"github.com/clearmatics/autonity/params"
+)
+
+// failingProcessor fails the blocks executed, for the blocks which must not be
+// executed.
+type failingProcessor struct{}
+
+func (failingProcessor) Process(*types.Block, *state.StateDB, vm.Config) (types.Receipts, []*types.Log, uint64, error) {
+	return nil, nil, 0, errors.New("unexpected execution")
+}
+
+func (failingProcessor) SetAutonityContract(*autonity.Contract) {}
+
+type processedBlocksTester struct {
+	key    *ecdsa.PrivateKey
+	gspec  Genesis
+	engine *ethash.Ethash
+}
+
+func newProcessedBlocksTester() *processedBlocksTester {
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	return &processedBlocksTester{
+		key: key,
+		gspec: Genesis{
+			Config: params.TestChainConfig,
+			Alloc: GenesisAlloc{
+				crypto.PubkeyToAddress(key.PublicKey): {Balance: big.NewInt(1000000000000000000)},
+				// push 1, pop
+				common.HexToAddress("0xc0de"): {Code: []byte{0x60, 0x01, 0x50}, Balance: big.NewInt(0)},
+			},
+			GasLimit: 100e6,
+		},
+		engine: ethash.NewFaker(),
+	}
+}
+
+// blocks generates n blocks of txs transactions each to the contract.
+func (p *processedBlocksTester) blocks(n, txs int, coinbase common.Address) types.Blocks {
+	db := rawdb.NewMemoryDatabase()
+	genesis := p.gspec.MustCommit(db)
+	blocks, _ := GenerateChain(p.gspec.Config, genesis, p.engine, db, n, func(i int, block *BlockGen) {
+		block.SetCoinbase(coinbase)
+		for j := 0; j < txs; j++ {
+			tx, _ := types.SignTx(types.NewTransaction(uint64(i*txs+j), common.HexToAddress("0xc0de"), big.NewInt(1), 30000, big.NewInt(1), nil), types.HomesteadSigner{}, p.key)
+			block.AddTx(tx)
+		}
+	})
+	return blocks
+}
+
+func (p *processedBlocksTester) chain(tb testing.TB) *BlockChain {
+	db := rawdb.NewMemoryDatabase()
+	p.gspec.MustCommit(db)
+	chain, err := NewBlockChain(db, nil, p.gspec.Config, p.engine, vm.Config{}, nil, NewTxSenderCacher(), nil)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return chain
+}
+
+// process executes the block on top of the head of the chain, the way the
+// consensus engine verifies a proposal.
+func process(tb testing.TB, chain *BlockChain, block *types.Block) *ProcessedBlock {
+	statedb, err := chain.StateAt(chain.CurrentBlock().Root())
+	if err != nil {
+		tb.Fatal(err)
+	}
+	receipts, logs, usedGas, err := chain.Processor().Process(block, statedb, vm.Config{})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return &ProcessedBlock{State: statedb, Receipts: receipts, Logs: logs, UsedGas: usedGas}
+}
+
+func TestProcessedBlocks(t *testing.T) {
+	tester := newProcessedBlocksTester()
+	blocks := tester.blocks(2, 10, common.Address{1})
+	fork := tester.blocks(1, 10, common.Address{2})
+
+	t.Run("processed blocks are not executed again", func(t *testing.T) {
+		chain := tester.chain(t)
+		defer chain.Stop()
+		chain.AddProcessedBlock(blocks[0], process(t, chain, blocks[0]))
+		chain.processor = failingProcessor{}
+
+		if _, err := chain.InsertChain(blocks[:1]); err != nil {
+			t.Fatalf("Expected <nil>, got %v", err)
+		}
+		if chain.CurrentBlock().Hash() != blocks[0].Hash() {
+			t.Fatalf("Expected %v, got %v", blocks[0].Hash(), chain.CurrentBlock().Hash())
+		}
+		if receipts := chain.GetReceiptsByHash(blocks[0].Hash()); len(receipts) != 10 {
+			t.Fatalf("Expected 10 receipts, got %d", len(receipts))
+		}
+		if chain.processedBlocks.len() != 0 {
+			t.Fatalf("Expected the processed block to be consumed")
+		}
+	})
+
+	t.Run("stale blocks are evicted", func(t *testing.T) {
+		chain := tester.chain(t)
+		defer chain.Stop()
+		chain.AddProcessedBlock(fork[0], process(t, chain, fork[0]))
+		chain.AddProcessedBlock(blocks[1], &ProcessedBlock{})
+
+		if _, err := chain.InsertChain(blocks[:1]); err != nil {
+			t.Fatalf("Expected <nil>, got %v", err)
+		}
+		if chain.processedBlocks.len() != 1 || chain.processedBlocks.take(blocks[1].Hash()) == nil {
+			t.Fatalf("Expected the blocks of the next height only")
+		}
+		chain.AddProcessedBlock(fork[0], &ProcessedBlock{})
+		if chain.processedBlocks.len() != 0 {
+			t.Fatalf("Expected the blocks below the head to be ignored")
+		}
+	})
+}
+
+func benchmarkInsertHeavyBlock(b *testing.B, processed bool) {
+	tester := newProcessedBlocksTester()
+	blocks := tester.blocks(1, 1000, common.Address{1})
+	b.ReportAllocs()
+	b.StopTimer()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		chain := tester.chain(b)
+		if processed {
+			chain.AddProcessedBlock(blocks[0], process(b, chain, blocks[0]))
+		}
+		b.StartTimer()
+		if _, err := chain.InsertChain(blocks); err != nil {
+			b.Fatalf("Expected <nil>, got %v", err)
+		}
+		b.StopTimer()
+		chain.Stop()
+	}
+}
+
+// BenchmarkInsertHeavyBlock_executed commits a block of 1000 contract calls
+// which is executed on insertion.
+func BenchmarkInsertHeavyBlock_executed(b *testing.B) {
+	benchmarkInsertHeavyBlock(b, false)
+}
+
+// BenchmarkInsertHeavyBlock_processed commits the same block once executed
+// ahead, as done for the proposals verified by the consensus engine.
+func BenchmarkInsertHeavyBlock_processed(b *testing.B) {
+	benchmarkInsertHeavyBlock(b, true)
+}
@@ -0,0 +1,57 @@
+package rawdb
+
+import (
+	"encoding/binary"
+
+	"github.com/clearmatics/autonity/ethdb"
+	"github.com/clearmatics/autonity/log"
+)
+
+// autonityABIPrefix + num (uint64 big endian) -> autonity contract ABI which took effect at that block
+var autonityABIPrefix = []byte("ABISPEC-")
+
+func autonityABIKey(number uint64) []byte {
+	return append(append([]byte{}, autonityABIPrefix...), encodeBlockNumber(number)...)
+}
+
+// WriteAutonityABI stores the autonity contract ABI which took effect at the
+// given block number.
+func WriteAutonityABI(db ethdb.KeyValueWriter, number uint64, abi string) {
+	if err := db.Put(autonityABIKey(number), []byte(abi)); err != nil {
+		log.Crit("Failed to store autonity contract ABI", "err", err)
+	}
+}
+
+// ReadAutonityABIs retrieves every stored autonity contract ABI along with the
+// block numbers at which they took effect, sorted by block number.
+func ReadAutonityABIs(db ethdb.Iteratee) ([]uint64, []string) {
+	it := db.NewIterator(autonityABIPrefix, nil)
+	defer it.Release()
+
+	var (
+		numbers []uint64
+		abis    []string
+	)
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(autonityABIPrefix)+8 {
+			continue
+		}
+		numbers = append(numbers, binary.BigEndian.Uint64(key[len(autonityABIPrefix):]))
+		abis = append(abis, string(it.Value()))
+	}
+	return numbers, abis
+}
+
+// DeleteAutonityABIsFrom removes the autonity contract ABIs which took effect
+// at or above the given block number.
+func DeleteAutonityABIsFrom(db ethdb.KeyValueStore, number uint64) {
+	it := db.NewIterator(autonityABIPrefix, encodeBlockNumber(number))
+	defer it.Release()
+
+	for it.Next() {
+		if err := db.Delete(it.Key()); err != nil {
+			log.Crit("Failed to delete autonity contract ABI", "err", err)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package rawdb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAutonityABIStorage(t *testing.T) {
+	db := NewMemoryDatabase()
+	if numbers, abis := ReadAutonityABIs(db); len(numbers) != 0 || len(abis) != 0 {
+		t.Fatalf("Expected no ABI, got %v %v", numbers, abis)
+	}
+
+	// block numbers are written out of order to check the sorting.
+	WriteAutonityABI(db, 256, "v3")
+	WriteAutonityABI(db, 0, "v1")
+	WriteAutonityABI(db, 10, "v2")
+
+	numbers, abis := ReadAutonityABIs(db)
+	if !reflect.DeepEqual(numbers, []uint64{0, 10, 256}) {
+		t.Fatalf("Expected %v, got %v", []uint64{0, 10, 256}, numbers)
+	}
+	if !reflect.DeepEqual(abis, []string{"v1", "v2", "v3"}) {
+		t.Fatalf("Expected %v, got %v", []string{"v1", "v2", "v3"}, abis)
+	}
+
+	DeleteAutonityABIsFrom(db, 10)
+	numbers, abis = ReadAutonityABIs(db)
+	if !reflect.DeepEqual(numbers, []uint64{0}) || !reflect.DeepEqual(abis, []string{"v1"}) {
+		t.Fatalf("Expected only the first ABI, got %v %v", numbers, abis)
+	}
+}
@@ -766,6 +766,16 @@ func (s *StateDB) Finalise(deleteEmptyObjects bool) {
 	s.clearJournalAndRefund()
 }
 
+// CleanRoot returns the root hash of the state trie without hashing it, it
+// returns false if the state has been modified since the root was last
+// computed, e.g. by IntermediateRoot.
+func (s *StateDB) CleanRoot() (common.Hash, bool) {
+	if s.journal.length() > 0 || len(s.stateObjectsPending) > 0 {
+		return common.Hash{}, false
+	}
+	return s.trie.Hash(), true
+}
+
 // IntermediateRoot computes the current root hash of the state trie.
 // It is called in between transactions to get the root hash that
 // goes into transaction receipts.
@@ -474,6 +474,28 @@ func TestTouchDelete(t *testing.T) {
 	}
 }
 
+// Tests that the root of a state is only known without hashing it while the
+// state isn't modified.
+func TestCleanRoot(t *testing.T) {
+	state, _ := New(common.Hash{}, NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	if root, ok := state.CleanRoot(); !ok || root != emptyRoot {
+		t.Fatalf("Expected %x, got %x", emptyRoot, root)
+	}
+	state.SetBalance(common.HexToAddress("aaaa"), big.NewInt(42))
+	if _, ok := state.CleanRoot(); ok {
+		t.Fatal("Expected a modified state")
+	}
+	intermediate := state.IntermediateRoot(true)
+	if root, ok := state.CleanRoot(); !ok || root != intermediate {
+		t.Fatalf("Expected %x, got %x", intermediate, root)
+	}
+	// reads leave the state unmodified
+	state.GetBalance(common.HexToAddress("bbbb"))
+	if root, ok := state.CleanRoot(); !ok || root != intermediate {
+		t.Fatalf("Expected %x, got %x", intermediate, root)
+	}
+}
+
 // TestCopyOfCopy tests that modified objects are carried over to the copy, and the copy of the copy.
 // See https://github.com/clearmatics/autonity/pull/15225#issuecomment-380191512
 func TestCopyOfCopy(t *testing.T) {
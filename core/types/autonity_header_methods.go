@@ -1,6 +1,8 @@
 package types
 
 import (
+	"bytes"
+
 	"github.com/clearmatics/autonity/common"
 )
 
@@ -35,3 +37,18 @@ func (c Committee) TotalVotingPower() uint64 {
 	}
 	return total
 }
+
+// Equal reports whether both committees have the same members, in the same
+// order and with the same voting power and keys.
+func (c Committee) Equal(other Committee) bool {
+	if len(c) != len(other) {
+		return false
+	}
+	for i := range c {
+		if c[i].Address != other[i].Address || c[i].Treasury != other[i].Treasury ||
+			c[i].VotingPower.Cmp(other[i].VotingPower) != 0 || !bytes.Equal(c[i].ConsensusKey, other[i].ConsensusKey) {
+			return false
+		}
+	}
+	return true
+}
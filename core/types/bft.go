@@ -21,6 +21,7 @@ import (
 	"strings"
 
 	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/common/hexutil"
 	"github.com/clearmatics/autonity/crypto"
 	"github.com/clearmatics/autonity/log"
 	"github.com/clearmatics/autonity/rlp"
@@ -60,6 +61,7 @@ func BFTFilteredHeader(h *Header, keepSeal bool) *Header {
 		newHeader.ProposerSeal = []byte{}
 	}
 	newHeader.CommittedSeals = [][]byte{}
+	newHeader.AggregatedSeal = AggregatedSeal{}
 	newHeader.Round = 0
 	newHeader.Extra = []byte{}
 	return newHeader
@@ -141,6 +143,35 @@ func WriteCommittedSeals(h *Header, committedSeals [][]byte) error {
 	return nil
 }
 
+// AggregatedSeal is the BLS signature aggregating the committed seals of
+// committee members, Signers is the bitmap of their indexes in the committee.
+type AggregatedSeal struct {
+	Signature hexutil.Bytes `json:"signature"`
+	Signers   hexutil.Bytes `json:"signers"`
+}
+
+// Empty reports whether the seal holds no signature.
+func (s AggregatedSeal) Empty() bool {
+	return len(s.Signature) == 0 && len(s.Signers) == 0
+}
+
+// Copy returns a deep copy of the seal.
+func (s AggregatedSeal) Copy() AggregatedSeal {
+	return AggregatedSeal{
+		Signature: common.CopyBytes(s.Signature),
+		Signers:   common.CopyBytes(s.Signers),
+	}
+}
+
+// WriteAggregatedSeal writes the aggregated committed seal of a block header.
+func WriteAggregatedSeal(h *Header, seal AggregatedSeal) error {
+	if len(seal.Signature) == 0 || len(seal.Signers) == 0 {
+		return ErrInvalidCommittedSeals
+	}
+	h.AggregatedSeal = seal.Copy()
+	return nil
+}
+
 func RLPHash(v interface{}) (h common.Hash) {
 	hw := sha3.NewLegacyKeccak256()
 	rlp.Encode(hw, v)
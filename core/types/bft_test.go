@@ -17,11 +17,13 @@
 package types
 
 import (
+	"bytes"
 	"math/big"
 	"reflect"
 	"testing"
 
 	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/rlp"
 )
 
 func TestHeaderHash(t *testing.T) {
@@ -96,6 +98,18 @@ func TestHeaderHash(t *testing.T) {
 			}),
 			common.HexToHash("0xdf95f3ce4042e30ce57b2bcab9d9ebff1612035ef8abfe2213e6aa77f6e43abc"),
 		},
+		{
+			setExtra(PosHeader, headerExtra{
+				Committee: Committee{
+					{
+						Address:     common.HexToAddress("0x1234566"),
+						VotingPower: new(big.Int).SetUint64(12),
+						Treasury:    common.HexToAddress("0x7e7e7e7e"),
+					},
+				},
+			}),
+			common.HexToHash("0x5fe7e7cac104432c90d3a8bb2f1a9067420ff7b9db9cd363526735957c19be3a"),
+		},
 		{
 			setExtra(PosHeader, headerExtra{
 				ProposerSeal: common.Hex2Bytes("0xbebedead"),
@@ -126,6 +140,30 @@ func TestHeaderHash(t *testing.T) {
 			}),
 			common.HexToHash("0x0e006ab23161c9d88dab8cf00b6de143f1230b8f8a9d6b91ed10bb788edb9e4f"),
 		},
+		{
+			setExtra(PosHeader, headerExtra{
+				AggregatedSeal: AggregatedSeal{Signature: []byte{0xfa, 0xce}, Signers: []byte{0x07}},
+			}),
+			posHeaderHash,
+		},
+		{
+			setExtra(PosHeader, headerExtra{
+				PastAggregatedSeal: AggregatedSeal{Signature: []byte{0xfa, 0xce}, Signers: []byte{0x07}},
+			}),
+			common.HexToHash("0x0e7df992fba873a1459693c7085382e0f4b10bc809f14b090d3e565b337aee76"),
+		},
+		{
+			setExtra(PosHeader, headerExtra{
+				Beacon: []byte{0xbe, 0xac, 0x04},
+			}),
+			common.HexToHash("0x56f0da3485108570383369f04e2302ee755ffe8b08887ef6b6b0018314ae9959"),
+		},
+		{
+			setExtra(PosHeader, headerExtra{
+				ProposerPriorities: []*big.Int{big.NewInt(0), big.NewInt(7)},
+			}),
+			common.HexToHash("0xc2a3f7c038d8252d74e912b3eafa3aa79991e8d77d04b540b3dbe46837009f18"),
+		},
 	}
 	for i := range testCases {
 		if !reflect.DeepEqual(testCases[i].hash, testCases[i].header.Hash()) {
@@ -140,6 +178,53 @@ func setExtra(h Header, hExtra headerExtra) Header {
 	h.Round = hExtra.Round
 	h.CommittedSeals = hExtra.CommittedSeals
 	h.PastCommittedSeals = hExtra.PastCommittedSeals
+	h.AggregatedSeal = hExtra.AggregatedSeal
+	h.PastAggregatedSeal = hExtra.PastAggregatedSeal
+	h.Beacon = hExtra.Beacon
+	h.ProposerPriorities = hExtra.ProposerPriorities
 
 	return h
 }
+
+func TestHeaderExtraEncoding(t *testing.T) {
+	// legacyExtra is the extra data of the headers preceding the aggregated
+	// seals, such headers must keep their encoding and hash.
+	type legacyMember struct {
+		Address     common.Address
+		VotingPower *big.Int
+	}
+	type legacyExtra struct {
+		Committee          []legacyMember
+		ProposerSeal       []byte
+		Round              uint64
+		CommittedSeals     [][]byte
+		PastCommittedSeals [][]byte
+	}
+	legacy := legacyExtra{
+		Committee:          []legacyMember{{common.HexToAddress("0x1234566"), big.NewInt(12)}},
+		ProposerSeal:       []byte{0xbe, 0xbe},
+		Round:              3,
+		CommittedSeals:     [][]byte{{0xfa, 0xce}},
+		PastCommittedSeals: [][]byte{{0xba, 0xba}},
+	}
+	extra, err := rlp.EncodeToBytes(legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded headerExtra
+	if err := rlp.DecodeBytes(extra, &decoded); err != nil {
+		t.Fatalf("Expected <nil>, got %v", err)
+	}
+	if !decoded.AggregatedSeal.Empty() || !decoded.PastAggregatedSeal.Empty() || decoded.Beacon != nil ||
+		decoded.ProposerPriorities != nil || decoded.Evidence != nil || decoded.Committee[0].ConsensusKey != nil {
+		t.Fatalf("Expected empty extension fields, got %v", decoded)
+	}
+	encoded, err := rlp.EncodeToBytes(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(encoded, extra) {
+		t.Fatalf("Expected %x, got %x", extra, encoded)
+	}
+}
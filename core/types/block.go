@@ -100,11 +100,27 @@ type Header struct {
 	Round              uint64   `json:"round"               gencodec:"required"`
 	CommittedSeals     [][]byte `json:"committedSeals"      gencodec:"required"`
 	PastCommittedSeals [][]byte `json:"pastCommittedSeals"  gencodec:"required"`
+	// BLS seals replacing the committed seals once every committee member has a consensus key.
+	// The fields below are optional, they are left out of the encoding when
+	// empty so that headers without any keep the same encoding and hash.
+	AggregatedSeal     AggregatedSeal `json:"aggregatedSeal"      rlp:"optional"`
+	PastAggregatedSeal AggregatedSeal `json:"pastAggregatedSeal"  rlp:"optional"`
+	// signature of the proposer over the parent randomness, seeding the next proposer selection.
+	Beacon []byte `json:"beacon"`
+	// proposer priorities of the parent committee members at the start of the height.
+	ProposerPriorities []*big.Int `json:"proposerPriorities"`
+	// rlp encoded evidence of misbehaving committee members, taken into account for the hash.
+	Evidence [][]byte `json:"evidence"`
 }
 
 type CommitteeMember struct {
+	// Address of the key signing the consensus messages of the member.
 	Address     common.Address `json:"address"            gencodec:"required"       abi:"addr"`
 	VotingPower *big.Int       `json:"votingPower"        gencodec:"required"`
+	// BLS public key and its proof of possession, empty if not registered.
+	ConsensusKey []byte `json:"consensusKey"       abi:"consensusKey" rlp:"optional"`
+	// Account of the member in the autonity contract, holding its stake and receiving its rewards.
+	Treasury common.Address `json:"treasury"           abi:"treasury" rlp:"optional"`
 }
 
 type Committee []CommitteeMember
@@ -134,6 +150,13 @@ type headerExtra struct {
 	Round              uint64    `json:"round"               gencodec:"required"`
 	CommittedSeals     [][]byte  `json:"committedSeals"      gencodec:"required"`
 	PastCommittedSeals [][]byte  `json:"pastCommittedSeals"  gencodec:"required"`
+	// The fields below are optional, they are left out of the encoding when
+	// empty so that headers without any keep the same encoding and hash.
+	AggregatedSeal     AggregatedSeal `json:"aggregatedSeal"      rlp:"optional"`
+	PastAggregatedSeal AggregatedSeal `json:"pastAggregatedSeal"  rlp:"optional"`
+	Beacon             []byte         `json:"beacon"              rlp:"optional"`
+	ProposerPriorities []*big.Int     `json:"proposerPriorities"  rlp:"optional"`
+	Evidence           [][]byte       `json:"evidence"            rlp:"tail"`
 }
 
 // field type overrides for gencodec
@@ -151,6 +174,9 @@ type headerMarshaling struct {
 	ProposerSeal       hexutil.Bytes
 	CommittedSeals     []hexutil.Bytes
 	PastCommittedSeals []hexutil.Bytes
+	Beacon             hexutil.Bytes
+	ProposerPriorities []*hexutil.Big
+	Evidence           []hexutil.Bytes
 }
 
 // Hash returns the block hash of the header, which is simply the keccak256 hash of its
@@ -210,6 +236,11 @@ func (h *Header) DecodeRLP(s *rlp.Stream) error {
 			h.CommittedSeals = hExtra.CommittedSeals
 			h.Committee = hExtra.Committee
 			h.PastCommittedSeals = hExtra.PastCommittedSeals
+			h.AggregatedSeal = hExtra.AggregatedSeal
+			h.PastAggregatedSeal = hExtra.PastAggregatedSeal
+			h.Beacon = hExtra.Beacon
+			h.ProposerPriorities = hExtra.ProposerPriorities
+			h.Evidence = hExtra.Evidence
 			h.ProposerSeal = hExtra.ProposerSeal
 			h.Round = hExtra.Round
 		}
@@ -242,6 +273,11 @@ func (h *Header) EncodeRLP(w io.Writer) error {
 		Round:              h.Round,
 		CommittedSeals:     h.CommittedSeals,
 		PastCommittedSeals: h.PastCommittedSeals,
+		AggregatedSeal:     h.AggregatedSeal,
+		PastAggregatedSeal: h.PastAggregatedSeal,
+		Beacon:             h.Beacon,
+		ProposerPriorities: h.ProposerPriorities,
+		Evidence:           h.Evidence,
 	}
 
 	original := h.original()
@@ -427,8 +463,10 @@ func CopyHeader(h *Header) *Header {
 		cpy.Committee = make([]CommitteeMember, len(h.Committee))
 		for i, val := range h.Committee {
 			cpy.Committee[i] = CommitteeMember{
-				Address:     val.Address,
-				VotingPower: new(big.Int).Set(val.VotingPower),
+				Address:      val.Address,
+				VotingPower:  new(big.Int).Set(val.VotingPower),
+				ConsensusKey: common.CopyBytes(val.ConsensusKey),
+				Treasury:     val.Treasury,
 			}
 		}
 	}
@@ -454,6 +492,25 @@ func CopyHeader(h *Header) *Header {
 		}
 	}
 
+	cpy.AggregatedSeal = h.AggregatedSeal.Copy()
+	cpy.PastAggregatedSeal = h.PastAggregatedSeal.Copy()
+	cpy.Beacon = common.CopyBytes(h.Beacon)
+
+	if len(h.ProposerPriorities) > 0 {
+		cpy.ProposerPriorities = make([]*big.Int, len(h.ProposerPriorities))
+		for i, val := range h.ProposerPriorities {
+			cpy.ProposerPriorities[i] = new(big.Int).Set(val)
+		}
+	}
+
+	if len(h.Evidence) > 0 {
+		cpy.Evidence = make([][]byte, len(h.Evidence))
+		for i, val := range h.Evidence {
+			cpy.Evidence[i] = make([]byte, len(val))
+			copy(cpy.Evidence[i], val)
+		}
+	}
+
 	return &cpy
 }
 
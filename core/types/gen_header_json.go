@@ -40,6 +40,11 @@ func (h Header) MarshalJSON() ([]byte, error) {
 		Round              hexutil.Uint64  `json:"round"               gencodec:"required"`
 		CommittedSeals     []hexutil.Bytes `json:"committedSeals"      gencodec:"required"`
 		PastCommittedSeals []hexutil.Bytes `json:"pastCommittedSeals"  gencodec:"required"`
+		AggregatedSeal     AggregatedSeal  `json:"aggregatedSeal"`
+		PastAggregatedSeal AggregatedSeal  `json:"pastAggregatedSeal"`
+		Beacon             hexutil.Bytes   `json:"beacon"`
+		ProposerPriorities []*hexutil.Big  `json:"proposerPriorities"`
+		Evidence           []hexutil.Bytes `json:"evidence"`
 	}
 
 	var enc Header
@@ -80,6 +85,23 @@ func (h Header) MarshalJSON() ([]byte, error) {
 			encExtra.PastCommittedSeals[k] = v
 		}
 	}
+	encExtra.AggregatedSeal = h.AggregatedSeal
+	encExtra.PastAggregatedSeal = h.PastAggregatedSeal
+	if h.Beacon != nil {
+		encExtra.Beacon = h.Beacon
+	}
+	if h.ProposerPriorities != nil {
+		encExtra.ProposerPriorities = make([]*hexutil.Big, len(h.ProposerPriorities))
+		for k, v := range h.ProposerPriorities {
+			encExtra.ProposerPriorities[k] = (*hexutil.Big)(v)
+		}
+	}
+	if h.Evidence != nil {
+		encExtra.Evidence = make([]hexutil.Bytes, len(h.Evidence))
+		for k, v := range h.Evidence {
+			encExtra.Evidence[k] = v
+		}
+	}
 
 	extraBytes, err := json.Marshal(&encExtra)
 	if err != nil {
@@ -116,6 +138,11 @@ func (h *Header) UnmarshalJSON(input []byte) error {
 		Round              *hexutil.Uint64  `json:"round"               gencodec:"required"`
 		CommittedSeals     *[]hexutil.Bytes `json:"committedSeals"      gencodec:"required"`
 		PastCommittedSeals *[]hexutil.Bytes `json:"pastCommittedSeals"  gencodec:"required"`
+		AggregatedSeal     *AggregatedSeal  `json:"aggregatedSeal"`
+		PastAggregatedSeal *AggregatedSeal  `json:"pastAggregatedSeal"`
+		Beacon             *hexutil.Bytes   `json:"beacon"`
+		ProposerPriorities []*hexutil.Big   `json:"proposerPriorities"`
+		Evidence           *[]hexutil.Bytes `json:"evidence"`
 	}
 	var dec Header
 	if err := json.Unmarshal(input, &dec); err != nil {
@@ -215,5 +242,31 @@ func (h *Header) UnmarshalJSON(input []byte) error {
 			h.PastCommittedSeals[k] = v
 		}
 	}
+
+	if decExtra.AggregatedSeal != nil {
+		h.AggregatedSeal = *decExtra.AggregatedSeal
+	}
+
+	if decExtra.PastAggregatedSeal != nil {
+		h.PastAggregatedSeal = *decExtra.PastAggregatedSeal
+	}
+
+	if decExtra.Beacon != nil {
+		h.Beacon = *decExtra.Beacon
+	}
+
+	if decExtra.ProposerPriorities != nil {
+		h.ProposerPriorities = make([]*big.Int, len(decExtra.ProposerPriorities))
+		for k, v := range decExtra.ProposerPriorities {
+			h.ProposerPriorities[k] = (*big.Int)(v)
+		}
+	}
+
+	if decExtra.Evidence != nil {
+		h.Evidence = make([][]byte, len(*decExtra.Evidence))
+		for k, v := range *decExtra.Evidence {
+			h.Evidence[k] = v
+		}
+	}
 	return nil
 }
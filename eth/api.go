@@ -29,6 +29,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/clearmatics/autonity/accounts/abi"
 	"github.com/clearmatics/autonity/autonity"
 	"github.com/clearmatics/autonity/common"
 	"github.com/clearmatics/autonity/common/hexutil"
@@ -552,68 +553,161 @@ type AutonityContractAPI struct {
 // themselves make no use of the method receiver. This design is required to be
 // able to fit into the current approach taken for registering rpc services.
 // See rpc.Server.RegisterName().
-func NewAutonityContractAPI(bc *core.BlockChain, ac *autonity.Contract) *AutonityContractAPI {
+//
+// Every method takes an optional block number or hash as its last argument,
+// the call is then run against the state of that block using the contract ABI
+// which was in effect at that height. The latest block is used by default. The
+// state is given by stateAt, light clients retrieve it on demand.
+func NewAutonityContractAPI(ac *autonity.Contract, stateAt AutonityStateFn) *AutonityContractAPI {
 	var viewMethodStr = "view"
-	var contractABI = ac.ABI()
 	var contractViewMethods = make(map[string]reflect.Value)
 
-	for n, m := range contractABI.Methods {
-		functionName := n
-		// Only expose read-only functions.
-		if m.StateMutability == viewMethodStr {
-			// The RPC service expect the first argument of an API method to be the receiver object.
-			inArgs := []reflect.Type{reflect.TypeOf(&AutonityContractAPI{})}
-			inArgs = append(inArgs, m.Inputs.Types()...)
-			sig := reflect.FuncOf(inArgs, []reflect.Type{
-				reflect.TypeOf((*interface{})(nil)).Elem(),
-				reflect.TypeOf((*error)(nil)).Elem(),
-			}, false)
-
-			contractViewMethods[functionName] = reflect.MakeFunc(sig,
-				func(args []reflect.Value) []reflect.Value {
-					// makereturn converts the return types to reflect.Value
-					makereturn := func(res interface{}, err error) []reflect.Value {
-						return []reflect.Value{reflect.ValueOf(&res).Elem(), reflect.ValueOf(&err).Elem()}
-					}
-					stateDB, err := bc.State()
-					if err != nil {
-						return makereturn(nil, err)
-					}
-					var iargs []interface{}
-					// args[0] is the reflect.Value of *AutonityContractAPI.
-					for i, arg := range args[1:] {
-						// If the argument is a pointer it is then an optional parameter for the RPC handler. The
-						// json unmarshalling function set it to nil if the argument isn't set in the RPC call.
-						// There are no optional parameters for the Autonity contract methods. Solidity doesn't
-						// even support them and the packing function will crash if nil is passed.
-						if arg.Kind() == reflect.Ptr && arg.IsNil() {
-							return makereturn(nil, fmt.Errorf("missing value for required argument %d", i))
-						}
-						iargs = append(iargs, arg.Interface())
-					}
-
-					// Pack the arguments call the function and then unpack the result and return it.
-					packedArgs, err := contractABI.Pack(functionName, iargs...)
-					if err != nil {
-						return makereturn(nil, err)
-					}
-					packedResult, err := ac.CallContractFunc(stateDB, bc.CurrentHeader(), functionName, packedArgs)
-					if err != nil {
-						return makereturn(nil, err)
-					}
-					result, err := contractABI.Unpack(functionName, packedResult)
-
-					// If the result slice contains only one element then just return the element.
-					if len(result) == 1 {
-						return makereturn(result[0], err)
-					}
-					return makereturn(result, err)
-				})
+	// the views removed by an upgrade can still be called at the heights
+	// before it, the latest signature of a view is the one exposed.
+	for _, contractABI := range ac.ABIs() {
+		for _, m := range contractABI.Methods {
+			// Only expose read-only functions.
+			if m.StateMutability == viewMethodStr {
+				contractViewMethods[m.Name] = autonityViewMethod(ac, stateAt, m)
+			}
 		}
 	}
 	return &AutonityContractAPI{calls: contractViewMethods}
 }
 
+// autonityViewMethod returns the rpc method calling the given view function,
+// its arguments are packed with the ABI in effect at the height of the call.
+func autonityViewMethod(ac *autonity.Contract, stateAt AutonityStateFn, method abi.Method) reflect.Value {
+	functionName := method.Name
+	// The RPC service expect the first argument of an API method to be the receiver object.
+	inArgs := []reflect.Type{reflect.TypeOf(&AutonityContractAPI{}), contextType}
+	inArgs = append(inArgs, method.Inputs.Types()...)
+	inArgs = append(inArgs, reflect.TypeOf(&rpc.BlockNumberOrHash{}))
+	sig := reflect.FuncOf(inArgs, []reflect.Type{
+		reflect.TypeOf((*interface{})(nil)).Elem(),
+		reflect.TypeOf((*error)(nil)).Elem(),
+	}, false)
+
+	return reflect.MakeFunc(sig,
+		func(args []reflect.Value) []reflect.Value {
+			// makereturn converts the return types to reflect.Value
+			makereturn := func(res interface{}, err error) []reflect.Value {
+				return []reflect.Value{reflect.ValueOf(&res).Elem(), reflect.ValueOf(&err).Elem()}
+			}
+			// args[0] is the reflect.Value of *AutonityContractAPI, args[1] the context of the
+			// call and the last one is the optional block.
+			ctx := args[1].Interface().(context.Context)
+			blockNrOrHash := args[len(args)-1].Interface().(*rpc.BlockNumberOrHash)
+			stateDB, header, err := stateAt(ctx, blockNrOrHash)
+			if err != nil {
+				return makereturn(nil, err)
+			}
+			contractABI := ac.ABIAt(header.Number.Uint64())
+			m, ok := contractABI.Methods[functionName]
+			if !ok {
+				return makereturn(nil, fmt.Errorf("%s is not available at block %d", functionName, header.Number.Uint64()))
+			}
+			if m.Sig != method.Sig {
+				return makereturn(nil, fmt.Errorf("%s is %s at block %d", functionName, m.Sig, header.Number.Uint64()))
+			}
+
+			var iargs []interface{}
+			for i, arg := range args[2 : len(args)-1] {
+				// If the argument is a pointer it is then an optional parameter for the RPC handler. The
+				// json unmarshalling function set it to nil if the argument isn't set in the RPC call.
+				// There are no optional parameters for the Autonity contract methods. Solidity doesn't
+				// even support them and the packing function will crash if nil is passed.
+				if arg.Kind() == reflect.Ptr && arg.IsNil() {
+					return makereturn(nil, fmt.Errorf("missing value for required argument %d", i))
+				}
+				iargs = append(iargs, arg.Interface())
+			}
+
+			// Pack the arguments call the function and then unpack the result and return it.
+			packedArgs, err := contractABI.Pack(functionName, iargs...)
+			if err != nil {
+				return makereturn(nil, err)
+			}
+			packedResult, err := ac.CallContractFunc(stateDB, header, functionName, packedArgs)
+			if err != nil {
+				return makereturn(nil, err)
+			}
+			// the failures to retrieve the state on demand are only reported by the state.
+			if err := stateDB.Error(); err != nil {
+				return makereturn(nil, err)
+			}
+			result, err := contractABI.Unpack(functionName, packedResult)
+
+			// If the result slice contains only one element then just return the element.
+			if len(result) == 1 {
+				return makereturn(result[0], err)
+			}
+			return makereturn(result, err)
+		})
+}
+
+// AutonityStateFn returns the state and the header of the block the autonity
+// contract view functions are called against, the latest block if none is
+// given.
+type AutonityStateFn func(ctx context.Context, blockNrOrHash *rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error)
+
+// contextType is the type of the context taken by the rpc methods.
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+// autonityContractState implements AutonityStateFn for the chain of the node.
+func (s *Ethereum) autonityContractState(_ context.Context, blockNrOrHash *rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error) {
+	return autonityContractState(s.blockchain, blockNrOrHash)
+}
+
+// autonityContractState returns the state and the header of the block the
+// autonity contract view functions are called against, the latest block is
+// used if none is given.
+func autonityContractState(bc *core.BlockChain, blockNrOrHash *rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error) {
+	header := bc.CurrentHeader()
+	if blockNrOrHash != nil {
+		if number, ok := blockNrOrHash.Number(); ok {
+			// the pending block is served as the latest one.
+			if number >= 0 {
+				header = bc.GetHeaderByNumber(uint64(number))
+			}
+		} else if hash, ok := blockNrOrHash.Hash(); ok {
+			header = bc.GetHeaderByHash(hash)
+			if header != nil && blockNrOrHash.RequireCanonical && bc.GetCanonicalHash(header.Number.Uint64()) != hash {
+				return nil, nil, errors.New("hash is not currently canonical")
+			}
+		}
+		if header == nil {
+			return nil, nil, errors.New("header not found")
+		}
+	}
+	stateDB, err := bc.StateAt(header.Root)
+	if err != nil {
+		return nil, nil, err
+	}
+	return stateDB, header, nil
+}
+
 func (a *AutonityContractAPI) AllMethods() map[string]reflect.Value {
 	return a.calls
 }
+
+// AutonityUpgradeAPI checks the autonity contract upgrade set by the operator
+// before it is performed by the next block.
+type AutonityUpgradeAPI struct {
+	bc *core.BlockChain
+}
+
+// NewAutonityUpgradeAPI creates a new AutonityUpgradeAPI.
+func NewAutonityUpgradeAPI(bc *core.BlockChain) *AutonityUpgradeAPI {
+	return &AutonityUpgradeAPI{bc: bc}
+}
+
+// DryRunUpgrade migrates the state of the autonity contract to the pending
+// upgrade on a copy of the latest state and reports the outcome.
+func (api *AutonityUpgradeAPI) DryRunUpgrade() (*autonity.UpgradeReport, error) {
+	stateDB, header, err := autonityContractState(api.bc, nil)
+	if err != nil {
+		return nil, err
+	}
+	return api.bc.GetAutonityContract().DryRunUpgrade(header, stateDB)
+}
@@ -18,18 +18,24 @@ package eth
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"math/big"
 	"reflect"
 	"sort"
+	"strings"
 	"testing"
 
 	"github.com/davecgh/go-spew/spew"
 
+	"github.com/clearmatics/autonity/autonity"
 	"github.com/clearmatics/autonity/common"
 	"github.com/clearmatics/autonity/core/rawdb"
 	"github.com/clearmatics/autonity/core/state"
+	"github.com/clearmatics/autonity/core/types"
 	"github.com/clearmatics/autonity/crypto"
+	"github.com/clearmatics/autonity/rpc"
 )
 
 var dumper = spew.ConfigState{Indent: "    "}
@@ -202,3 +208,63 @@ func TestStorageRangeAt(t *testing.T) {
 		}
 	}
 }
+
+type testABIHistory struct {
+	heights []uint64
+	abis    []string
+}
+
+func (h *testABIHistory) UpdateEnodeWhitelist(*types.Nodes)          {}
+func (h *testABIHistory) ReadEnodeWhitelist() *types.Nodes           { return nil }
+func (h *testABIHistory) WriteContractABI(height uint64, abi string) {}
+func (h *testABIHistory) ReadContractABIs() ([]uint64, []string)     { return h.heights, h.abis }
+
+func TestAutonityContractAPIUpgradedViews(t *testing.T) {
+	const (
+		abiV1 = `[{"inputs":[{"internalType":"uint256","name":"_n","type":"uint256"}],"name":"getRemoved","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"uint256","name":"_n","type":"uint256"}],"name":"getChanged","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"}]`
+		abiV2 = `[{"inputs":[{"internalType":"address","name":"_a","type":"address"}],"name":"getChanged","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"},{"inputs":[],"name":"getAdded","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"}]`
+	)
+	history := &testABIHistory{heights: []uint64{0, 10}, abis: []string{abiV1, abiV2}}
+	ac, err := autonity.NewAutonityContract(history, common.Address{}, 0, abiV2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errState := errors.New("no state")
+	stateAt := func(ctx context.Context, blockNrOrHash *rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error) {
+		number, _ := blockNrOrHash.Number()
+		if number == 5 {
+			return nil, nil, errState
+		}
+		return nil, &types.Header{Number: big.NewInt(int64(number))}, nil
+	}
+	api := NewAutonityContractAPI(ac, stateAt)
+
+	calls := api.AllMethods()
+	for _, name := range []string{"getRemoved", "getChanged", "getAdded"} {
+		if _, ok := calls[name]; !ok {
+			t.Fatalf("%s is not exposed", name)
+		}
+	}
+	call := func(name string, number rpc.BlockNumber, args ...interface{}) error {
+		in := []reflect.Value{reflect.ValueOf(api), reflect.ValueOf(context.Background())}
+		for _, arg := range args {
+			in = append(in, reflect.ValueOf(arg))
+		}
+		blockNr := rpc.BlockNumberOrHashWithNumber(number)
+		out := calls[name].Call(append(in, reflect.ValueOf(&blockNr)))
+		err, _ := out[1].Interface().(error)
+		return err
+	}
+
+	// the removed view is still dispatched at the heights before the upgrade.
+	if err := call("getRemoved", 5, big.NewInt(1)); err != errState {
+		t.Fatalf("Expected %v, got %v", errState, err)
+	}
+	if err := call("getRemoved", 12, big.NewInt(1)); err == nil || !strings.Contains(err.Error(), "not available") {
+		t.Fatalf("Expected the view to be unavailable, got %v", err)
+	}
+	// the latest signature of a changed view is exposed.
+	if err := call("getChanged", 8, common.Address{}); err == nil || !strings.Contains(err.Error(), "getChanged(uint256)") {
+		t.Fatalf("Expected a signature mismatch, got %v", err)
+	}
+}
@@ -146,6 +146,13 @@ func New(stack *node.Node, config *Config, cons func(basic consensus.Engine) con
 	)
 	log.Info("Initialised chain configuration", "config", chainConfig)
 
+	if chainConfig.Tendermint != nil {
+		config.Tendermint.ApplyChainConfig(chainConfig.Tendermint)
+		if err := config.Tendermint.ValidateTimeouts(); err != nil {
+			return nil, fmt.Errorf("invalid tendermint config: %v", err)
+		}
+	}
+
 	consEngine := CreateConsensusEngine(stack, chainConfig, config, config.Miner.Notify, config.Miner.Noverify, chainDb, &vmConfig)
 	if cons != nil {
 		consEngine = cons(consEngine)
@@ -208,6 +215,9 @@ func New(stack *node.Node, config *Config, cons func(basic consensus.Engine) con
 		config.TxPool.Journal = stack.ResolvePath(config.TxPool.Journal)
 	}
 	eth.txPool = core.NewTxPool(config.TxPool, chainConfig, eth.blockchain, senderCacher)
+	if be, ok := consEngine.(tendermintcore.Backend); ok {
+		be.SetTxPool(eth.txPool)
+	}
 
 	// Permit the downloader to use the trie cache allowance during fast sync
 	cacheLimit := cacheConfig.TrieCleanLimit + cacheConfig.TrieDirtyLimit + cacheConfig.SnapshotLimit
@@ -258,7 +268,11 @@ func makeExtraData(extra []byte) []byte {
 func CreateConsensusEngine(ctx *node.Node, chainConfig *params.ChainConfig, config *Config, notify []string, noverify bool, db ethdb.Database, vmConfig *vm.Config) consensus.Engine {
 
 	if chainConfig.Tendermint != nil {
-		return tendermintBackend.New(&config.Tendermint, ctx.Config().NodeKey(), db, chainConfig, vmConfig)
+		engine := tendermintBackend.New(&config.Tendermint, ctx.Config().NodeKey(), db, chainConfig, vmConfig)
+		if config.SyncMode == downloader.LightSync {
+			engine.SetLightMode()
+		}
+		return engine
 	}
 
 	// Otherwise assume proof-of-work
@@ -299,7 +313,12 @@ func (s *Ethereum) APIs() []rpc.API {
 		apis = append(apis, rpc.API{
 			Namespace: "aut",
 			Version:   params.Version,
-			Service:   NewAutonityContractAPI(s.BlockChain(), s.BlockChain().GetAutonityContract()),
+			Service:   NewAutonityContractAPI(s.BlockChain().GetAutonityContract(), s.autonityContractState),
+			Public:    true,
+		}, rpc.API{
+			Namespace: "aut",
+			Version:   params.Version,
+			Service:   NewAutonityUpgradeAPI(s.BlockChain()),
 			Public:    true,
 		})
 	}
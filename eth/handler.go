@@ -1052,6 +1052,31 @@ func (pm *ProtocolManager) FindPeers(targets map[common.Address]struct{}) map[co
 	return m
 }
 
+// Peers implements consensus.Broadcaster.Peers
+func (pm *ProtocolManager) Peers() map[common.Address]consensus.Peer {
+	m := make(map[common.Address]consensus.Peer)
+
+	for _, p := range pm.peers.Peers() {
+		pubKey := p.Node().Pubkey()
+		if pubKey == nil {
+			continue
+		}
+		m[crypto.PubkeyToAddress(*pubKey)] = p
+	}
+
+	return m
+}
+
+// DisconnectPeer implements consensus.Broadcaster.DisconnectPeer
+func (pm *ProtocolManager) DisconnectPeer(addr common.Address) {
+	for _, p := range pm.peers.Peers() {
+		pubKey := p.Node().Pubkey()
+		if pubKey != nil && crypto.PubkeyToAddress(*pubKey) == addr {
+			pm.removePeer(p.id)
+		}
+	}
+}
+
 // NodeInfo represents a short summary of the Ethereum sub-protocol metadata
 // known about the host peer.
 type NodeInfo struct {
@@ -43,7 +43,7 @@ const protocolName = "eth"
 var ProtocolVersions = []uint{eth65, eth64, eth63}
 
 // protocolLengths are the number of implemented message corresponding to different protocol versions.
-var protocolLengths = map[uint]uint64{eth65: 19, eth64: 19, eth63: 19}
+var protocolLengths = map[uint]uint64{eth65: 23, eth64: 23, eth63: 23}
 
 const protocolMaxMsgSize = 10 * 1024 * 1024 // Maximum cap on the size of a protocol message
 
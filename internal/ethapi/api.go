@@ -1152,6 +1152,7 @@ func RPCMarshalHeader(head *types.Header) map[string]interface{} {
 		"committedSeals":     head.CommittedSeals,
 		"round":              head.Round,
 		"proposerSeal":       head.ProposerSeal,
+		"evidence":           head.Evidence,
 	}
 }
 
@@ -169,6 +169,10 @@ web3._extend({
 			name: 'datadir',
 			getter: 'admin_datadir'
 		}),
+		new web3._extend.Property({
+			name: 'peerScores',
+			getter: 'admin_peerScores'
+		}),
 	]
 });
 `
@@ -854,6 +858,21 @@ web3._extend({
 			name: 'getCoreState',
 			call: 'tendermint_getCoreState',
 			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'getEvidence',
+			call: 'tendermint_getEvidence',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'getConsensusKey',
+			call: 'tendermint_getConsensusKey',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'getConsensusKeys',
+			call: 'tendermint_getConsensusKeys',
+			params: 0
 		})
 	]
 });
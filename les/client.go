@@ -18,6 +18,7 @@
 package les
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -29,6 +30,7 @@ import (
 	"github.com/clearmatics/autonity/core"
 	"github.com/clearmatics/autonity/core/bloombits"
 	"github.com/clearmatics/autonity/core/rawdb"
+	"github.com/clearmatics/autonity/core/state"
 	"github.com/clearmatics/autonity/core/types"
 	"github.com/clearmatics/autonity/eth"
 	"github.com/clearmatics/autonity/eth/downloader"
@@ -225,6 +227,14 @@ func (s *LightDummyAPI) Mining() bool {
 func (s *LightEthereum) APIs() []rpc.API {
 	apis := ethapi.GetAPIs(s.ApiBackend)
 	apis = append(apis, s.engine.APIs(s.BlockChain().HeaderChain())...)
+	if contract := s.blockchain.GetAutonityContract(); contract != nil {
+		apis = append(apis, rpc.API{
+			Namespace: "aut",
+			Version:   params.Version,
+			Service:   eth.NewAutonityContractAPI(contract, s.autonityContractState),
+			Public:    true,
+		})
+	}
 	return append(apis, []rpc.API{
 		{
 			Namespace: "eth",
@@ -260,6 +270,16 @@ func (s *LightEthereum) APIs() []rpc.API {
 	}...)
 }
 
+// autonityContractState implements eth.AutonityStateFn, the state is retrieved
+// on demand from the servers and verified against the header.
+func (s *LightEthereum) autonityContractState(ctx context.Context, blockNrOrHash *rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error) {
+	if blockNrOrHash == nil {
+		latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+		blockNrOrHash = &latest
+	}
+	return s.ApiBackend.StateAndHeaderByNumberOrHash(ctx, *blockNrOrHash)
+}
+
 func (s *LightEthereum) ResetWithGenesisBlock(gb *types.Block) {
 	s.blockchain.ResetWithGenesisBlock(gb)
 }
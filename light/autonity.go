@@ -0,0 +1,80 @@
+package light
+
+import (
+	"math/big"
+
+	"github.com/clearmatics/autonity/autonity"
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/core"
+	"github.com/clearmatics/autonity/core/rawdb"
+	"github.com/clearmatics/autonity/core/state"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/core/vm"
+	"github.com/clearmatics/autonity/params"
+)
+
+// lightEVMProvider implements autonity.EVMProvider, the state given to the EVM
+// is expected to be an ODR state, see NewState.
+type lightEVMProvider struct {
+	lc *LightChain
+}
+
+func (p *lightEVMProvider) EVM(header *types.Header, origin common.Address, statedb *state.StateDB) *vm.EVM {
+	coinbase, _ := types.Ecrecover(header)
+	evmContext := vm.Context{
+		CanTransfer: core.CanTransfer,
+		Transfer:    core.Transfer,
+		GetHash:     core.GetHashFn(header, p.lc),
+		Origin:      origin,
+		Coinbase:    coinbase,
+		BlockNumber: header.Number,
+		Time:        new(big.Int).SetUint64(header.Time),
+		GasLimit:    header.GasLimit,
+		Difficulty:  header.Difficulty,
+		GasPrice:    new(big.Int),
+	}
+	return vm.NewEVM(evmContext, statedb, p.lc.Config(), vm.Config{})
+}
+
+// setupAutonityContract creates the autonity contract of tendermint chains,
+// whose state is retrieved on demand and verified against the headers. Light
+// clients don't execute the blocks, the ABI of the genesis contract is used.
+func (lc *LightChain) setupAutonityContract(config *params.ChainConfig) error {
+	if config.Tendermint == nil || config.AutonityContractConfig == nil {
+		return nil
+	}
+	acConfig := config.AutonityContractConfig
+	contract, err := autonity.NewAutonityContract(lc, acConfig.Operator, acConfig.MinGasPrice, acConfig.ABI, &lightEVMProvider{lc})
+	if err != nil {
+		return err
+	}
+	lc.autonityContract = contract
+	return nil
+}
+
+// GetAutonityContract returns the autonity contract, nil if the chain is not a
+// tendermint chain.
+func (lc *LightChain) GetAutonityContract() *autonity.Contract {
+	return lc.autonityContract
+}
+
+// UpdateEnodeWhitelist implements autonity.Blockchainer
+func (lc *LightChain) UpdateEnodeWhitelist(newWhitelist *types.Nodes) {
+	rawdb.WriteEnodeWhitelist(lc.chainDb, newWhitelist)
+}
+
+// ReadEnodeWhitelist implements autonity.Blockchainer
+func (lc *LightChain) ReadEnodeWhitelist() *types.Nodes {
+	return rawdb.ReadEnodeWhitelist(lc.chainDb)
+}
+
+// WriteContractABI implements autonity.Blockchainer
+func (lc *LightChain) WriteContractABI(height uint64, abi string) {
+	rawdb.DeleteAutonityABIsFrom(lc.chainDb, height)
+	rawdb.WriteAutonityABI(lc.chainDb, height, abi)
+}
+
+// ReadContractABIs implements autonity.Blockchainer
+func (lc *LightChain) ReadContractABIs() ([]uint64, []string) {
+	return rawdb.ReadAutonityABIs(lc.chainDb)
+}
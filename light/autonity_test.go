@@ -0,0 +1,71 @@
+package light
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	tendermintConfig "github.com/clearmatics/autonity/consensus/tendermint/config"
+	"github.com/clearmatics/autonity/consensus/ethash"
+	"github.com/clearmatics/autonity/core"
+	"github.com/clearmatics/autonity/core/rawdb"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/params"
+)
+
+func TestAutonityContractOdr(t *testing.T) {
+	const enode = "enode://d73b857969c86415c0c000371bcebd9ed3cca6c376032b3f65e58e9e2b79276fbc6f59eb1e22fcd6356ab95f42a666f70afd4985933bd8f3e05beb1a2bf8fdde@172.25.0.11:30303"
+	config := *params.TestChainConfig
+	config.Tendermint = tendermintConfig.DefaultConfig()
+	config.AutonityContractConfig = &params.AutonityContractGenesis{
+		Operator: testBankAddress,
+		Users: []params.User{
+			{Address: &testBankAddress, Type: params.UserValidator, Enode: enode, Stake: 100},
+		},
+	}
+	if err := config.AutonityContractConfig.Prepare(); err != nil {
+		t.Fatal(err)
+	}
+	var (
+		sdb   = rawdb.NewMemoryDatabase()
+		ldb   = rawdb.NewMemoryDatabase()
+		gspec = core.Genesis{Config: &config, Mixhash: types.BFTDigest, Difficulty: big.NewInt(1), GasLimit: 10000000}
+	)
+	genesis := gspec.MustCommit(sdb)
+	// the light database only holds the genesis block, not its state.
+	rawdb.WriteTd(ldb, genesis.Hash(), 0, genesis.Difficulty())
+	rawdb.WriteBlock(ldb, genesis)
+	rawdb.WriteCanonicalHash(ldb, genesis.Hash(), 0)
+	rawdb.WriteHeadHeaderHash(ldb, genesis.Hash())
+	rawdb.WriteHeadBlockHash(ldb, genesis.Hash())
+
+	odr := &testOdr{sdb: sdb, ldb: ldb, indexerConfig: TestClientIndexerConfig}
+	lightchain, err := NewLightChain(odr, &config, ethash.NewFullFaker(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	contract := lightchain.GetAutonityContract()
+	if contract == nil {
+		t.Fatalf("Expected the autonity contract, got nil")
+	}
+
+	// the contract state can't be read without the ODR.
+	odr.disable = true
+	statedb := NewState(context.Background(), genesis.Header(), odr)
+	if _, err := contract.GetWhitelist(genesis, statedb); err == nil && statedb.Error() == nil {
+		t.Fatalf("Expected an error, got nil")
+	}
+
+	odr.disable = false
+	statedb = NewState(context.Background(), genesis.Header(), odr)
+	whitelist, err := contract.GetWhitelist(genesis, statedb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := statedb.Error(); err != nil {
+		t.Fatal(err)
+	}
+	if len(whitelist.StrList) != 1 || whitelist.StrList[0] != enode {
+		t.Fatalf("Expected %v, got %v", []string{enode}, whitelist.StrList)
+	}
+}
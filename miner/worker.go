@@ -526,6 +526,10 @@ func (w *worker) mainLoop() {
 				}
 			}
 			atomic.AddInt32(&w.newTxs, int32(len(ev.Txs)))
+			// the empty block waiting to be proposed is replaced right away.
+			if w.isRunning() && w.current != nil && w.current.tcount == 0 && w.delaysEmptyBlocks() {
+				w.commitNewWork(nil, true, time.Now().Unix())
+			}
 
 		// System stopped
 		case <-w.exitCh:
@@ -998,9 +1002,22 @@ func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64)
 			return
 		}
 	}
+	// An empty block isn't proposed before the maximum empty block interval
+	// has elapsed since its parent, the engine waits for its timestamp.
+	if w.delaysEmptyBlocks() && w.current.tcount == 0 {
+		if deadline := parent.Time() + w.chainConfig.Tendermint.MaxEmptyBlockInterval; deadline > w.current.header.Time {
+			w.current.header.Time = deadline
+		}
+	}
 	w.commit(uncles, w.fullTaskHook, true, tstart)
 }
 
+// delaysEmptyBlocks returns whether the empty blocks wait for transactions up
+// to the maximum empty block interval of the chain.
+func (w *worker) delaysEmptyBlocks() bool {
+	return w.chainConfig.Tendermint != nil && w.chainConfig.Tendermint.MaxEmptyBlockInterval > 0
+}
+
 // commit runs any post-transaction state modifications, assembles the final block
 // and commits new work if consensus engine is running.
 func (w *worker) commit(uncles []*types.Header, interval func(), update bool, start time.Time) error {
@@ -95,10 +95,24 @@ func (ac *AutonityContractGenesis) Prepare() error {
 
 //User - is used to put predefined accounts to genesis
 type User struct {
+	// Address is the account of the user, for validators it is the treasury
+	// receiving the rewards. It defaults to the address of the enode key.
 	Address *common.Address `json:"address,omitempty"`
 	Enode   string          `json:"enode"`
 	Type    UserType        `json:"type"`
 	Stake   uint64          `json:"stake"`
+	// ConsensusAddress is the address of the key signing the consensus
+	// messages. It defaults to the address of the user.
+	ConsensusAddress *common.Address `json:"consensusAddress,omitempty"`
+}
+
+// GetConsensusAddress returns the address of the key signing the consensus
+// messages of the user.
+func (u *User) GetConsensusAddress() common.Address {
+	if u.ConsensusAddress != nil {
+		return *u.ConsensusAddress
+	}
+	return *u.Address
 }
 
 // getAddressFromEnode gets the account address from the user enode.
@@ -357,6 +357,34 @@ type tailPrivateFields struct {
 	x, y bool   //lint:ignore U1000 unused fields required for testing purposes.
 }
 
+type optionalFields struct {
+	A uint
+	B uint `rlp:"optional"`
+	C uint `rlp:"optional"`
+}
+
+type optionalAndTailField struct {
+	A    uint
+	B    uint   `rlp:"optional"`
+	Tail []uint `rlp:"tail"`
+}
+
+type optionalSliceFields struct {
+	A uint
+	B []byte `rlp:"optional"`
+	C []byte `rlp:"optional"`
+}
+
+type invalidOptional1 struct {
+	A uint `rlp:"optional"`
+	B uint
+}
+
+type invalidOptional2 struct {
+	A uint   `rlp:"optional"`
+	B []uint `rlp:"optional,tail"`
+}
+
 type nilListUint struct {
 	X *uint `rlp:"nilList"`
 }
@@ -516,6 +544,53 @@ var decodeTests = []decodeTest{
 		error: `rlp: invalid struct tag "nil" for rlp.invalidNilTag.X (field is not a pointer)`,
 	},
 
+	// struct tag "optional"
+	{
+		input: "C101",
+		ptr:   new(optionalFields),
+		value: optionalFields{1, 0, 0},
+	},
+	{
+		input: "C20102",
+		ptr:   new(optionalFields),
+		value: optionalFields{1, 2, 0},
+	},
+	{
+		input: "C3010203",
+		ptr:   new(optionalFields),
+		value: optionalFields{1, 2, 3},
+	},
+	{
+		input: "C401020304",
+		ptr:   new(optionalFields),
+		error: "rlp: input list has too many elements for rlp.optionalFields",
+	},
+	{
+		input: "C101",
+		ptr:   new(optionalAndTailField),
+		value: optionalAndTailField{A: 1},
+	},
+	{
+		input: "C3010203",
+		ptr:   new(optionalAndTailField),
+		value: optionalAndTailField{A: 1, B: 2, Tail: []uint{3}},
+	},
+	{
+		input: "C101",
+		ptr:   &optionalSliceFields{A: 0, B: []byte{1}, C: []byte{2}},
+		value: optionalSliceFields{A: 1},
+	},
+	{
+		input: "C0",
+		ptr:   new(invalidOptional1),
+		error: `rlp: struct field rlp.invalidOptional1.B needs "optional" tag`,
+	},
+	{
+		input: "C0",
+		ptr:   new(invalidOptional2),
+		error: `rlp: invalid struct tag "tail" for rlp.invalidOptional2.B (also has "optional" tag)`,
+	},
+
 	// struct tag "tail"
 	{
 		input: "C3010203",
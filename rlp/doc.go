@@ -102,13 +102,32 @@ Signed integers, floating point numbers, maps, channels and functions cannot be
 
 Struct Tags
 
-Package rlp honours certain struct tags: "-", "tail", "nil", "nilList" and "nilString".
+Package rlp honours certain struct tags: "-", "tail", "optional", "nil", "nilList" and
+"nilString".
 
 The "-" tag ignores fields.
 
 The "tail" tag, which may only be used on the last exported struct field, allows slurping
 up any excess list elements into a slice. See examples for more details.
 
+The "optional" tag says that the field may be omitted if it is empty. When this tag is
+used on a struct field, all subsequent public fields must also be declared optional, or
+be the "tail" field.
+
+When encoding a struct with optional fields, the output RLP list contains all values up
+to the last non-empty optional field. Slices and maps of length zero are empty, as are
+structs and arrays holding only empty values.
+
+When decoding into a struct, optional fields may be omitted from the end of the input
+list. For the example below, this means input lists of one, two, or three elements are
+accepted.
+
+    type StructWithOptionalFields struct {
+        Required  uint64
+        Optional1 uint64 `rlp:"optional"`
+        Optional2 uint64 `rlp:"optional"`
+    }
+
 The "nil" tag applies to pointer-typed fields and changes the decoding rules for the field
 such that input values of size zero decode as a nil pointer. This tag can be useful when
 decoding recursive types.
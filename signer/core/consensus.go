@@ -0,0 +1,184 @@
+package core
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"mime"
+	"os"
+	"sync"
+
+	"github.com/clearmatics/autonity/accounts"
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/common/hexutil"
+	tendermintCore "github.com/clearmatics/autonity/consensus/tendermint/core"
+	tendermintCrypto "github.com/clearmatics/autonity/consensus/tendermint/crypto"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/log"
+	"github.com/clearmatics/autonity/rlp"
+)
+
+// ConsensusAPIVersion is the version of the consensus signer API.
+const ConsensusAPIVersion = "1.0.0"
+
+var (
+	// ErrDoubleSign is returned if the data to sign conflicts with the data
+	// already signed, according to the high-water mark of the signer.
+	ErrDoubleSign = errors.New("refusing to double sign")
+	// ErrForeignMessage is returned if a consensus message to sign is not sent
+	// by the address of the signer.
+	ErrForeignMessage = errors.New("message not sent by the signer")
+)
+
+// ConsensusSignerAPI signs the consensus messages, committed seals, beacons
+// and headers of a tendermint committee member. It decodes the data it is
+// asked to sign and keeps a high-water mark of the highest (height, round,
+// step) signed, persisted before any signature is released, so that it never
+// signs two different values at the same step nor goes back in the consensus.
+type ConsensusSignerAPI struct {
+	signer   *tendermintCrypto.KeySigner
+	markPath string
+	mark     tendermintCore.View
+	mu       sync.Mutex
+}
+
+// NewConsensusSignerAPI creates a consensus signer for the given key, the
+// high-water mark is loaded from and saved to the given file.
+func NewConsensusSignerAPI(key *ecdsa.PrivateKey, markPath string) (*ConsensusSignerAPI, error) {
+	signer, err := tendermintCrypto.NewKeySigner(key)
+	if err != nil {
+		return nil, err
+	}
+	api := &ConsensusSignerAPI{signer: signer, markPath: markPath}
+	data, err := ioutil.ReadFile(markPath)
+	switch {
+	case os.IsNotExist(err):
+		log.Warn("No high-water mark found, starting from genesis", "file", markPath)
+	case err != nil:
+		return nil, err
+	default:
+		if err := json.Unmarshal(data, &api.mark); err != nil {
+			return nil, err
+		}
+	}
+	return api, nil
+}
+
+// Version returns the version of the consensus signer API.
+func (api *ConsensusSignerAPI) Version() string {
+	return ConsensusAPIVersion
+}
+
+// Address returns the address of the key signing the consensus messages.
+func (api *ConsensusSignerAPI) Address() common.Address {
+	return api.signer.Address()
+}
+
+// ConsensusKey returns the BLS public key followed by its proof of possession.
+func (api *ConsensusSignerAPI) ConsensusKey() hexutil.Bytes {
+	return api.signer.ConsensusKey()
+}
+
+// HighWaterMark returns the highest view signed.
+func (api *ConsensusSignerAPI) HighWaterMark() tendermintCore.View {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	return api.mark
+}
+
+// SignData signs the data of one of the tendermint content types, if it
+// doesn't conflict with the data signed before.
+func (api *ConsensusSignerAPI) SignData(contentType string, data hexutil.Bytes) (hexutil.Bytes, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		view       tendermintCore.View
+		heightOnly bool
+	)
+	switch mediaType {
+	case accounts.MimetypeTendermintMessage:
+		var sender common.Address
+		if sender, view, err = tendermintCore.MessageView(data); err == nil && sender != api.Address() {
+			err = ErrForeignMessage
+		}
+	case accounts.MimetypeTendermintSeal, accounts.MimetypeTendermintAggregatedSeal:
+		view, err = tendermintCore.CommittedSealView(data)
+	case accounts.MimetypeTendermintBLSBeacon:
+		heightOnly = true
+		view.Height, err = tendermintCore.BeaconHeight(data)
+	case accounts.MimetypeTendermintHeader:
+		heightOnly = true
+		header := new(types.Header)
+		if err = rlp.DecodeBytes(data, header); err == nil {
+			view.Height = header.Number
+		}
+	default:
+		err = tendermintCrypto.ErrUnknownContentType
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if err := api.checkView(view, heightOnly); err != nil {
+		log.Warn("Refused to sign consensus data", "type", mediaType, "height", view.Height, "round", view.Round, "step", view.Step, "mark", api.mark.Height)
+		return nil, err
+	}
+	return api.signer.SignData(contentType, data)
+}
+
+// checkView checks the view against the high-water mark and raises the mark
+// to the view. Blocks and beacons are not bound to a round, they are only
+// refused for heights lower than the mark. A new header or beacon height
+// raises the mark below the first round of that height, so that the messages
+// of the height can still be signed but none of the heights before it.
+func (api *ConsensusSignerAPI) checkView(view tendermintCore.View, heightOnly bool) error {
+	if view.Height == nil {
+		return ErrDoubleSign
+	}
+	if api.mark.Height == nil {
+		api.mark.Height = new(big.Int)
+	}
+	if heightOnly {
+		switch view.Height.Cmp(api.mark.Height) {
+		case -1:
+			return ErrDoubleSign
+		case 0:
+			return nil
+		}
+		return api.saveMark(tendermintCore.View{Height: view.Height, Round: -1})
+	}
+	switch view.Cmp(api.mark) {
+	case -1:
+		return ErrDoubleSign
+	case 0:
+		if view.Value != api.mark.Value {
+			return ErrDoubleSign
+		}
+		return nil
+	}
+	return api.saveMark(view)
+}
+
+// saveMark persists the new high-water mark, the previous file is replaced
+// atomically so that a crash never leaves the mark behind.
+func (api *ConsensusSignerAPI) saveMark(view tendermintCore.View) error {
+	data, err := json.Marshal(view)
+	if err != nil {
+		return err
+	}
+	tmp := api.markPath + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, api.markPath); err != nil {
+		return err
+	}
+	api.mark = view
+	return nil
+}